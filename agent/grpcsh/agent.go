@@ -5,6 +5,7 @@ import (
 	agent "grpcsh/agent"
 	"log"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -13,6 +14,15 @@ func main() {
 	peerID := flag.String("i", "", "Peer ID")
 	routerUrl := flag.String("r", "localhost:50051", "Router URL")
 	socketPath := flag.String("s", "agent.sock", "Socket Path")
+	backendName := flag.String("b", "shell", "Job backend (shell, slurm)")
+	warm := flag.Int("warm", 0, "[shell] Shells kept ready to run commands without spawning one; 0 spawns one per command")
+	spoolDir := flag.String("spool", "spool", "[slurm] Directory for job scripts and output, shared with compute nodes")
+	sbatchArgs := flag.String("sbatch-args", "", "[slurm] Extra arguments passed to sbatch")
+	rootDirs := flag.String("roots", "", "Comma-separated directories file access is confined to (default: the working directory for other peers, anywhere for the local socket)")
+	cacheDir := flag.String("cache-dir", "", "Directory to keep a cache of uploaded files in (default: no cache)")
+	cacheSize := flag.Int64("cache-size", 1024, "Size limit of the cache of uploaded files in MiB")
+	jobDir := flag.String("job-dir", "", "Directory detached jobs spool their output to (default: no jobs)")
+	allowDial := flag.String("allow-dial", "", "Comma-separated host:port patterns, like *.internal:443, other peers may connect to through this agent (default: any)")
 	flag.Parse()
 
 	// validation
@@ -28,10 +38,36 @@ func main() {
 	if err := os.RemoveAll(*socketPath); err != nil {
 		log.Fatalf("Failed to remove existing socket: %v", err)
 	}
+	var backend agent.Backend
+	switch *backendName {
+	case "shell":
+		backend = agent.NewWarmBackend(*warm)
+	case "slurm":
+		backend = &agent.SlurmBackend{SpoolDir: *spoolDir, Args: strings.Fields(*sbatchArgs)}
+	default:
+		log.Fatalf("Unknown backend: %s", *backendName)
+	}
 
 	// logic
 	log.Println("Peer ID:", *peerID)
 	log.Println("Router URL:", *routerUrl)
 	log.Println("Socket Path:", *socketPath)
-	agent.Start(*peerID, *routerUrl, *socketPath)
+	log.Println("Backend:", *backendName)
+	if *backendName == "shell" && *warm > 0 {
+		log.Println("Warm workers:", *warm)
+	}
+	opts := agent.Options{Backend: backend}
+	if *rootDirs != "" {
+		opts.Roots = strings.Split(*rootDirs, ",")
+		log.Println("Roots:", opts.Roots)
+	}
+	if *allowDial != "" {
+		opts.AllowDial = strings.Split(*allowDial, ",")
+		log.Println("Allowed dials:", opts.AllowDial)
+	}
+	if *cacheDir != "" && *cacheSize > 0 {
+		opts.CacheDir, opts.CacheSize = *cacheDir, *cacheSize*1024*1024
+	}
+	opts.JobDir = *jobDir
+	agent.Start(*peerID, *routerUrl, *socketPath, opts)
 }
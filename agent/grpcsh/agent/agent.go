@@ -6,13 +6,15 @@ import (
 	"io"
 	"log"
 	"net"
-	"os/exec"
+	"os"
+	"strconv"
 	"sync"
 
 	pb "grpcsh/pb"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -23,7 +25,10 @@ type executorServer struct {
 // global variables
 var bus *Bus
 var channelSvcClient pb.ChannelServiceClient
+var routerConn *grpc.ClientConn
+var localConn *grpc.ClientConn
 var selfId string
+var backend Backend
 var bufsize = 1 * 1024 * 1024
 
 func (s *executorServer) Exec(stream pb.ExecutorService_ExecServer) error {
@@ -49,10 +54,10 @@ func (s *executorServer) Exec(stream pb.ExecutorService_ExecServer) error {
 		// run command remotely
 		ctx := context.Background()
 		chnl, err := channelSvcClient.CreateChannel(ctx, &emptypb.Empty{})
-		chnlId := chnl.Id
 		if err != nil {
 			return fmt.Errorf("failed to create channel: %w", err)
 		}
+		chnlId := chnl.Id
 		log.Printf("[%s] got channel: %s\n", selfId, chnlId)
 		ci, co := bus.Channel(chnlId)
 		cmd := &pb.PeerMessage{Channel: chnlId, From: selfId, To: toId, Flag: flag, Data: data}
@@ -70,24 +75,16 @@ func execLocalOnLocal(stream pb.ExecutorService_ExecServer, cmd *pb.Message) err
 		return fmt.Errorf("expected command, got: %s", cmd.Flag.String())
 	}
 	script := string(cmd.Data)
-	proc := exec.Command("bash", "-c", script)
-	stdin, err := proc.StdinPipe()
+	proc, err := backend.Start(script)
 	if err != nil {
-		return fmt.Errorf("failed to get stdin pipe: %w", err)
-	}
-	stdout, err := proc.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stdout pipe: %w", err)
-	}
-	stderr, err := proc.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stderr pipe: %w", err)
-	}
-	if err := proc.Start(); err != nil {
 		return fmt.Errorf("failed to start command: %w", err)
 	}
+	stdin, stdout, stderr := proc.Stdin(), proc.Stdout(), proc.Stderr()
+	if err := stream.Send(&pb.Result{From: selfId, To: selfId, Flag: pb.Flag_STARTED}); err != nil {
+		return fmt.Errorf("Error sending start: %w", err)
+	}
 
-	done := make(chan bool, 3)
+	done := make(chan bool, 2)
 	mu := sync.RWMutex{}
 
 	handleStream := func(reader io.Reader, transform func([]byte, bool) *pb.Result) error {
@@ -147,6 +144,7 @@ func execLocalOnLocal(stream pb.ExecutorService_ExecServer, cmd *pb.Message) err
 	}()
 
 	go func() {
+		stdinOpen := true
 		for {
 			msg, err := stream.Recv()
 			if err != nil {
@@ -155,38 +153,53 @@ func execLocalOnLocal(stream pb.ExecutorService_ExecServer, cmd *pb.Message) err
 				}
 				break
 			}
+			if msg.Flag == pb.Flag_SIGNAL {
+				signalProcess(proc, msg.Data)
+				continue
+			}
+			if !stdinOpen {
+				continue
+			}
 			if msg.Flag == pb.Flag_EOF_STDIN {
-				break
+				log.Printf("[%s] done handling stdin stream\n", selfId)
+				stdin.Close()
+				stdinOpen = false
+				continue
 			}
 			if msg.Flag != pb.Flag_MSG_STDIN {
 				log.Printf("[%s] expected stdin, got: %s\n", selfId, msg.Flag.String())
-				break
+				continue
 			}
 			chunk := msg.Data
 			if n, err := stdin.Write(chunk); err != nil {
 				log.Printf("[%s] error writing to stdin: %s\n", selfId, err)
-				break
 			} else if n != len(chunk) {
 				log.Printf("[%s] failed to write all bytes to stdin\n", selfId)
-				break
 			}
 		}
-		log.Printf("[%s] done handling stdin stream\n", selfId)
-		stdin.Close()
-		done <- true
+		if stdinOpen {
+			stdin.Close()
+		}
 	}()
 
-	// wait for all goroutines to finish
-	for i := 0; i < 3; i++ {
+	// wait for the output streams to finish; stdin may stay open after the
+	// command exits, and is released once the stream ends
+	for i := 0; i < 2; i++ {
 		<-done
 	}
-	proc.Wait()
+	code, err := proc.Wait()
+	if err != nil {
+		log.Printf("[%s] error waiting for command: %s\n", selfId, err)
+	}
+	if err := stream.Send(&pb.Result{From: selfId, To: selfId, Flag: pb.Flag_EXIT, Data: []byte(strconv.Itoa(code))}); err != nil {
+		return fmt.Errorf("Error sending exit code: %w", err)
+	}
 
 	log.Printf("[%s] Exec command finished\n", selfId)
 	return nil
 }
 
-func execLocalOnRemote(stream pb.ExecutorService_ExecServer, in chan *pb.PeerMessage, out chan *pb.PeerMessage, cmd *pb.PeerMessage) error {
+func execLocalOnRemote(stream pb.ExecutorService_ExecServer, in chan *pb.PeerMessage, out func(*pb.PeerMessage), cmd *pb.PeerMessage) error {
 	log.Printf("[%s] forwarding remote command: %s\n", selfId, cmd)
 
 	chId := cmd.Channel
@@ -195,7 +208,9 @@ func execLocalOnRemote(stream pb.ExecutorService_ExecServer, in chan *pb.PeerMes
 	done := make(chan bool, 2)
 
 	go func() {
-		out <- cmd
+		out(cmd)
+		// keep forwarding after EOF_STDIN, as signals may still follow
+		eof := false
 		for {
 			msg, err := stream.Recv()
 			if err != nil {
@@ -207,11 +222,16 @@ func execLocalOnRemote(stream pb.ExecutorService_ExecServer, in chan *pb.PeerMes
 			flag := msg.Flag
 			data := msg.Data
 			if flag == pb.Flag_EOF_STDIN {
-				break
+				if eof {
+					continue
+				}
+				eof = true
 			}
-			out <- &pb.PeerMessage{Channel: chId, From: selfId, To: toId, Flag: flag, Data: data}
+			out(&pb.PeerMessage{Channel: chId, From: selfId, To: toId, Flag: flag, Data: data})
+		}
+		if !eof {
+			out(&pb.PeerMessage{Channel: chId, From: selfId, To: toId, Flag: pb.Flag_EOF_STDIN, Data: nil})
 		}
-		out <- &pb.PeerMessage{Channel: chId, From: selfId, To: toId, Flag: pb.Flag_EOF_STDIN, Data: nil}
 		done <- true
 	}()
 
@@ -219,12 +239,15 @@ func execLocalOnRemote(stream pb.ExecutorService_ExecServer, in chan *pb.PeerMes
 		for msg := range in {
 			flag := msg.Flag
 			data := msg.Data
-			if flag == pb.Flag_MSG_STDOUT || flag == pb.Flag_MSG_STDERR || flag == pb.Flag_EOF_STDOUT || flag == pb.Flag_EOF_STDERR {
+			if flag == pb.Flag_MSG_STDOUT || flag == pb.Flag_MSG_STDERR || flag == pb.Flag_EOF_STDOUT || flag == pb.Flag_EOF_STDERR || flag == pb.Flag_STARTED || flag == pb.Flag_EXIT {
 				err := stream.Send(&pb.Result{From: selfId, To: msg.To, Flag: flag, Data: data})
 				if err != nil {
 					log.Printf("[%s] error sending msg: %s\n", selfId, err)
 					break
 				}
+				if flag == pb.Flag_EXIT {
+					break
+				}
 			} else {
 				log.Printf("[%s] unexpected message: %s\n", selfId, msg)
 				break
@@ -240,10 +263,10 @@ func execLocalOnRemote(stream pb.ExecutorService_ExecServer, in chan *pb.PeerMes
 	return nil
 }
 
-func execRemoteOnLocal(in chan *pb.PeerMessage, out chan *pb.PeerMessage, cmd *pb.PeerMessage) error {
+func execRemoteOnLocal(in chan *pb.PeerMessage, out func(*pb.PeerMessage), cmd *pb.PeerMessage) error {
 
 	flag := cmd.Flag
-	if flag != pb.Flag_COMMAND {
+	if flag != pb.Flag_COMMAND && flag != pb.Flag_TERMINAL && flag != pb.Flag_PIPE {
 		return fmt.Errorf("expected command, got: %s", flag.String())
 	}
 
@@ -251,26 +274,37 @@ func execRemoteOnLocal(in chan *pb.PeerMessage, out chan *pb.PeerMessage, cmd *p
 	to := cmd.From
 	script := string(cmd.Data)
 
+	// a stage of a pipeline sends its stdout on to the next stage
+	var pipe *pb.Pipe
+	if flag == pb.Flag_PIPE {
+		pipe = &pb.Pipe{}
+		if err := proto.Unmarshal(cmd.Data, pipe); err != nil {
+			return fmt.Errorf("invalid pipe: %w", err)
+		}
+		script = pipe.Command
+	}
+
 	// create a subprocess for a locally-initiated command
-	proc := exec.Command("bash", "-c", script)
 	log.Printf("[%s] execRemote(): %s\n", selfId, script)
-	stdin, err := proc.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stdin pipe: %w", err)
-	}
-	stdout, err := proc.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stdout pipe: %w", err)
+	var proc Process
+	var err error
+	if flag == pb.Flag_TERMINAL {
+		proc, err = startTerminal(script)
+	} else {
+		proc, err = backend.Start(script)
 	}
-	stderr, err := proc.StderrPipe()
 	if err != nil {
-		return fmt.Errorf("failed to get stderr pipe: %w", err)
-	}
-	if err := proc.Start(); err != nil {
+		// report the failure to the peer, which would otherwise wait forever
+		out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_MSG_STDERR, Data: []byte(err.Error() + "\n")})
+		out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_EOF_STDOUT})
+		out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_EOF_STDERR})
+		out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_EXIT, Data: []byte("127")})
 		return fmt.Errorf("failed to start command: %w", err)
 	}
+	stdin, stdout, stderr := proc.Stdin(), proc.Stdout(), proc.Stderr()
+	out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_STARTED})
 
-	done := make(chan bool, 3)
+	done := make(chan bool, 2)
 
 	handleStream := func(reader io.Reader, transform func(string, string, []byte, bool) *pb.PeerMessage) {
 		buf := make([]byte, bufsize)
@@ -285,14 +319,21 @@ func execRemoteOnLocal(in chan *pb.PeerMessage, out chan *pb.PeerMessage, cmd *p
 			if n > 0 {
 				chunk := make([]byte, n)
 				copy(chunk, buf[:n])
-				out <- transform(chId, to, chunk, false)
+				out(transform(chId, to, chunk, false))
 			}
 		}
-		out <- transform(chId, to, nil, true)
+		out(transform(chId, to, nil, true))
 		done <- true
 	}
 
 	go handleStream(stdout, func(id string, peer string, data []byte, eof bool) *pb.PeerMessage {
+		if pipe != nil {
+			if !eof {
+				return &pb.PeerMessage{Channel: pipe.Channel, From: selfId, To: pipe.Peer, Flag: pb.Flag_MSG_STDIN, Data: data}
+			}
+			// the peer that opened the stage still learns it is done writing
+			out(&pb.PeerMessage{Channel: pipe.Channel, From: selfId, To: pipe.Peer, Flag: pb.Flag_EOF_STDIN, Data: nil})
+		}
 		if !eof {
 			return &pb.PeerMessage{Channel: id, From: selfId, To: peer, Flag: pb.Flag_MSG_STDOUT, Data: data}
 		} else {
@@ -308,43 +349,111 @@ func execRemoteOnLocal(in chan *pb.PeerMessage, out chan *pb.PeerMessage, cmd *p
 		}
 	})
 
+	// stdin and signals; the loop ends when the bus closes the channel
 	go func() {
+		stdinOpen := true
 		for msg := range in {
-			if msg.Flag == pb.Flag_MSG_STDIN {
-				stdin.Write(msg.Data)
-				continue
-			}
-			if msg.Flag != pb.Flag_EOF_STDIN {
+			switch msg.Flag {
+			case pb.Flag_MSG_STDIN:
+				if stdinOpen {
+					stdin.Write(msg.Data)
+				}
+			case pb.Flag_EOF_STDIN:
+				if stdinOpen {
+					log.Printf("[%s] done handling stdin stream\n", selfId)
+					stdin.Close()
+					stdinOpen = false
+				}
+			case pb.Flag_SIGNAL:
+				signalProcess(proc, msg.Data)
+			case pb.Flag_RESIZE:
+				resizeProcess(proc, msg.Data)
+			default:
 				log.Printf("[%s] unexpected message: %s\n", selfId, msg)
 			}
-			break
 		}
-		log.Printf("[%s] done handling stdin stream\n", selfId)
-		stdin.Close()
-		done <- true
+		if stdinOpen {
+			stdin.Close()
+		}
 	}()
 
-	for i := 0; i < 3; i++ {
+	for i := 0; i < 2; i++ {
 		<-done
 	}
-	proc.Wait()
+	code, err := proc.Wait()
+	if err != nil {
+		log.Printf("[%s] error waiting for command: %s\n", selfId, err)
+	}
+	out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_EXIT, Data: []byte(strconv.Itoa(code))})
 
 	log.Printf("[%s] execRemote finished\n", selfId)
 	return nil
 }
 
-func Start(peerID string, routerUrl string, socketPath string) {
+// Options holds the optional settings of an agent.
+type Options struct {
+	Backend Backend  // runs commands; defaults to ShellBackend
+	Roots   []string // directories file access is confined to; with none, other peers are confined to the working directory
+
+	CacheDir  string // where uploaded files are kept by digest; none disables the cache
+	CacheSize int64  // bytes the cache may hold
+
+	AllowDial []string // host:port patterns other peers may have this agent dial; none allows any
+
+	JobDir string // where detached jobs spool their output; none disables jobs
+}
+
+func Start(peerID string, routerUrl string, socketPath string, opts Options) {
 
 	eSig := make(chan struct{})
 	rSig := make(chan struct{})
 	selfId = peerID
+	backend = opts.Backend
+	if backend == nil {
+		backend = ShellBackend{}
+	}
+	if err := setRoots(opts.Roots); err != nil {
+		log.Printf("[%s] invalid root: %s\n", selfId, err)
+		return
+	}
+	allowDial = opts.AllowDial
+	objects = nil
+	if opts.CacheDir != "" {
+		var err error
+		if objects, err = openCache(opts.CacheDir, opts.CacheSize); err != nil {
+			log.Printf("[%s] failed to open cache: %s\n", selfId, err)
+			return
+		}
+		log.Printf("[%s] cache %s holds %d objects (%d of %d bytes)\n", selfId, opts.CacheDir, len(objects.objects), objects.size, objects.limit)
+	}
+	jobDir = ""
+	if opts.JobDir != "" {
+		if err := openJobs(opts.JobDir); err != nil {
+			log.Printf("[%s] failed to open job directory: %s\n", selfId, err)
+			return
+		}
+		log.Printf("[%s] job directory %s holds %d jobs\n", selfId, opts.JobDir, len(jobs))
+	}
 
 	// server to process executor requests
 	go func() {
 		defer close(eSig)
 
-		s := grpc.NewServer()
+		// services the agent does not serve itself are relayed to the router,
+		// and calls meant for other peers to those peers
+		s := grpc.NewServer(
+			grpc.ForceServerCodec(frameCodec{}),
+			grpc.UnknownServiceHandler(forwardToRouter),
+			grpc.StreamInterceptor(routeStream),
+			grpc.UnaryInterceptor(routeUnary),
+		)
 		pb.RegisterExecutorServiceServer(s, &executorServer{})
+		pb.RegisterFileServiceServer(s, &fileServer{})
+		pb.RegisterSyncServiceServer(s, &syncServer{})
+		pb.RegisterCacheServiceServer(s, &cacheServer{})
+		pb.RegisterTunnelServiceServer(s, &tunnelServer{})
+		pb.RegisterSessionServiceServer(s, &sessionServer{})
+		pb.RegisterJobServiceServer(s, &jobServer{})
 
 		// Serve on socketPath
 		lis, err := net.Listen("unix", socketPath)
@@ -355,6 +464,14 @@ func Start(peerID string, routerUrl string, socketPath string) {
 		defer lis.Close()
 		log.Printf("[%s] started server on unix://%s\n", selfId, socketPath)
 
+		// calls relayed from other peers are made against the socket
+		localConn, err = grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Printf("[%s] failed to connect to own socket: %s\n", selfId, err)
+			return
+		}
+		defer localConn.Close()
+
 		log.Printf("[%s] starting ExecutorService[gRPC]\n", selfId)
 		if s.Serve(lis) != nil {
 			log.Printf("[%s] failed to start ExecutorService[gRPC]\n", selfId)
@@ -366,12 +483,13 @@ func Start(peerID string, routerUrl string, socketPath string) {
 	go func() {
 		defer close(rSig)
 
-		conn, err := grpc.NewClient(routerUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := dialRouter(routerUrl)
 		if err != nil {
 			log.Printf("[%s] cannot connect to %s [gRPC]: %s\n", selfId, routerUrl, err)
 			return
 		}
 		defer conn.Close()
+		routerConn = conn
 
 		channelSvcClient = pb.NewChannelServiceClient(conn)
 		routerSvcClient := pb.NewRouterServiceClient(conn)
@@ -384,8 +502,11 @@ func Start(peerID string, routerUrl string, socketPath string) {
 		}
 		defer stream.CloseSend()
 
+		// the registration carries the hostname, which the router hands out
+		// as a rendezvous address
+		hostname, _ := os.Hostname()
 		func() {
-			err := stream.Send(&pb.PeerMessage{From: selfId})
+			err := stream.Send(&pb.PeerMessage{From: selfId, Data: []byte(hostname)})
 			if err != nil {
 				log.Printf("[%s] error sending peer ID: %s\n", selfId, err)
 				return
@@ -397,6 +518,14 @@ func Start(peerID string, routerUrl string, socketPath string) {
 		intercept := bus.Intercept()
 
 		for message := range intercept {
+			if message.Flag == pb.Flag_CALL {
+				go func() {
+					ci, co := bus.Channel(message.Channel)
+					serveCall(ci, co, message)
+					bus.Close(message.Channel)
+				}()
+				continue
+			}
 			log.Printf("[%s] received remote command: %s\n", selfId, message)
 			go func() {
 				ci, co := bus.Channel(message.Channel)
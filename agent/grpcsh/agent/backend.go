@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os/exec"
+	"syscall"
+)
+
+// Backend starts the processes that run commands received by the agent.
+type Backend interface {
+	Start(script string) (Process, error)
+}
+
+// Process is a command started by a Backend.
+type Process interface {
+	Stdin() io.WriteCloser
+	Stdout() io.Reader
+	Stderr() io.Reader
+	// Wait blocks until the command finishes and returns its exit code.
+	Wait() (int, error)
+	// Signal delivers a signal to the command and everything it started.
+	Signal(sig syscall.Signal) error
+}
+
+// ShellBackend runs each command in a fresh `bash -c`.
+type ShellBackend struct{}
+
+type shellProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.Reader
+	stderr io.Reader
+}
+
+func (ShellBackend) Start(script string) (Process, error) {
+	return startProcess(exec.Command("bash", "-c", script))
+}
+
+func startProcess(cmd *exec.Cmd) (*shellProcess, error) {
+	// run in a process group of its own, so signals reach its children
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	p := &shellProcess{cmd: cmd}
+	var err error
+	if p.stdin, err = cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if p.stdout, err = cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	if p.stderr, err = cmd.StderrPipe(); err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *shellProcess) Stdin() io.WriteCloser { return p.stdin }
+func (p *shellProcess) Stdout() io.Reader     { return p.stdout }
+func (p *shellProcess) Stderr() io.Reader     { return p.stderr }
+
+func (p *shellProcess) Wait() (int, error) {
+	return exitCode(p.cmd.Wait())
+}
+
+func (p *shellProcess) Signal(sig syscall.Signal) error {
+	return syscall.Kill(-p.cmd.Process.Pid, sig)
+}
+
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"PIPE": syscall.SIGPIPE,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+}
+
+// signalProcess handles a SIGNAL frame, whose data names the signal, e.g. "TERM".
+func signalProcess(proc Process, name []byte) {
+	sig, exists := signals[string(name)]
+	if !exists {
+		log.Printf("[%s] unknown signal: %q\n", selfId, name)
+		return
+	}
+	log.Printf("[%s] sending SIG%s\n", selfId, name)
+	if err := proc.Signal(sig); err != nil {
+		log.Printf("[%s] failed to send SIG%s: %s\n", selfId, name, err)
+	}
+}
+
+// exitCode converts the error returned by exec.Cmd.Wait into a shell-style
+// exit code, reporting death by signal as 128+signal.
+func exitCode(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return -1, err
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), nil
+	}
+	return exitErr.ExitCode(), nil
+}
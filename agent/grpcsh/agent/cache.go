@@ -0,0 +1,271 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// cache keeps copies of uploaded files under their digest, so that the same
+// content need not be sent again. The least recently used objects are
+// evicted once the cache grows past its limit; an object's modification
+// time records its last use across restarts.
+type cache struct {
+	dir     string
+	limit   int64
+	mu      sync.Mutex
+	objects map[string]*cachedObject
+	size    int64
+}
+
+type cachedObject struct {
+	size int64
+	used time.Time
+}
+
+// objects is the agent's cache, or nil if it has none.
+var objects *cache
+
+func isDigest(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+// openCache indexes the objects in a cache directory, removing what
+// interrupted inserts left behind.
+func openCache(dir string, limit int64) (*cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &cache{dir: dir, limit: limit, objects: map[string]*cachedObject{}}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if !isDigest(entry.Name()) || !info.Mode().IsRegular() {
+			os.RemoveAll(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		c.objects[entry.Name()] = &cachedObject{size: info.Size(), used: info.ModTime()}
+		c.size += info.Size()
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+func (c *cache) path(digest string) string {
+	return filepath.Join(c.dir, digest)
+}
+
+// touch marks an object as used now.
+func (c *cache) touch(digest string, obj *cachedObject) {
+	obj.used = time.Now()
+	os.Chtimes(c.path(digest), obj.used, obj.used)
+}
+
+// restore writes the object with a digest to path, reporting whether the
+// cache had it. An object that no longer matches its digest is dropped.
+func (c *cache) restore(digest string, path string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	obj, exists := c.objects[digest]
+	if exists {
+		c.touch(digest, obj)
+	}
+	c.mu.Unlock()
+	if !exists {
+		return false
+	}
+	src, err := os.Open(c.path(digest))
+	if err != nil {
+		c.remove(digest)
+		return false
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return false
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(dst, h), src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && hex.EncodeToString(h.Sum(nil)) != digest {
+		err = fmt.Errorf("content does not match the digest")
+	}
+	if err != nil {
+		log.Printf("[%s] dropping cached %s: %s\n", selfId, digest, err)
+		os.Remove(path)
+		c.remove(digest)
+		return false
+	}
+	return true
+}
+
+// add copies a file whose digest has been verified into the cache. It runs
+// after the upload has been acknowledged; restore drops the copy should the
+// file change before it is taken.
+func (c *cache) add(digest string, path string, size int64) {
+	if c == nil || size > c.limit {
+		return
+	}
+	c.mu.Lock()
+	if obj, exists := c.objects[digest]; exists {
+		c.touch(digest, obj)
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp(c.dir, ".insert.*")
+	if err != nil {
+		log.Printf("[%s] failed to cache %s: %s\n", selfId, path, err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, src)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0400)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(digest))
+	}
+	if err != nil {
+		log.Printf("[%s] failed to cache %s: %s\n", selfId, path, err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.objects[digest]; !exists {
+		c.objects[digest] = &cachedObject{size: size}
+		c.size += size
+	}
+	c.touch(digest, c.objects[digest])
+	c.evict()
+}
+
+func (c *cache) remove(digest string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.drop(digest)
+}
+
+// drop removes an object; the lock must be held.
+func (c *cache) drop(digest string) int64 {
+	obj, exists := c.objects[digest]
+	if !exists {
+		return 0
+	}
+	os.Remove(c.path(digest))
+	delete(c.objects, digest)
+	c.size -= obj.size
+	return obj.size
+}
+
+// evict drops the least recently used objects until the cache fits its
+// limit; the lock must be held.
+func (c *cache) evict() {
+	if c.size <= c.limit {
+		return
+	}
+	for _, digest := range c.byUse() {
+		if c.size <= c.limit {
+			return
+		}
+		c.drop(digest)
+		log.Printf("[%s] evicted %s from the cache\n", selfId, digest)
+	}
+}
+
+// byUse lists the digests from the least to the most recently used; the
+// lock must be held.
+func (c *cache) byUse() []string {
+	digests := make([]string, 0, len(c.objects))
+	for digest := range c.objects {
+		digests = append(digests, digest)
+	}
+	sort.Slice(digests, func(i, j int) bool { return c.objects[digests[i]].used.Before(c.objects[digests[j]].used) })
+	return digests
+}
+
+type cacheServer struct {
+	pb.UnimplementedCacheServiceServer
+}
+
+func (s *cacheServer) List(ctx context.Context, req *emptypb.Empty) (*pb.CacheList, error) {
+	if objects == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the agent has no cache")
+	}
+	objects.mu.Lock()
+	defer objects.mu.Unlock()
+	list := &pb.CacheList{Size: objects.size, Limit: objects.limit}
+	digests := objects.byUse()
+	for i := len(digests) - 1; i >= 0; i-- {
+		obj := objects.objects[digests[i]]
+		list.Objects = append(list.Objects, &pb.CachedObject{Sha256: digests[i], Size: obj.size, LastUsed: obj.used.Unix()})
+	}
+	return list, nil
+}
+
+func (s *cacheServer) Purge(ctx context.Context, req *pb.PurgeRequest) (*pb.PurgeResponse, error) {
+	if objects == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the agent has no cache")
+	}
+	for _, prefix := range req.Sha256 {
+		if len(prefix) < 8 {
+			return nil, status.Errorf(codes.InvalidArgument, "digest prefix too short: %q", prefix)
+		}
+	}
+	objects.mu.Lock()
+	defer objects.mu.Unlock()
+	resp := &pb.PurgeResponse{}
+	for digest := range objects.objects {
+		purge := req.All
+		for _, prefix := range req.Sha256 {
+			purge = purge || strings.HasPrefix(digest, prefix)
+		}
+		if purge {
+			resp.Freed += objects.drop(digest)
+			resp.Removed++
+		}
+	}
+	log.Printf("[%s] purged %d objects (%d bytes) from the cache\n", selfId, resp.Removed, resp.Freed)
+	return resp, nil
+}
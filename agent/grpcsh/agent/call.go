@@ -0,0 +1,326 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	pb "grpcsh/pb"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// PeerKey is the metadata key naming the peer a call is meant for. Calls to
+// the agent's services that name another peer are relayed to that peer over
+// a router channel and served by the same service there.
+const PeerKey = "grpcsh-peer"
+
+// CallerKey is the metadata key under which a relayed call carries the
+// peer it came from.
+const CallerKey = "grpcsh-caller"
+
+// TargetKey is the metadata key naming the gRPC server, as host:port, a
+// call relayed from a gRPC forward is meant for.
+const TargetKey = "grpcsh-target"
+
+// reservedKey reports whether a metadata key is left out of HEADER and
+// TRAILER frames: gRPC sets those of the protocol itself, and the receiver
+// those naming peers.
+func reservedKey(key string) bool {
+	switch key {
+	case "content-type", "user-agent", "te", PeerKey, CallerKey:
+		return true
+	}
+	return strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-")
+}
+
+// encodeMetadata encodes metadata, and the time left until a deadline
+// unless it is zero, for a HEADER or TRAILER frame.
+func encodeMetadata(md metadata.MD, deadline time.Time) []byte {
+	m := &pb.CallMetadata{}
+	for key, values := range md {
+		if reservedKey(key) {
+			continue
+		}
+		entry := &pb.MetadataEntry{Key: key}
+		for _, value := range values {
+			entry.Values = append(entry.Values, []byte(value))
+		}
+		m.Entries = append(m.Entries, entry)
+	}
+	if !deadline.IsZero() {
+		m.Timeout = durationpb.New(time.Until(deadline))
+	}
+	data, _ := proto.Marshal(m)
+	return data
+}
+
+// hasMetadata reports whether metadata has keys that are relayed.
+func hasMetadata(md metadata.MD) bool {
+	for key := range md {
+		if !reservedKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeMetadata decodes the data of a HEADER or TRAILER frame, returning
+// the time left until the deadline, or 0 for none.
+func decodeMetadata(data []byte) (metadata.MD, time.Duration, error) {
+	m := &pb.CallMetadata{}
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, 0, err
+	}
+	md := metadata.MD{}
+	for _, entry := range m.Entries {
+		for _, value := range entry.Values {
+			md.Append(entry.Key, string(value))
+		}
+	}
+	return md, m.Timeout.AsDuration(), nil
+}
+
+// callTarget returns the peer a call is meant for, or "" for this agent.
+func callTarget(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if peers := md.Get(PeerKey); len(peers) > 0 && peers[0] != selfId {
+		return peers[0]
+	}
+	return ""
+}
+
+// routeStream and routeUnary relay calls naming another peer to that peer,
+// and calls from gRPC forwards to their target even where the target
+// serves a service of the agent's own.
+func routeStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	peer := callTarget(stream.Context())
+	if peer == "" {
+		if target := targetOf(stream.Context()); target != "" {
+			return relayToTarget(stream, info.FullMethod, target)
+		}
+		return handler(srv, stream)
+	}
+	return callPeer(stream.Context(), peer, info.FullMethod,
+		func() (*frame, error) {
+			f := &frame{}
+			return f, stream.RecvMsg(f)
+		},
+		func(f *frame) error {
+			return stream.SendMsg(f)
+		})
+}
+
+func routeUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	peer := callTarget(ctx)
+	if peer == "" {
+		if target := targetOf(ctx); target != "" {
+			return invokeTarget(ctx, req, info.FullMethod, target)
+		}
+		return handler(ctx, req)
+	}
+	resp, err := newResponse(info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(req.(proto.Message))
+	if err != nil {
+		return nil, err
+	}
+	sent := false
+	err = callPeer(ctx, peer, info.FullMethod,
+		func() (*frame, error) {
+			if sent {
+				return nil, io.EOF
+			}
+			sent = true
+			return &frame{data}, nil
+		},
+		func(f *frame) error {
+			return proto.Unmarshal(f.data, resp)
+		})
+	return resp, err
+}
+
+// newResponse creates an empty response message of a method, e.g.
+// "/grpcsh.FileService/Stat".
+func newResponse(method string) (proto.Message, error) {
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, err
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok || sd.Methods().ByName(protoreflect.Name(name)) == nil {
+		return nil, fmt.Errorf("unknown method: %s", method)
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(sd.Methods().ByName(protoreflect.Name(name)).Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+	return mt.New().Interface(), nil
+}
+
+// callPeer relays a call to another peer. Its metadata and deadline are
+// sent with HEADER; requests are read with recv until it fails, and are sent
+// as MSG_STDIN frames followed by EOF_STDIN. Responses come back as
+// MSG_STDOUT frames, headers and trailers with HEADER and TRAILER, and the
+// call's status with EXIT.
+func callPeer(ctx context.Context, peer string, method string, recv func() (*frame, error), send func(*frame) error) error {
+	chnl, err := channelSvcClient.CreateChannel(ctx, &emptypb.Empty{})
+	if err != nil {
+		return fmt.Errorf("failed to create channel: %w", err)
+	}
+	chId := chnl.Id
+	log.Printf("[%s] relaying %s to %s on %s\n", selfId, method, peer, chId)
+	in, out := bus.Channel(chId)
+	out(&pb.PeerMessage{Channel: chId, From: selfId, To: peer, Flag: pb.Flag_CALL, Data: []byte(method)})
+	md, _ := metadata.FromIncomingContext(ctx)
+	deadline, _ := ctx.Deadline()
+	out(&pb.PeerMessage{Channel: chId, From: selfId, To: peer, Flag: pb.Flag_HEADER, Data: encodeMetadata(md, deadline)})
+
+	// the requests end before or with the call; the channel is only closed
+	// after the last of them, as the bus does not accept frames once closed.
+	// finished is closed once the peer has ended the call; until then, it
+	// is cancelled there when the caller goes away.
+	finished := make(chan struct{})
+	go func() {
+		defer bus.Close(chId)
+		for {
+			f, err := recv()
+			if err != nil {
+				break
+			}
+			out(&pb.PeerMessage{Channel: chId, From: selfId, To: peer, Flag: pb.Flag_MSG_STDIN, Data: f.data})
+		}
+		out(&pb.PeerMessage{Channel: chId, From: selfId, To: peer, Flag: pb.Flag_EOF_STDIN})
+		<-ctx.Done()
+		select {
+		case <-finished:
+		default:
+			// the caller gave up
+			out(&pb.PeerMessage{Channel: chId, From: selfId, To: peer, Flag: pb.Flag_SIGNAL, Data: []byte("TERM")})
+		}
+	}()
+	for {
+		select {
+		case msg, ok := <-in:
+			if !ok {
+				return status.Error(codes.Unavailable, "connection to router lost")
+			}
+			switch msg.Flag {
+			case pb.Flag_MSG_STDOUT:
+				if err := send(&frame{msg.Data}); err != nil {
+					return err
+				}
+			case pb.Flag_HEADER, pb.Flag_TRAILER:
+				md, _, err := decodeMetadata(msg.Data)
+				if err != nil {
+					return fmt.Errorf("bad metadata from %s: %w", peer, err)
+				}
+				if msg.Flag == pb.Flag_HEADER {
+					grpc.SendHeader(ctx, md)
+				} else {
+					grpc.SetTrailer(ctx, md)
+				}
+			case pb.Flag_EXIT:
+				close(finished)
+				s := &spb.Status{}
+				if err := proto.Unmarshal(msg.Data, s); err != nil {
+					return fmt.Errorf("bad status from %s: %w", peer, err)
+				}
+				return status.FromProto(s).Err()
+			default:
+				log.Printf("[%s] unexpected message: %s\n", selfId, msg)
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// serveCall serves a call relayed from another peer by making it against
+// this agent's own socket, with the metadata and deadline of the HEADER
+// frame that follows CALL from callers that send one.
+func serveCall(in chan *pb.PeerMessage, out func(*pb.PeerMessage), cmd *pb.PeerMessage) {
+	chId := cmd.Channel
+	to := cmd.From
+	method := string(cmd.Data)
+	log.Printf("[%s] serving %s for %s\n", selfId, method, to)
+
+	md, timeout := metadata.MD{}, time.Duration(0)
+	first, ok := <-in
+	if ok && first.Flag == pb.Flag_HEADER {
+		var err error
+		if md, timeout, err = decodeMetadata(first.Data); err != nil {
+			log.Printf("[%s] bad metadata from %s: %s\n", selfId, to, err)
+		}
+		first = nil
+	}
+	md.Set(CallerKey, to)
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(context.Background(), md))
+	defer cancel()
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+	stream, err := localConn.NewStream(ctx, desc, method, grpc.ForceCodec(frameCodec{}))
+	if err == nil {
+		// requests and cancellation; the loop ends when the bus closes the channel
+		go func() {
+			if !ok {
+				return
+			}
+			handle := func(msg *pb.PeerMessage) {
+				switch msg.Flag {
+				case pb.Flag_MSG_STDIN:
+					stream.SendMsg(&frame{msg.Data})
+				case pb.Flag_EOF_STDIN:
+					stream.CloseSend()
+				case pb.Flag_SIGNAL:
+					cancel()
+				default:
+					log.Printf("[%s] unexpected message: %s\n", selfId, msg)
+				}
+			}
+			if first != nil {
+				handle(first)
+			}
+			for msg := range in {
+				handle(msg)
+			}
+		}()
+		if header, err := stream.Header(); err == nil && hasMetadata(header) {
+			out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_HEADER, Data: encodeMetadata(header, time.Time{})})
+		}
+		for {
+			f := &frame{}
+			if err = stream.RecvMsg(f); err != nil {
+				break
+			}
+			out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_MSG_STDOUT, Data: f.data})
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if trailer := stream.Trailer(); hasMetadata(trailer) {
+			out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_TRAILER, Data: encodeMetadata(trailer, time.Time{})})
+		}
+	}
+	data, _ := proto.Marshal(status.Convert(err).Proto())
+	out(&pb.PeerMessage{Channel: chId, From: selfId, To: to, Flag: pb.Flag_EXIT, Data: data})
+	log.Printf("[%s] served %s for %s: %v\n", selfId, method, to, err)
+}
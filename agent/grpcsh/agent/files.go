@@ -0,0 +1,342 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fileServer struct {
+	pb.UnimplementedFileServiceServer
+}
+
+// roots are the directories file access is confined to; with none, any
+// path is allowed to calls made on the agent's socket, and calls relayed
+// from other peers are confined to workDir.
+var (
+	roots   []string
+	workDir string
+)
+
+// setRoots resolves the directories file access is confined to.
+func setRoots(dirs []string) error {
+	roots = nil
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if workDir, err = filepath.EvalSymlinks(wd); err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if abs, err = filepath.EvalSymlinks(abs); err != nil {
+			return err
+		}
+		roots = append(roots, abs)
+	}
+	return nil
+}
+
+// rootsOf returns the directories a call may access, none meaning any.
+func rootsOf(ctx context.Context) []string {
+	if len(roots) == 0 && callerOf(ctx) != "" {
+		return []string{workDir}
+	}
+	return roots
+}
+
+// resolvePath maps a path given by a client to a file on this agent.
+// Relative paths are relative to the first root, or else to the agent's
+// working directory; paths outside of the roots, also by way of symlinks,
+// are refused.
+func resolvePath(ctx context.Context, path string) (string, error) {
+	if path == "" {
+		return "", status.Error(codes.InvalidArgument, "path must not be empty")
+	}
+	roots := rootsOf(ctx)
+	if len(roots) == 0 {
+		return filepath.Abs(path)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(roots[0], path)
+	}
+	path = filepath.Clean(path)
+	real, err := realPath(path)
+	if err != nil {
+		return "", err
+	}
+	for _, root := range roots {
+		if within(real, root) {
+			return path, nil
+		}
+	}
+	return "", status.Errorf(codes.PermissionDenied, "outside of the agent's roots: %s", path)
+}
+
+// realPath resolves the symlinks in a path, of which only a prefix may exist.
+func realPath(path string) (string, error) {
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fileError(err)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...), nil
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+func within(path string, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// isRoot reports whether a path is one of the roots of a call, which may
+// not be removed or renamed.
+func isRoot(ctx context.Context, path string) bool {
+	for _, root := range rootsOf(ctx) {
+		if real, err := realPath(path); err == nil && real == root {
+			return true
+		}
+	}
+	return false
+}
+
+// fileDigest returns the hex SHA-256 digest of a file's contents.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// partPath is where an upload is written until its digest has been
+// verified; an interrupted upload of the same content resumes from it.
+func partPath(path string, digest string) string {
+	if len(digest) > 16 {
+		digest = digest[:16]
+	}
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+"."+digest+".part")
+}
+
+// fileError converts a filesystem error into a gRPC status.
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, syscall.ENOTEMPTY):
+		// also matches ErrExist
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, fs.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, fs.ErrPermission):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, fs.ErrExist):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return err
+}
+
+func (s *fileServer) Upload(stream pb.FileService_UploadServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to receive header: %w", err)
+	}
+	header := first.Header
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "first chunk must carry the header")
+	}
+	path, err := resolvePath(stream.Context(), header.Path)
+	if err != nil {
+		return err
+	}
+	if len(header.Sha256) != sha256.Size*2 {
+		return status.Error(codes.InvalidArgument, "header must carry the sha256 digest")
+	}
+	log.Printf("[%s] receiving %s (%d bytes)\n", selfId, path, header.Size)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fileError(err)
+	}
+
+	// take the content from the cache if it is there, or else keep what an
+	// interrupted upload of it left behind
+	part := partPath(path, header.Sha256)
+	cached := objects.restore(header.Sha256, part)
+	f, err := os.OpenFile(part, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if offset > header.Size {
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		offset, _ = f.Seek(0, io.SeekStart)
+	}
+	if cached {
+		log.Printf("[%s] found %s in the cache\n", selfId, path)
+	} else if offset > 0 {
+		log.Printf("[%s] resuming %s at %d\n", selfId, path, offset)
+	}
+	if err := stream.Send(&pb.FileChunk{Offset: offset, Cached: cached}); err != nil {
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the part is kept for the next attempt
+			return err
+		}
+		if chunk.Offset != offset {
+			return status.Errorf(codes.InvalidArgument, "chunk at %d, expected %d", chunk.Offset, offset)
+		}
+		n, err := f.Write(chunk.Data)
+		offset += int64(n)
+		if err != nil {
+			return fileError(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fileError(err)
+	}
+
+	if offset != header.Size {
+		return status.Errorf(codes.DataLoss, "received %d of %d bytes", offset, header.Size)
+	}
+	digest, err := fileDigest(part)
+	if err != nil {
+		return fileError(err)
+	}
+	if digest != header.Sha256 {
+		os.Remove(part)
+		return status.Errorf(codes.DataLoss, "digest mismatch: got %s, expected %s", digest, header.Sha256)
+	}
+	if header.Mode != 0 {
+		if err := os.Chmod(part, fs.FileMode(header.Mode)&fs.ModePerm); err != nil {
+			return fileError(err)
+		}
+	}
+	if header.Mtime != 0 {
+		mtime := time.Unix(0, header.Mtime)
+		if err := os.Chtimes(part, mtime, mtime); err != nil {
+			return fileError(err)
+		}
+	}
+	if err := os.Rename(part, path); err != nil {
+		return fileError(err)
+	}
+	log.Printf("[%s] received %s\n", selfId, path)
+	if !cached {
+		go objects.add(digest, path, offset)
+	}
+
+	stored, err := statHeader(path)
+	if err != nil {
+		return fileError(err)
+	}
+	stored.Sha256 = digest
+	return stream.Send(&pb.FileChunk{Header: stored, Offset: offset})
+}
+
+func (s *fileServer) Download(req *pb.DownloadRequest, stream pb.FileService_DownloadServer) error {
+	path, err := resolvePath(stream.Context(), req.Path)
+	if err != nil {
+		return err
+	}
+	header, err := statHeader(path)
+	if err != nil {
+		return fileError(err)
+	}
+	if header.Sha256, err = fileDigest(path); err != nil {
+		return fileError(err)
+	}
+	// resume only if the file is still the one the client has part of
+	offset := int64(0)
+	if len(req.Sha256) >= 16 && strings.HasPrefix(header.Sha256, req.Sha256) && req.Offset > 0 && req.Offset <= header.Size {
+		offset = req.Offset
+	}
+	log.Printf("[%s] sending %s (%d bytes from %d)\n", selfId, path, header.Size, offset)
+	if err := stream.Send(&pb.FileChunk{Header: header, Offset: offset}); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	buf := make([]byte, bufsize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.FileChunk{Offset: offset, Data: buf[:n]}); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fileError(err)
+		}
+	}
+}
+
+// statHeader describes a regular file, without its digest.
+func statHeader(path string) (*pb.FileHeader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, status.Errorf(codes.InvalidArgument, "not a regular file: %s", path)
+	}
+	return &pb.FileHeader{
+		Path:  path,
+		Size:  info.Size(),
+		Mode:  uint32(info.Mode().Perm()),
+		Mtime: info.ModTime().UnixNano(),
+	}, nil
+}
@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// how often followed files are checked for new data, rotation and truncation
+const followInterval = 250 * time.Millisecond
+
+// Follow streams several files over one call. Each file is watched by its
+// own goroutine, whose events are sent in the order they occur.
+func (s *fileServer) Follow(req *pb.FollowRequest, stream pb.FileService_FollowServer) error {
+	if len(req.Files) == 0 {
+		return status.Error(codes.InvalidArgument, "no files to follow")
+	}
+	paths := make([]string, len(req.Files))
+	for i, file := range req.Files {
+		path, err := resolvePath(stream.Context(), file.Path)
+		if err != nil {
+			return err
+		}
+		paths[i] = path
+	}
+	log.Printf("[%s] following %v\n", selfId, paths)
+	ctx := stream.Context()
+	events := make(chan *pb.FollowEvent, 16)
+	for i, file := range req.Files {
+		w := &watcher{index: int32(i), path: paths[i], events: events}
+		go w.follow(ctx, file.Offset, file.Lines)
+	}
+	for {
+		select {
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			log.Printf("[%s] stopped following %v\n", selfId, paths)
+			return nil
+		}
+	}
+}
+
+// watcher follows one file for Follow.
+type watcher struct {
+	index   int32
+	path    string
+	events  chan<- *pb.FollowEvent
+	f       *os.File
+	offset  int64
+	missing bool // reported missing, and not opened since
+	rotated bool // closed because another file took the path
+}
+
+func (w *watcher) emit(ctx context.Context, kind pb.FollowEvent_Kind, data []byte) bool {
+	select {
+	case w.events <- &pb.FollowEvent{File: w.index, Kind: kind, Offset: w.offset, Data: data}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (w *watcher) follow(ctx context.Context, offset int64, lines int32) {
+	defer func() {
+		if w.f != nil {
+			w.f.Close()
+		}
+	}()
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	// the requested offset applies to the file there at the start; files
+	// that take the path later are read from their start
+	first := true
+	for {
+		if w.f == nil {
+			if !w.open(ctx, first, offset, lines) {
+				return
+			}
+			first = false
+		}
+		if w.f != nil && !w.poll(ctx) {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// open opens the file, reporting it missing the first time it is not there.
+func (w *watcher) open(ctx context.Context, first bool, offset int64, lines int32) bool {
+	f, err := os.Open(w.path)
+	if err != nil {
+		if w.missing {
+			return true
+		}
+		w.missing, w.offset = true, 0
+		return w.emit(ctx, pb.FollowEvent_MISSING, nil)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return true
+	}
+	w.f, w.offset = f, 0
+	kind := pb.FollowEvent_OPENED
+	if w.rotated && !w.missing {
+		kind = pb.FollowEvent_ROTATED
+	}
+	w.rotated, w.missing = false, false
+	if first {
+		switch {
+		case offset < 0:
+			w.offset = lineOffset(f, info.Size(), lines)
+		case offset > info.Size():
+			// the file was replaced or truncated since the offset was read
+			if !w.emit(ctx, pb.FollowEvent_TRUNCATED, nil) {
+				return false
+			}
+		default:
+			w.offset = offset
+		}
+	}
+	return w.emit(ctx, kind, nil)
+}
+
+// poll sends what was appended to the file and checks whether it was
+// rotated or truncated, closing it if it was rotated.
+func (w *watcher) poll(ctx context.Context) bool {
+	// look at the path before draining, so nothing written to the old file
+	// in between is lost
+	current, statErr := os.Stat(w.path)
+	buf := make([]byte, defaultReadSize)
+	for {
+		n, err := w.f.ReadAt(buf, w.offset)
+		if n > 0 {
+			if !w.emit(ctx, pb.FollowEvent_DATA, append([]byte(nil), buf[:n]...)) {
+				return false
+			}
+			w.offset += int64(n)
+		}
+		if err == io.EOF || n == 0 {
+			break
+		}
+		if err != nil {
+			log.Printf("[%s] failed to read %s: %s\n", selfId, w.path, err)
+			break
+		}
+	}
+	info, err := w.f.Stat()
+	if err == nil && statErr == nil && os.SameFile(info, current) {
+		if info.Size() < w.offset {
+			w.offset = 0
+			return w.emit(ctx, pb.FollowEvent_TRUNCATED, nil)
+		}
+		return true
+	}
+	// a new file took the path, or it was removed
+	w.f.Close()
+	w.f = nil
+	w.rotated = true
+	return true
+}
+
+// lineOffset finds where the last lines of a file start.
+func lineOffset(f *os.File, size int64, lines int32) int64 {
+	if lines <= 0 {
+		return size
+	}
+	buf := make([]byte, defaultReadSize)
+	end := size
+	// a newline ending the file does not start a line
+	skip := true
+	for end > 0 {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		block := buf[:end-start]
+		if _, err := f.ReadAt(block, start); err != nil && err != io.EOF {
+			return size
+		}
+		for i := len(block) - 1; i >= 0; i-- {
+			if block[i] != '\n' {
+				skip = false
+				continue
+			}
+			if skip {
+				skip = false
+				continue
+			}
+			if lines--; lines == 0 {
+				return start + int64(i) + 1
+			}
+		}
+		end = start
+	}
+	return 0
+}
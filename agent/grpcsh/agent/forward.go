@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"sync/atomic"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Forward listens on a port of this agent and tunnels each connection it
+// accepts to a target dialed by a peer, for as long as the client stays.
+// Other peers may only have it listen on loopback addresses, and not have
+// it dial targets itself, which would skip the check of -allow-dial.
+func (s *tunnelServer) Forward(req *pb.ForwardRequest, stream pb.TunnelService_ForwardServer) error {
+	if req.Socks && req.Grpc {
+		return status.Error(codes.InvalidArgument, "a forward serves either SOCKS5 or gRPC")
+	}
+	if caller := callerOf(stream.Context()); caller != "" {
+		if req.Peer == selfId {
+			log.Printf("[%s] refused a forward dialing from %s for %s\n", selfId, selfId, caller)
+			return status.Errorf(codes.PermissionDenied, "other peers may not have %s forward to itself", selfId)
+		}
+		if !req.Reverse && !loopback(req.Listen) {
+			log.Printf("[%s] refused to listen on %s for %s\n", selfId, req.Listen, caller)
+			return status.Errorf(codes.PermissionDenied, "other peers may only have %s listen on loopback addresses", selfId)
+		}
+	}
+	if req.Socks {
+		req.Target = ""
+	} else if _, _, err := net.SplitHostPort(req.Target); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid target %q: %s", req.Target, err)
+	}
+	if req.Reverse {
+		return reverseForward(req, stream)
+	}
+	peer := req.Peer
+	if peer == "" {
+		peer = callerOf(stream.Context())
+	}
+	ln, err := net.Listen("tcp", req.Listen)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer ln.Close()
+	if req.Socks {
+		log.Printf("[%s] serving SOCKS5 on %s for %s\n", selfId, ln.Addr(), peer)
+	} else if req.Grpc {
+		log.Printf("[%s] serving gRPC on %s for %s on %s\n", selfId, ln.Addr(), req.Target, peer)
+	} else {
+		log.Printf("[%s] forwarding %s to %s on %s\n", selfId, ln.Addr(), req.Target, peer)
+	}
+	defer log.Printf("[%s] stopped forwarding %s\n", selfId, ln.Addr())
+	if err := stream.Send(&pb.ForwardEvent{Kind: pb.ForwardEvent_LISTENING, Address: ln.Addr().String()}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	events := make(chan *pb.ForwardEvent, 16)
+	accepted := make(chan error, 1)
+	if req.Grpc {
+		server := grpcForwarder(ctx, peer, req.Target, events)
+		defer server.Stop()
+		go func() { accepted <- server.Serve(ln) }()
+	} else {
+		go func() {
+			var count atomic.Int64
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					accepted <- err
+					return
+				}
+				go forwardConn(ctx, conn, count.Add(1), peer, req.Target, events)
+			}
+		}()
+	}
+	for {
+		select {
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case err := <-accepted:
+			return status.Error(codes.Unavailable, err.Error())
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// loopback reports whether an address to listen on is only reachable from
+// this host.
+func loopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// reverseForward has a peer listen and dial the target from this agent,
+// if it allows the target to be exposed to peers.
+func reverseForward(req *pb.ForwardRequest, stream pb.TunnelService_ForwardServer) error {
+	if req.Peer == "" {
+		return status.Error(codes.InvalidArgument, "peer must not be empty")
+	}
+	if !dialAllowed(req.Target) {
+		return status.Errorf(codes.PermissionDenied, "%s may not be exposed to peers by %s", req.Target, selfId)
+	}
+	log.Printf("[%s] exposing %s on %s:%s\n", selfId, req.Target, req.Peer, req.Listen)
+	ctx := metadata.AppendToOutgoingContext(stream.Context(), PeerKey, req.Peer)
+	remote, err := pb.NewTunnelServiceClient(localConn).Forward(ctx, &pb.ForwardRequest{Listen: req.Listen, Peer: selfId, Target: req.Target, Grpc: req.Grpc})
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := remote.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+}
+
+// forwardConn tunnels an accepted connection to a target dialed by a peer,
+// or, without a target, to the one its SOCKS5 request names.
+func forwardConn(ctx context.Context, conn net.Conn, id int64, peer string, target string, events chan<- *pb.ForwardEvent) {
+	defer conn.Close()
+	report := func(event *pb.ForwardEvent) {
+		event.Connection = id
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+	report(&pb.ForwardEvent{Kind: pb.ForwardEvent_OPENED, Address: conn.RemoteAddr().String()})
+	socks := target == ""
+	if socks {
+		var err error
+		if target, err = socksRequest(conn); err != nil {
+			report(&pb.ForwardEvent{Kind: pb.ForwardEvent_CLOSED, Address: target, Error: err.Error()})
+			return
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := dialPeer(ctx, peer, target)
+	if socks && err != nil {
+		socksReply(conn, socksError(err))
+	}
+	if err != nil {
+		report(&pb.ForwardEvent{Kind: pb.ForwardEvent_CLOSED, Address: target, Error: status.Convert(err).Message()})
+		return
+	}
+	if socks {
+		if err := socksReply(conn, socksSucceeded); err != nil {
+			report(&pb.ForwardEvent{Kind: pb.ForwardEvent_CLOSED, Address: target, Error: err.Error()})
+			return
+		}
+	}
+	sent, received, err := splice(conn, stream)
+	closed := &pb.ForwardEvent{Kind: pb.ForwardEvent_CLOSED, Address: target, Sent: sent, Received: received}
+	if err != nil {
+		closed.Error = status.Convert(err).Message()
+	}
+	report(closed)
+}
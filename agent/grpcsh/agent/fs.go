@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	defaultPageSize = 1000
+	maxPageSize     = 10000
+	defaultReadSize = 64 * 1024
+	maxReadSize     = 1024 * 1024 // well under the 4MiB a gRPC message may carry
+)
+
+// fileInfo describes a file without following it if it is a symlink.
+func fileInfo(path string) (*pb.FileInfo, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, fileError(err)
+	}
+	return describe(path, info), nil
+}
+
+func describe(path string, info fs.FileInfo) *pb.FileInfo {
+	fi := &pb.FileInfo{
+		Path:  path,
+		Name:  info.Name(),
+		Size:  info.Size(),
+		Mode:  uint32(info.Mode()),
+		Mtime: info.ModTime().UnixNano(),
+		IsDir: info.IsDir(),
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		fi.Link, _ = os.Readlink(path)
+	}
+	return fi
+}
+
+func (s *fileServer) Stat(ctx context.Context, req *pb.PathRequest) (*pb.FileInfo, error) {
+	path, err := resolvePath(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo(path)
+}
+
+// ListDir lists a directory a page at a time. Pages follow the order of
+// names, so entries added or removed between pages do not shift the rest.
+func (s *fileServer) ListDir(ctx context.Context, req *pb.ListDirRequest) (*pb.ListDirResponse, error) {
+	path, err := resolvePath(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	size := int(req.PageSize)
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fileError(err)
+	}
+	// ReadDir sorts by name
+	start := sort.Search(len(entries), func(i int) bool { return entries[i].Name() > req.PageToken })
+	resp := &pb.ListDirResponse{}
+	for _, entry := range entries[start:] {
+		if len(resp.Entries) == size {
+			resp.NextPageToken = resp.Entries[size-1].Name
+			break
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// removed since it was listed
+			continue
+		}
+		resp.Entries = append(resp.Entries, describe(filepath.Join(path, entry.Name()), info))
+	}
+	return resp, nil
+}
+
+func (s *fileServer) ReadRange(ctx context.Context, req *pb.ReadRangeRequest) (*pb.ReadRangeResponse, error) {
+	path, err := resolvePath(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	length := req.Length
+	if length <= 0 {
+		length = defaultReadSize
+	}
+	if length > maxReadSize {
+		return nil, status.Errorf(codes.InvalidArgument, "length must be at most %d", maxReadSize)
+	}
+	if req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fileError(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fileError(err)
+	}
+	if info.IsDir() {
+		return nil, status.Errorf(codes.InvalidArgument, "is a directory: %s", path)
+	}
+	buf := make([]byte, length)
+	n, err := f.ReadAt(buf, req.Offset)
+	if err != nil && err != io.EOF {
+		return nil, fileError(err)
+	}
+	return &pb.ReadRangeResponse{Data: buf[:n], Size: info.Size(), Eof: req.Offset+int64(n) >= info.Size()}, nil
+}
+
+func (s *fileServer) Mkdir(ctx context.Context, req *pb.MkdirRequest) (*pb.FileInfo, error) {
+	path, err := resolvePath(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	mode := fs.FileMode(req.Mode) & fs.ModePerm
+	if mode == 0 {
+		mode = 0755
+	}
+	if req.Parents {
+		err = os.MkdirAll(path, mode)
+	} else {
+		err = os.Mkdir(path, mode)
+	}
+	if err != nil {
+		return nil, fileError(err)
+	}
+	log.Printf("[%s] created directory %s\n", selfId, path)
+	return fileInfo(path)
+}
+
+func (s *fileServer) Remove(ctx context.Context, req *pb.RemoveRequest) (*emptypb.Empty, error) {
+	path, err := resolvePath(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if isRoot(ctx, path) {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot remove a root: %s", path)
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return nil, fileError(err)
+	}
+	if req.Recursive {
+		err = os.RemoveAll(path)
+	} else {
+		err = os.Remove(path)
+	}
+	if err != nil {
+		return nil, fileError(err)
+	}
+	log.Printf("[%s] removed %s\n", selfId, path)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *fileServer) Rename(ctx context.Context, req *pb.RenameRequest) (*pb.FileInfo, error) {
+	from, err := resolvePath(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolvePath(ctx, req.To)
+	if err != nil {
+		return nil, err
+	}
+	if isRoot(ctx, from) {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot rename a root: %s", from)
+	}
+	if err := os.Rename(from, to); err != nil {
+		return nil, fileError(err)
+	}
+	log.Printf("[%s] renamed %s to %s\n", selfId, from, to)
+	return fileInfo(to)
+}
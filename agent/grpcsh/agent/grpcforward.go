@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// connections to the gRPC servers calls are relayed to, by host:port
+var (
+	targetConns   = make(map[string]*grpc.ClientConn)
+	targetConnsMu sync.Mutex
+)
+
+// targetOf returns the gRPC server a call from a gRPC forward is meant for.
+func targetOf(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if targets := md.Get(TargetKey); len(targets) > 0 {
+		return targets[0]
+	}
+	return ""
+}
+
+func targetConn(target string) (*grpc.ClientConn, error) {
+	targetConnsMu.Lock()
+	defer targetConnsMu.Unlock()
+	if conn, exists := targetConns[target]; exists {
+		return conn, nil
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	targetConns[target] = conn
+	return conn, nil
+}
+
+// dialTarget returns the connection to the gRPC server a call names, if
+// other peers may have this agent dial it, and the call's context without
+// the metadata grpcsh added.
+func dialTarget(ctx context.Context, method string, target string) (*grpc.ClientConn, context.Context, error) {
+	if caller := callerOf(ctx); caller != "" && !dialAllowed(target) {
+		log.Printf("[%s] refused to relay %s to %s for %s\n", selfId, method, target, caller)
+		return nil, nil, status.Errorf(codes.PermissionDenied, "%s may not be dialed through %s", target, selfId)
+	}
+	conn, err := targetConn(target)
+	if err != nil {
+		return nil, nil, status.Error(codes.Unavailable, err.Error())
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Delete(TargetKey)
+	md.Delete(CallerKey)
+	md.Delete(PeerKey)
+	return conn, metadata.NewIncomingContext(ctx, md), nil
+}
+
+// relayToTarget makes a call on the gRPC server it names.
+func relayToTarget(stream grpc.ServerStream, method string, target string) error {
+	conn, ctx, err := dialTarget(stream.Context(), method, target)
+	if err != nil {
+		return err
+	}
+	return relayStream(ctx, stream, conn, method)
+}
+
+// invokeTarget makes a unary call on the gRPC server it names, passing
+// back the headers and trailers of the server.
+func invokeTarget(ctx context.Context, req any, method string, target string) (any, error) {
+	conn, ctx, err := dialTarget(ctx, method, target)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newResponse(method)
+	if err != nil {
+		return nil, err
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header, trailer metadata.MD
+	err = conn.Invoke(metadata.NewOutgoingContext(ctx, md), method, req, resp, grpc.Header(&header), grpc.Trailer(&trailer), grpc.ForceCodec(frameCodec{}))
+	grpc.SetHeader(ctx, header)
+	grpc.SetTrailer(ctx, trailer)
+	return resp, err
+}
+
+// grpcForwarder serves any gRPC call by relaying it to a peer, which makes
+// it on the gRPC server at target, reporting each call as a connection.
+func grpcForwarder(ctx context.Context, peer string, target string, events chan<- *pb.ForwardEvent) *grpc.Server {
+	var count atomic.Int64
+	report := func(event *pb.ForwardEvent) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+	return grpc.NewServer(
+		grpc.ForceServerCodec(frameCodec{}),
+		grpc.UnknownServiceHandler(func(srv any, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			id := count.Add(1)
+			report(&pb.ForwardEvent{Kind: pb.ForwardEvent_OPENED, Connection: id, Address: method})
+			md, _ := metadata.FromIncomingContext(stream.Context())
+			md = md.Copy()
+			md.Set(TargetKey, target)
+			var sent, received atomic.Int64
+			err := callPeer(metadata.NewIncomingContext(stream.Context(), md), peer, method,
+				func() (*frame, error) {
+					f := &frame{}
+					if err := stream.RecvMsg(f); err != nil {
+						return nil, err
+					}
+					sent.Add(int64(len(f.data)))
+					return f, nil
+				},
+				func(f *frame) error {
+					received.Add(int64(len(f.data)))
+					return stream.SendMsg(f)
+				})
+			closed := &pb.ForwardEvent{Kind: pb.ForwardEvent_CLOSED, Connection: id, Address: target, Sent: sent.Load(), Received: received.Load()}
+			if err != nil {
+				st := status.Convert(err)
+				closed.Error = fmt.Sprintf("%s: %s", st.Code(), st.Message())
+			}
+			report(closed)
+			return err
+		}),
+	)
+}
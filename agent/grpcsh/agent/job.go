@@ -0,0 +1,374 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// job is a command running detached from the call that submitted it. Its
+// stdout and stderr are spooled to <id>.out and <id>.err in the job
+// directory, and its status to <id>.job, so that jobs that ended are still
+// known after the agent restarts.
+type job struct {
+	id        string
+	command   string
+	proc      Process // nil for jobs of an earlier run of the agent
+	state     pb.Job_State
+	code      int
+	started   time.Time
+	ended     time.Time
+	size      [2]int64 // of stdout and stderr
+	cancelled bool
+	changed   chan struct{} // closed and replaced when output is spooled or the job ends
+}
+
+var (
+	jobs   = make(map[string]*job)
+	jobsMu sync.Mutex
+	jobDir string
+)
+
+type jobServer struct {
+	pb.UnimplementedJobServiceServer
+}
+
+// openJobs loads the jobs spooled in a directory by earlier runs of the
+// agent. Jobs that were running then are marked lost.
+func openJobs(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.job"))
+	if err != nil {
+		return err
+	}
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	jobDir = dir
+	jobs = make(map[string]*job)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var saved pb.Job
+		if err := proto.Unmarshal(data, &saved); err != nil {
+			log.Printf("[%s] ignoring job file %s: %s\n", selfId, path, err)
+			continue
+		}
+		j := &job{
+			id:      saved.Id,
+			command: saved.Command,
+			state:   saved.State,
+			code:    int(saved.ExitCode),
+			started: time.Unix(saved.Started, 0),
+			changed: make(chan struct{}),
+		}
+		if saved.Ended != 0 {
+			j.ended = time.Unix(saved.Ended, 0)
+		}
+		for i, ext := range []string{".out", ".err"} {
+			if info, err := os.Stat(j.path(ext)); err == nil {
+				j.size[i] = info.Size()
+			}
+		}
+		if j.state == pb.Job_RUNNING {
+			j.state, j.code = pb.Job_LOST, -1
+			j.save()
+		}
+		jobs[j.id] = j
+	}
+	return nil
+}
+
+func (j *job) path(ext string) string {
+	return filepath.Join(jobDir, j.id+ext)
+}
+
+// report returns the status of a job; jobsMu must be held.
+func (j *job) report() *pb.Job {
+	r := &pb.Job{
+		Id:         j.id,
+		Command:    j.command,
+		State:      j.state,
+		ExitCode:   int32(j.code),
+		Started:    j.started.Unix(),
+		StdoutSize: j.size[0],
+		StderrSize: j.size[1],
+	}
+	if !j.ended.IsZero() {
+		r.Ended = j.ended.Unix()
+	}
+	return r
+}
+
+// save writes the status of a job to its job file; jobsMu must be held.
+func (j *job) save() {
+	data, _ := proto.Marshal(j.report())
+	tmp := j.path(".job.tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Printf("[%s] failed to save job %s: %s\n", selfId, j.id, err)
+		return
+	}
+	if err := os.Rename(tmp, j.path(".job")); err != nil {
+		log.Printf("[%s] failed to save job %s: %s\n", selfId, j.id, err)
+	}
+}
+
+// notify wakes the calls following a job; jobsMu must be held.
+func (j *job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// spool copies one stream of a job to its file.
+func (j *job) spool(stream int, r io.Reader, f *os.File) {
+	defer f.Close()
+	buf := make([]byte, bufsize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := f.Write(buf[:n]); werr != nil {
+				log.Printf("[%s] failed to spool output of job %s: %s\n", selfId, j.id, werr)
+				// keep draining, so the job does not block on a full pipe
+			} else {
+				jobsMu.Lock()
+				j.size[stream] += int64(n)
+				j.notify()
+				jobsMu.Unlock()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func lookupJob(id string) (*job, error) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	if jobDir == "" {
+		return nil, status.Error(codes.FailedPrecondition, "the agent keeps no jobs (see -job-dir)")
+	}
+	j, exists := jobs[id]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "no such job: %s", id)
+	}
+	return j, nil
+}
+
+func (js *jobServer) Submit(ctx context.Context, req *pb.JobRequest) (*pb.Job, error) {
+	if strings.TrimSpace(req.Command) == "" {
+		return nil, status.Error(codes.InvalidArgument, "command must not be empty")
+	}
+	jobsMu.Lock()
+	dir := jobDir
+	jobsMu.Unlock()
+	if dir == "" {
+		return nil, status.Error(codes.FailedPrecondition, "the agent keeps no jobs (see -job-dir)")
+	}
+	nonce := make([]byte, 6)
+	rand.Read(nonce)
+	j := &job{id: hex.EncodeToString(nonce), command: req.Command, changed: make(chan struct{})}
+	var files [2]*os.File
+	for i, ext := range []string{".out", ".err"} {
+		f, err := os.OpenFile(j.path(ext), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			if files[0] != nil {
+				files[0].Close()
+			}
+			return nil, status.Errorf(codes.Internal, "failed to create output file: %s", err)
+		}
+		files[i] = f
+	}
+	proc, err := backend.Start(req.Command)
+	if err != nil {
+		files[0].Close()
+		files[1].Close()
+		os.Remove(j.path(".out"))
+		os.Remove(j.path(".err"))
+		return nil, status.Errorf(codes.Internal, "failed to start job: %s", err)
+	}
+	proc.Stdin().Close()
+	j.proc, j.started = proc, time.Now()
+	jobsMu.Lock()
+	jobs[j.id] = j
+	j.save()
+	report := j.report()
+	jobsMu.Unlock()
+	log.Printf("[%s] started job %s: %s\n", selfId, j.id, j.command)
+
+	go func() {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		for i, r := range []io.Reader{proc.Stdout(), proc.Stderr()} {
+			go func(i int, r io.Reader) {
+				defer wg.Done()
+				j.spool(i, r, files[i])
+			}(i, r)
+		}
+		wg.Wait()
+		code, err := proc.Wait()
+		if err != nil {
+			log.Printf("[%s] error waiting for job %s: %s\n", selfId, j.id, err)
+		}
+		jobsMu.Lock()
+		j.state, j.code, j.ended = pb.Job_EXITED, code, time.Now()
+		if j.cancelled {
+			j.state = pb.Job_CANCELLED
+		}
+		j.save()
+		j.notify()
+		jobsMu.Unlock()
+		log.Printf("[%s] job %s exited with code %d\n", selfId, j.id, code)
+	}()
+	return report, nil
+}
+
+func (js *jobServer) List(ctx context.Context, req *emptypb.Empty) (*pb.JobList, error) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	list := &pb.JobList{}
+	for _, j := range jobs {
+		list.Jobs = append(list.Jobs, j.report())
+	}
+	sort.Slice(list.Jobs, func(i, k int) bool {
+		a, b := list.Jobs[i], list.Jobs[k]
+		if a.Started != b.Started {
+			return a.Started < b.Started
+		}
+		return a.Id < b.Id
+	})
+	return list, nil
+}
+
+func (js *jobServer) Status(ctx context.Context, req *pb.JobId) (*pb.Job, error) {
+	j, err := lookupJob(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	return j.report(), nil
+}
+
+// Output reads the files no further than the sizes spool has recorded, so
+// that all output read before the job ended has been sent when EXIT is.
+func (js *jobServer) Output(req *pb.OutputRequest, stream pb.JobService_OutputServer) error {
+	j, err := lookupJob(req.Id)
+	if err != nil {
+		return err
+	}
+	offsets := [2]int64{req.StdoutOffset, req.StderrOffset}
+	if offsets[0] < 0 || offsets[1] < 0 {
+		return status.Error(codes.InvalidArgument, "offsets must not be negative")
+	}
+	var files [2]*os.File
+	for i, ext := range []string{".out", ".err"} {
+		f, err := os.Open(j.path(ext))
+		if err != nil {
+			return status.Errorf(codes.NotFound, "output of job %s is gone: %s", j.id, err)
+		}
+		defer f.Close()
+		files[i] = f
+	}
+	flags := [2]pb.Flag{pb.Flag_MSG_STDOUT, pb.Flag_MSG_STDERR}
+	buf := make([]byte, defaultReadSize)
+	for {
+		jobsMu.Lock()
+		size, state, code, changed := j.size, j.state, j.code, j.changed
+		jobsMu.Unlock()
+		for i := range files {
+			for offsets[i] < size[i] {
+				n := int64(len(buf))
+				if rest := size[i] - offsets[i]; rest < n {
+					n = rest
+				}
+				read, err := files[i].ReadAt(buf[:n], offsets[i])
+				if read > 0 {
+					if err := stream.Send(&pb.JobOutput{Flag: flags[i], Data: buf[:read], Offset: offsets[i]}); err != nil {
+						return err
+					}
+					offsets[i] += int64(read)
+				}
+				if err != nil {
+					return status.Errorf(codes.Internal, "failed to read output of job %s: %s", j.id, err)
+				}
+			}
+		}
+		if state != pb.Job_RUNNING {
+			return stream.Send(&pb.JobOutput{Flag: pb.Flag_EXIT, Data: []byte(strconv.Itoa(code))})
+		}
+		if !req.Follow {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (js *jobServer) Cancel(ctx context.Context, req *pb.CancelRequest) (*pb.Job, error) {
+	j, err := lookupJob(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	name := req.Signal
+	if name == "" {
+		name = "TERM"
+	}
+	sig, exists := signals[name]
+	if !exists {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown signal: %q", name)
+	}
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	if j.state != pb.Job_RUNNING {
+		return nil, status.Errorf(codes.FailedPrecondition, "job %s has ended", j.id)
+	}
+	log.Printf("[%s] cancelling job %s with SIG%s\n", selfId, j.id, name)
+	if err := j.proc.Signal(sig); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to signal job %s: %s", j.id, err)
+	}
+	j.cancelled = true
+	return j.report(), nil
+}
+
+func (js *jobServer) Remove(ctx context.Context, req *pb.JobId) (*emptypb.Empty, error) {
+	j, err := lookupJob(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	if j.state == pb.Job_RUNNING {
+		return nil, status.Errorf(codes.FailedPrecondition, "job %s is running", j.id)
+	}
+	delete(jobs, j.id)
+	for _, ext := range []string{".job", ".out", ".err"} {
+		if err := os.Remove(j.path(ext)); err != nil && !os.IsNotExist(err) {
+			log.Printf("[%s] failed to remove %s: %s\n", selfId, j.path(ext), err)
+		}
+	}
+	log.Printf("[%s] removed job %s\n", selfId, j.id)
+	return &emptypb.Empty{}, nil
+}
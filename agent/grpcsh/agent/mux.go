@@ -2,46 +2,136 @@ package agent
 
 import (
 	"crypto/md5"
+	"fmt"
 	pb "grpcsh/pb"
 	"log"
+	"strconv"
+	"strings"
 	"sync"
 )
 
+// bytes of each direction of a channel a peer may send ahead of the WINDOW
+// frames the receiver sends back once it consumed them; the router uses
+// the same window
+const channelWindow = 8 * 1024 * 1024
+
+// a channel whose senders run this many windows ahead of the receiver is
+// closed, rather than buffered without bound
+const channelOverrun = 4
+
 type Bus struct {
-	channels_i map[string]chan *pb.PeerMessage
-	channels_o map[string]chan *pb.PeerMessage
-	stream     pb.RouterService_ConnectClient
-	intercept  chan *pb.PeerMessage
-	mu         sync.RWMutex
+	channels  map[string]*busChannel
+	senders   map[creditKey]*busChannel // the channel sending the data a WINDOW frame credits
+	stream    pb.RouterService_ConnectClient
+	intercept chan *pb.PeerMessage
+	mu        sync.RWMutex
+	sendMu    sync.Mutex
+}
+
+// busChannel queues inbound frames of one channel, so that a slow or
+// finished consumer never blocks the frames of other channels. What it
+// queues is bounded by the windows of its senders, which wait for this
+// channel's consumer rather than for the bus.
+type busChannel struct {
+	id       string
+	in       chan *pb.PeerMessage
+	out      chan *pb.PeerMessage
+	queue    []*pb.PeerMessage
+	queued   int64               // data bytes in queue
+	inflight *pb.PeerMessage     // taken from queue, not yet consumed
+	owed     map[owedKey]int64   // data bytes consumed, not yet credited to their sender
+	credits  map[creditKey]int64 // data bytes this channel may still send
+	closed   bool
+	done     chan struct{}
+	cond     *sync.Cond
+	mu       sync.Mutex
+}
+
+// creditKey names the data a WINDOW frame credits: that sent on a channel
+// in one direction, "in" for MSG_STDIN, or "out" for MSG_STDOUT and
+// MSG_STDERR. A channel's data is sent in each direction by one peer only.
+type creditKey struct {
+	channel string
+	dir     string
+}
+
+// owedKey names the data of a channel received from one peer in one direction.
+type owedKey struct {
+	peer string
+	dir  string
+}
+
+// direction returns the direction of a data frame, or "" for other frames.
+func direction(flag pb.Flag) string {
+	switch flag {
+	case pb.Flag_MSG_STDIN:
+		return "in"
+	case pb.Flag_MSG_STDOUT, pb.Flag_MSG_STDERR:
+		return "out"
+	}
+	return ""
+}
+
+func windowFrame(channel string, to string, dir string, n int64) *pb.PeerMessage {
+	return &pb.PeerMessage{Channel: channel, From: selfId, To: to, Flag: pb.Flag_WINDOW, Data: []byte(fmt.Sprintf("%s %d", dir, n))}
+}
+
+func parseWindow(data []byte) (string, int64, error) {
+	dir, count, _ := strings.Cut(string(data), " ")
+	n, err := strconv.ParseInt(count, 10, 64)
+	if err != nil || (dir != "in" && dir != "out") {
+		return "", 0, fmt.Errorf("bad window: %q", data)
+	}
+	return dir, n, nil
+}
+
+// opening reports whether a frame starts a new channel on the receiving agent.
+func opening(flag pb.Flag) bool {
+	return flag == pb.Flag_COMMAND || flag == pb.Flag_TERMINAL || flag == pb.Flag_PIPE || flag == pb.Flag_CALL
 }
 
 func CreateBus(stream pb.RouterService_ConnectClient) *Bus {
 	b := &Bus{
-		channels_i: make(map[string]chan *pb.PeerMessage),
-		channels_o: make(map[string]chan *pb.PeerMessage),
-		stream:     stream,
-		intercept:  make(chan *pb.PeerMessage),
+		channels:  make(map[string]*busChannel),
+		senders:   make(map[creditKey]*busChannel),
+		stream:    stream,
+		intercept: make(chan *pb.PeerMessage),
 	}
 
 	go func() {
 		for {
 			msg, err := b.stream.Recv()
-			log.Printf("[%s] mux received: %s<-%s, channel=%s, flag=%s, hash=%x, length=%d\n", selfId, msg.To, msg.From, msg.Channel, msg.Flag.String(), md5.Sum(msg.Data), len(msg.Data))
 			if err != nil {
+				log.Printf("[%s] mux stopped receiving: %s\n", selfId, err)
+				b.closeAll()
 				return
 			}
-			if msg.Flag == pb.Flag_COMMAND {
+			log.Printf("[%s] mux received: %s<-%s, channel=%s, flag=%s, hash=%x, length=%d\n", selfId, msg.To, msg.From, msg.Channel, msg.Flag.String(), md5.Sum(msg.Data), len(msg.Data))
+			if msg.Flag == pb.Flag_WINDOW {
+				b.credit(msg)
+				continue
+			}
+			if opening(msg.Flag) {
+				// register the channel before handing it off, so that frames
+				// following the opening one are queued rather than dropped
+				b.channel(msg.Channel)
 				b.intercept <- msg
-			} else {
-				c := msg.Channel
-				b.mu.Lock()
-				ch, exists := b.channels_i[c]
-				if !exists {
-					ch = make(chan *pb.PeerMessage)
-					b.channels_i[c] = ch
+				continue
+			}
+			b.mu.RLock()
+			ch, exists := b.channels[msg.Channel]
+			b.mu.RUnlock()
+			if !exists {
+				log.Printf("[%s] mux dropped %s for closed channel %s\n", selfId, msg.Flag, msg.Channel)
+				// dropped data is credited at once, so its sender is not held up
+				if dir := direction(msg.Flag); dir != "" {
+					b.Send(windowFrame(msg.Channel, msg.From, dir, int64(len(msg.Data))))
 				}
-				b.mu.Unlock()
-				ch <- msg
+				continue
+			}
+			if !ch.push(msg) {
+				log.Printf("[%s] mux closing channel %s, whose senders overran their window\n", selfId, msg.Channel)
+				b.Close(msg.Channel)
 			}
 		}
 	}()
@@ -49,50 +139,250 @@ func CreateBus(stream pb.RouterService_ConnectClient) *Bus {
 	return b
 }
 
-func (b *Bus) Channel(id string) (chan *pb.PeerMessage, chan *pb.PeerMessage) {
-	log.Printf("[%s] mux received bi-channel request: %s\n", selfId, id)
+func (b *Bus) channel(id string) *busChannel {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch, exists := b.channels[id]
+	if exists {
+		return ch
+	}
+	ch = &busChannel{
+		id:      id,
+		in:      make(chan *pb.PeerMessage),
+		out:     make(chan *pb.PeerMessage),
+		owed:    make(map[owedKey]int64),
+		credits: make(map[creditKey]int64),
+		done:    make(chan struct{}),
+	}
+	ch.cond = sync.NewCond(&ch.mu)
+	b.channels[id] = ch
 
-	begin_channel_loop := false
+	// deliver queued frames to the consumer, and credit their senders
+	go func() {
+		defer close(ch.in)
+		for {
+			ch.mu.Lock()
+			for len(ch.queue) == 0 && !ch.closed {
+				ch.cond.Wait()
+			}
+			if ch.closed {
+				ch.mu.Unlock()
+				return
+			}
+			msg := ch.queue[0]
+			ch.queue = ch.queue[1:]
+			ch.inflight = msg
+			ch.mu.Unlock()
+			select {
+			case ch.in <- msg:
+			case <-ch.done:
+				return
+			}
+			if grant := ch.consumed(msg); grant != nil {
+				b.Send(grant)
+			}
+		}
+	}()
 
-	b.mu.Lock()
+	// send outbound frames to the router, data only while there is credit
+	go func() {
+		for {
+			var msg *pb.PeerMessage
+			select {
+			case msg = <-ch.out:
+			case <-ch.done:
+				return
+			}
+			if dir := direction(msg.Flag); dir != "" && !b.acquire(ch, creditKey{msg.Channel, dir}, len(msg.Data)) {
+				continue
+			}
+			log.Printf("[%s] mux sending: %s->%s, channel=%s, flag=%s, hash=%x, length=%d\n", selfId, msg.From, msg.To, msg.Channel, msg.Flag.String(), md5.Sum(msg.Data), len(msg.Data))
+			if err := b.Send(msg); err != nil {
+				log.Printf("[%s] mux got error when sending: %s\n", selfId, err)
+			}
+		}
+	}()
+	return ch
+}
 
-	if b.channels_i[id] == nil {
-		b.channels_i[id] = make(chan *pb.PeerMessage)
+// send hands a frame to be sent to the router. Frames sent once the
+// channel is closed are dropped, as out is never closed: its producers may
+// outlive the channel, e.g. when the connection to the router is lost.
+func (c *busChannel) send(msg *pb.PeerMessage) {
+	select {
+	case c.out <- msg:
+	case <-c.done:
 	}
+}
 
-	if b.channels_o[id] == nil {
-		b.channels_o[id] = make(chan *pb.PeerMessage)
-		begin_channel_loop = true
+// push queues a frame, and reports false if the channel's senders are too
+// far ahead of its consumer.
+func (c *busChannel) push(msg *pb.PeerMessage) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return true
+	}
+	if direction(msg.Flag) != "" {
+		c.queued += int64(len(msg.Data))
+		if c.queued > channelOverrun*channelWindow {
+			return false
+		}
 	}
+	c.queue = append(c.queue, msg)
+	c.cond.Broadcast()
+	return true
+}
 
-	b.mu.Unlock()
+// consumed accounts for a frame the consumer took, and returns the WINDOW
+// frame crediting its sender once a quarter of a window is owed to it.
+func (c *busChannel) consumed(msg *pb.PeerMessage) *pb.PeerMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inflight = nil
+	dir := direction(msg.Flag)
+	if dir == "" || c.closed {
+		return nil
+	}
+	n := int64(len(msg.Data))
+	c.queued -= n
+	key := owedKey{msg.From, dir}
+	c.owed[key] += n
+	if c.owed[key] < channelWindow/4 {
+		return nil
+	}
+	n = c.owed[key]
+	delete(c.owed, key)
+	return windowFrame(c.id, msg.From, dir, n)
+}
 
-	if begin_channel_loop {
-		go func() {
-			ch := b.channels_o[id]
-			for msg := range ch {
-				log.Printf("[%s] mux sending: %s->%s, channel=%s, flag=%s, hash=%x, length=%d\n", selfId, msg.From, msg.To, msg.Channel, msg.Flag.String(), md5.Sum(msg.Data), len(msg.Data))
-				if err := b.stream.Send(msg); err != nil {
-					log.Printf("[%s] mux got error when sending: %s\n", selfId, err)
-					return
-				}
-			}
-		}()
+// acquire takes credit to send n bytes of data, waiting for WINDOW frames
+// while there is none left. It fails once the channel is closed.
+func (b *Bus) acquire(ch *busChannel, key creditKey, n int) bool {
+	ch.mu.Lock()
+	_, known := ch.credits[key]
+	ch.mu.Unlock()
+	if !known {
+		b.mu.Lock()
+		b.senders[key] = ch
+		b.mu.Unlock()
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if !known {
+		ch.credits[key] = channelWindow
+	}
+	for ch.credits[key] <= 0 && !ch.closed {
+		ch.cond.Wait()
+	}
+	if ch.closed {
+		return false
+	}
+	ch.credits[key] -= int64(n)
+	return true
+}
+
+// credit hands the credit of a WINDOW frame to the channel sending that data.
+func (b *Bus) credit(msg *pb.PeerMessage) {
+	dir, n, err := parseWindow(msg.Data)
+	if err != nil {
+		log.Printf("[%s] mux dropped window from %s: %s\n", selfId, msg.From, err)
+		return
+	}
+	key := creditKey{msg.Channel, dir}
+	b.mu.RLock()
+	ch := b.senders[key]
+	b.mu.RUnlock()
+	if ch == nil {
+		return
+	}
+	ch.mu.Lock()
+	if _, exists := ch.credits[key]; exists {
+		ch.credits[key] += n
+		ch.cond.Broadcast()
+	}
+	ch.mu.Unlock()
+}
+
+// close stops the channel, and returns the WINDOW frames crediting what
+// was received but never consumed.
+func (c *busChannel) close() []*pb.PeerMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	unconsumed := c.queue
+	if c.inflight != nil {
+		unconsumed = append(unconsumed, c.inflight)
+	}
+	for _, msg := range unconsumed {
+		if dir := direction(msg.Flag); dir != "" {
+			c.owed[owedKey{msg.From, dir}] += int64(len(msg.Data))
+		}
+	}
+	var grants []*pb.PeerMessage
+	for key, n := range c.owed {
+		grants = append(grants, windowFrame(c.id, key.peer, key.dir, n))
 	}
-	return b.channels_i[id], b.channels_o[id]
+	c.closed = true
+	c.queue = nil
+	c.inflight = nil
+	c.owed = nil
+	close(c.done)
+	c.cond.Broadcast()
+	return grants
+}
+
+// Send writes a frame to the router; the stream allows only one sender at a time.
+func (b *Bus) Send(msg *pb.PeerMessage) error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+	return b.stream.Send(msg)
+}
+
+// Channel returns the frames received on a channel, and the function
+// sending frames on it.
+func (b *Bus) Channel(id string) (chan *pb.PeerMessage, func(*pb.PeerMessage)) {
+	log.Printf("[%s] mux received bi-channel request: %s\n", selfId, id)
+	ch := b.channel(id)
+	return ch.in, ch.send
 }
 
 func (b *Bus) Intercept() chan *pb.PeerMessage {
 	return b.intercept
 }
 
+// Close releases a channel; frames that still arrive for it are dropped.
 func (b *Bus) Close(id string) {
-	if ch, exists := b.channels_o[id]; exists {
-		close(ch)
-		delete(b.channels_o, id)
+	b.mu.Lock()
+	ch, exists := b.channels[id]
+	delete(b.channels, id)
+	b.forget(ch)
+	b.mu.Unlock()
+	if exists {
+		for _, grant := range ch.close() {
+			b.Send(grant)
+		}
 	}
-	if ch, exists := b.channels_i[id]; exists {
-		close(ch)
-		delete(b.channels_i, id)
+}
+
+// forget removes the credit a closed channel was sending with; b.mu must be held.
+func (b *Bus) forget(ch *busChannel) {
+	for key, sender := range b.senders {
+		if sender == ch {
+			delete(b.senders, key)
+		}
+	}
+}
+
+func (b *Bus) closeAll() {
+	b.mu.Lock()
+	channels := b.channels
+	b.channels = make(map[string]*busChannel)
+	b.senders = make(map[creditKey]*busChannel)
+	b.mu.Unlock()
+	for _, ch := range channels {
+		ch.close()
 	}
 }
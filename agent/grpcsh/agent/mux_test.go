@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"errors"
+	pb "grpcsh/pb"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// fakeRouter is the agent's end of a connection to the router. Recv waits
+// for frames given to it, and fails once the connection is dropped.
+type fakeRouter struct {
+	grpc.ClientStream
+	frames  chan *pb.PeerMessage
+	dropped chan struct{}
+	sent    chan *pb.PeerMessage
+}
+
+func newFakeRouter() *fakeRouter {
+	return &fakeRouter{
+		frames:  make(chan *pb.PeerMessage),
+		dropped: make(chan struct{}),
+		sent:    make(chan *pb.PeerMessage, 100),
+	}
+}
+
+func (r *fakeRouter) Send(msg *pb.PeerMessage) error {
+	select {
+	case r.sent <- msg:
+	default:
+	}
+	return nil
+}
+
+func (r *fakeRouter) Recv() (*pb.PeerMessage, error) {
+	select {
+	case msg := <-r.frames:
+		return msg, nil
+	case <-r.dropped:
+		return nil, errors.New("connection dropped")
+	}
+}
+
+func (r *fakeRouter) drop() {
+	close(r.dropped)
+}
+
+// await returns the first frame sent with the given flag.
+func (r *fakeRouter) await(t *testing.T, flag pb.Flag) *pb.PeerMessage {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-r.sent:
+			if msg.Flag == flag {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("no %s frame sent", flag)
+		}
+	}
+}
+
+func TestDisconnectDuringCommand(t *testing.T) {
+	selfId = "agent"
+	backend = ShellBackend{}
+	router := newFakeRouter()
+	b := CreateBus(router)
+
+	in, out := b.Channel("c1")
+	cmd := &pb.PeerMessage{Channel: "c1", From: "client", To: "agent", Flag: pb.Flag_COMMAND, Data: []byte("sleep 1; echo hi")}
+	finished := make(chan error)
+	go func() {
+		err := execRemoteOnLocal(in, out, cmd)
+		b.Close("c1")
+		finished <- err
+	}()
+	router.await(t, pb.Flag_STARTED)
+
+	// the command's output and exit status follow the closed channel
+	router.drop()
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Fatalf("command failed: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not finish after the connection was dropped")
+	}
+}
+
+func TestSendAfterClose(t *testing.T) {
+	selfId = "agent"
+	router := newFakeRouter()
+	b := CreateBus(router)
+
+	_, out := b.Channel("c1")
+	b.Close("c1")
+	sent := make(chan struct{})
+	go func() {
+		out(&pb.PeerMessage{Channel: "c1", From: "agent", To: "client", Flag: pb.Flag_MSG_STDOUT, Data: []byte("hi")})
+		out(&pb.PeerMessage{Channel: "c1", From: "agent", To: "client", Flag: pb.Flag_EXIT, Data: []byte("0")})
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sending on a closed channel blocked")
+	}
+	select {
+	case msg := <-router.sent:
+		t.Fatalf("frame sent on a closed channel: %s", msg)
+	default:
+	}
+}
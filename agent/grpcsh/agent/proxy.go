@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// frame is a message relayed without being decoded.
+type frame struct {
+	data []byte
+}
+
+// frameCodec passes frames through untouched and encodes everything else
+// as protobuf, so relayed and locally served calls share one server.
+type frameCodec struct{}
+
+func (frameCodec) Marshal(v any) ([]byte, error) {
+	if f, ok := v.(*frame); ok {
+		return f.data, nil
+	}
+	return proto.Marshal(v.(proto.Message))
+}
+
+func (frameCodec) Unmarshal(data []byte, v any) error {
+	if f, ok := v.(*frame); ok {
+		f.data = append([]byte(nil), data...)
+		return nil
+	}
+	return proto.Unmarshal(data, v.(proto.Message))
+}
+
+func (frameCodec) Name() string {
+	return "proto"
+}
+
+// forwardToRouter relays calls to services the agent does not implement
+// to the router, so that clients only ever need the agent's socket. Calls
+// naming a target come from gRPC forwards and go to the server there.
+func forwardToRouter(srv any, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	if target := targetOf(stream.Context()); target != "" {
+		return relayToTarget(stream, method, target)
+	}
+	if routerConn == nil {
+		return fmt.Errorf("not connected to router")
+	}
+	return relayStream(stream.Context(), stream, routerConn, method)
+}
+
+// relayStream copies a call, with the metadata of ctx, to another connection.
+func relayStream(ctx context.Context, stream grpc.ServerStream, conn *grpc.ClientConn, method string) error {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = metadata.NewOutgoingContext(ctx, md.Copy())
+	}
+	desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+	upstream, err := conn.NewStream(ctx, desc, method, grpc.ForceCodec(frameCodec{}))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			f := &frame{}
+			if err := stream.RecvMsg(f); err != nil {
+				upstream.CloseSend()
+				return
+			}
+			if err := upstream.SendMsg(f); err != nil {
+				return
+			}
+		}
+	}()
+
+	header, err := upstream.Header()
+	if err != nil {
+		return err
+	}
+	if err := stream.SendHeader(header); err != nil {
+		return err
+	}
+	for {
+		f := &frame{}
+		if err := upstream.RecvMsg(f); err != nil {
+			stream.SetTrailer(upstream.Trailer())
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := stream.SendMsg(f); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a new pseudo-terminal, returning its master side and the
+// path of its slave side.
+func openPTY() (*os.File, string, error) {
+	tty, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	var n uint32
+	err = control(tty, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		n, err = unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+		return err
+	})
+	if err != nil {
+		tty.Close()
+		return nil, "", err
+	}
+	return tty, fmt.Sprintf("/dev/pts/%d", n), nil
+}
+
+func setWinsize(tty *os.File, cols, rows uint16) error {
+	return control(tty, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Col: cols, Row: rows})
+	})
+}
+
+// control runs an ioctl on a file without taking it out of the poller,
+// as Fd would, so that reads of it can still be interrupted.
+func control(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ioctlErr error
+	if err := conn.Control(func(fd uintptr) { ioctlErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return ioctlErr
+}
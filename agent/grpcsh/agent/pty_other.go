@@ -0,0 +1,16 @@
+//go:build !linux
+
+package agent
+
+import (
+	"errors"
+	"os"
+)
+
+func openPTY() (*os.File, string, error) {
+	return nil, "", errors.New("terminals are only supported on Linux")
+}
+
+func setWinsize(tty *os.File, cols, rows uint16) error {
+	return errors.New("terminals are only supported on Linux")
+}
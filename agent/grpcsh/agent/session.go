@@ -0,0 +1,265 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// sessionSetup is the first input of a session's shell, which ignores
+// SIGINT with a handler rather than SIG_IGN, so that the commands it runs
+// can still be interrupted
+const sessionSetup = "trap : INT\n"
+
+// session is a long-lived shell reading commands from its stdin. Each
+// command is followed by a marker on stdout and stderr, which ends its
+// output; the marker on stdout carries the exit code and working directory.
+type session struct {
+	name     string
+	proc     Process
+	stdout   *shellOutput
+	stderr   *shellOutput
+	created  time.Time
+	used     time.Time
+	cwd      string
+	commands int
+	busy     bool
+	run      sync.Mutex // held while a command runs
+	once     sync.Once
+	code     int
+}
+
+// shellOutput is a stream of a session's shell, holding what was read past
+// the end of the last command's output.
+type shellOutput struct {
+	r       io.Reader
+	pending []byte
+}
+
+var (
+	sessions   = make(map[string]*session)
+	sessionsMu sync.Mutex
+)
+
+type sessionServer struct {
+	pb.UnimplementedSessionServiceServer
+}
+
+// readUntil passes the stream to emit until marker, which it consumes.
+func (o *shellOutput) readUntil(marker []byte, emit func([]byte)) error {
+	buf := make([]byte, bufsize)
+	for {
+		if i := bytes.Index(o.pending, marker); i >= 0 {
+			if i > 0 {
+				emit(o.pending[:i])
+			}
+			o.pending = append([]byte(nil), o.pending[i+len(marker):]...)
+			return nil
+		}
+		// hold back what may be the start of the marker
+		if keep := len(marker) - 1; len(o.pending) > keep {
+			emit(o.pending[:len(o.pending)-keep])
+			o.pending = append([]byte(nil), o.pending[len(o.pending)-keep:]...)
+		}
+		n, err := o.r.Read(buf)
+		o.pending = append(o.pending, buf[:n]...)
+		if err != nil {
+			if len(o.pending) > 0 {
+				emit(o.pending)
+				o.pending = nil
+			}
+			return err
+		}
+	}
+}
+
+// startSession returns the session of a name, starting its shell if needed.
+// Session shells are always local, whatever the backend, as a batch job
+// would not start before its input ends.
+func startSession(name string) (*session, error) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	if s, exists := sessions[name]; exists {
+		return s, nil
+	}
+	proc, err := ShellBackend{}.Start("exec bash")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(proc.Stdin(), sessionSetup); err != nil {
+		proc.Signal(syscall.SIGKILL)
+		proc.Wait()
+		return nil, err
+	}
+	now := time.Now()
+	s := &session{
+		name:    name,
+		proc:    proc,
+		stdout:  &shellOutput{r: proc.Stdout()},
+		stderr:  &shellOutput{r: proc.Stderr()},
+		created: now,
+		used:    now,
+	}
+	sessions[name] = s
+	log.Printf("[%s] started session %s\n", selfId, name)
+	return s, nil
+}
+
+// end removes a session whose shell exited or is being closed, and
+// returns the shell's exit code once it is gone.
+func (s *session) end() int {
+	sessionsMu.Lock()
+	if sessions[s.name] == s {
+		delete(sessions, s.name)
+	}
+	sessionsMu.Unlock()
+	s.once.Do(func() {
+		code, err := s.proc.Wait()
+		if err != nil {
+			log.Printf("[%s] error waiting for session %s: %s\n", selfId, s.name, err)
+		}
+		s.code = code
+		log.Printf("[%s] session %s ended with code %d\n", selfId, s.name, code)
+	})
+	return s.code
+}
+
+func (ss *sessionServer) Run(req *pb.SessionCommand, stream pb.SessionService_RunServer) error {
+	if req.Session == "" {
+		return status.Error(codes.InvalidArgument, "session name must not be empty")
+	}
+	s, err := startSession(req.Session)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to start session: %s", err)
+	}
+	if !s.run.TryLock() {
+		return status.Errorf(codes.FailedPrecondition, "session %s is running a command", s.name)
+	}
+	defer s.run.Unlock()
+	sessionsMu.Lock()
+	s.busy = true
+	sessionsMu.Unlock()
+	defer func() {
+		sessionsMu.Lock()
+		s.busy = false
+		s.used = time.Now()
+		sessionsMu.Unlock()
+	}()
+
+	// the marker is put together by printf, so that it is not in the
+	// input of the shell, which may echo it
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	tag := hex.EncodeToString(nonce)
+	marker := []byte("\x00grpcsh-" + tag + " ")
+	script := fmt.Sprintf("eval %s </dev/null; printf '\\0grpcsh-%%s %%d %%s\\0' %s \"$?\" \"$PWD\"; printf '\\0grpcsh-%%s ' %s >&2\n",
+		shellQuote(req.Command), tag, tag)
+	log.Printf("[%s] session %s: %s\n", selfId, s.name, req.Command)
+	if _, err := io.WriteString(s.proc.Stdin(), script); err != nil {
+		code := s.end()
+		return stream.Send(&pb.SessionOutput{Flag: pb.Flag_EXIT, Data: []byte(strconv.Itoa(code)), Closed: true})
+	}
+
+	// the output is read to the marker even if the client goes away,
+	// which interrupts the command
+	var mu sync.Mutex
+	send := func(flag pb.Flag) func([]byte) {
+		return func(data []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			stream.Send(&pb.SessionOutput{Flag: flag, Data: data})
+		}
+	}
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-stream.Context().Done():
+			select {
+			case <-finished:
+			default:
+				log.Printf("[%s] interrupting session %s\n", selfId, s.name)
+				s.proc.Signal(syscall.SIGINT)
+			}
+		case <-finished:
+		}
+	}()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.stderr.readUntil(marker, send(pb.Flag_MSG_STDERR))
+	}()
+	err = s.stdout.readUntil(marker, send(pb.Flag_MSG_STDOUT))
+	var trailer []byte
+	if err == nil {
+		err = s.stdout.readUntil([]byte{0}, func(data []byte) { trailer = append(trailer, data...) })
+	}
+	if err != nil {
+		// the shell exited, e.g. by the command running exit
+		<-errs
+		code := s.end()
+		return stream.Send(&pb.SessionOutput{Flag: pb.Flag_EXIT, Data: []byte(strconv.Itoa(code)), Closed: true})
+	}
+	<-errs
+
+	code, cwd, _ := strings.Cut(string(trailer), " ")
+	sessionsMu.Lock()
+	s.cwd = cwd
+	s.commands++
+	sessionsMu.Unlock()
+	return stream.Send(&pb.SessionOutput{Flag: pb.Flag_EXIT, Data: []byte(code), Cwd: cwd})
+}
+
+func (ss *sessionServer) List(ctx context.Context, req *emptypb.Empty) (*pb.SessionList, error) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	list := &pb.SessionList{}
+	for _, s := range sessions {
+		list.Sessions = append(list.Sessions, &pb.Session{
+			Name:     s.name,
+			Cwd:      s.cwd,
+			Created:  s.created.Unix(),
+			LastUsed: s.used.Unix(),
+			Commands: int32(s.commands),
+			Busy:     s.busy,
+		})
+	}
+	sort.Slice(list.Sessions, func(i, j int) bool { return list.Sessions[i].Name < list.Sessions[j].Name })
+	return list, nil
+}
+
+// Close ends the shell of a session, and the command it is running.
+func (ss *sessionServer) Close(ctx context.Context, req *pb.SessionName) (*emptypb.Empty, error) {
+	sessionsMu.Lock()
+	s, exists := sessions[req.Name]
+	delete(sessions, req.Name)
+	sessionsMu.Unlock()
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "no such session: %s", req.Name)
+	}
+	log.Printf("[%s] closing session %s\n", selfId, s.name)
+	s.proc.Stdin().Close()
+	s.proc.Signal(syscall.SIGHUP)
+	go s.end()
+	return &emptypb.Empty{}, nil
+}
+
+// shellQuote quotes s for use as a single word in a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
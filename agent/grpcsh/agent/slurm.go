@@ -0,0 +1,264 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SlurmBackend submits each command as a batch job with sbatch, polls
+// squeue until the job leaves the queue, and streams the job's output
+// files back while it runs. The final state and exit code come from sacct.
+//
+// Jobs have no interactive stdin: whatever the client sends is spooled to a
+// file and passed with --input, so the job is submitted once stdin closes.
+type SlurmBackend struct {
+	SpoolDir string        // directory shared with the compute nodes
+	Args     []string      // extra arguments passed to sbatch
+	Poll     time.Duration // interval between squeue polls
+}
+
+type slurmProcess struct {
+	backend *SlurmBackend
+	base    string
+	stdin   *os.File
+	stdoutR *io.PipeReader
+	stdoutW *io.PipeWriter
+	stderrR *io.PipeReader
+	stderrW *io.PipeWriter
+	code    int
+	done    chan struct{}
+	jobId   string
+	signal  syscall.Signal // set if signalled before submission
+	mu      sync.Mutex
+}
+
+// states in which squeue may still report a job that has finished
+var slurmTerminalStates = map[string]bool{
+	"BOOT_FAIL":     true,
+	"CANCELLED":     true,
+	"COMPLETED":     true,
+	"DEADLINE":      true,
+	"FAILED":        true,
+	"NODE_FAIL":     true,
+	"OUT_OF_MEMORY": true,
+	"PREEMPTED":     true,
+	"TIMEOUT":       true,
+}
+
+func (b *SlurmBackend) Start(script string) (Process, error) {
+	spool, err := filepath.Abs(b.SpoolDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve spool dir: %w", err)
+	}
+	if err := os.MkdirAll(spool, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create spool dir: %w", err)
+	}
+	f, err := os.CreateTemp(spool, "job-*.sh")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job script: %w", err)
+	}
+	_, err = f.WriteString("#!/bin/bash\n" + script + "\n")
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write job script: %w", err)
+	}
+	base := strings.TrimSuffix(f.Name(), ".sh")
+	stdin, err := os.Create(base + ".in")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job input: %w", err)
+	}
+	p := &slurmProcess{backend: b, base: base, stdin: stdin, done: make(chan struct{})}
+	p.stdoutR, p.stdoutW = io.Pipe()
+	p.stderrR, p.stderrW = io.Pipe()
+	return p, nil
+}
+
+// Stdin returns the job's input file; closing it submits the job.
+func (p *slurmProcess) Stdin() io.WriteCloser { return p }
+func (p *slurmProcess) Stdout() io.Reader     { return p.stdoutR }
+func (p *slurmProcess) Stderr() io.Reader     { return p.stderrR }
+
+func (p *slurmProcess) Write(data []byte) (int, error) {
+	return p.stdin.Write(data)
+}
+
+func (p *slurmProcess) Close() error {
+	err := p.stdin.Close()
+	go p.run()
+	return err
+}
+
+func (p *slurmProcess) Wait() (int, error) {
+	<-p.done
+	return p.code, nil
+}
+
+func (p *slurmProcess) run() {
+	defer close(p.done)
+	defer p.cleanup()
+	defer p.stderrW.Close()
+	defer p.stdoutW.Close()
+
+	p.mu.Lock()
+	if p.signal != 0 {
+		p.mu.Unlock()
+		p.code = 128 + int(p.signal)
+		return
+	}
+	jobId, err := p.submit()
+	p.jobId = jobId
+	p.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(p.stderrW, "sbatch: %s\n", err)
+		p.code = 1
+		return
+	}
+	log.Printf("[%s] submitted slurm job %s\n", selfId, jobId)
+
+	poll := p.backend.Poll
+	if poll <= 0 {
+		poll = 2 * time.Second
+	}
+	stdout := &fileTail{path: p.base + ".out", w: p.stdoutW}
+	stderr := &fileTail{path: p.base + ".err", w: p.stderrW}
+	state := ""
+	for {
+		time.Sleep(poll)
+		stdout.poll()
+		stderr.poll()
+		s, err := squeueState(jobId)
+		if err != nil {
+			log.Printf("[%s] squeue failed for job %s: %s\n", selfId, jobId, err)
+			break
+		}
+		if s != "" {
+			state = s
+		}
+		if s == "" || slurmTerminalStates[s] {
+			break
+		}
+	}
+	stdout.poll()
+	stderr.poll()
+
+	finalState, code, err := sacctResult(jobId)
+	if err != nil {
+		log.Printf("[%s] sacct failed for job %s: %s\n", selfId, jobId, err)
+		finalState = state
+		code = 0
+		if finalState != "" && finalState != "COMPLETED" {
+			code = 1
+		}
+	}
+	log.Printf("[%s] slurm job %s finished: state=%s, code=%d\n", selfId, jobId, finalState, code)
+	p.code = code
+}
+
+// Signal forwards a signal to the job with scancel, or keeps a job that
+// has not been submitted yet from ever running.
+func (p *slurmProcess) Signal(sig syscall.Signal) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.jobId == "" {
+		p.signal = sig
+		return nil
+	}
+	return exec.Command("scancel", "--full", "--signal="+strconv.Itoa(int(sig)), p.jobId).Run()
+}
+
+func (p *slurmProcess) submit() (string, error) {
+	args := []string{
+		"--parsable",
+		"--output=" + p.base + ".out",
+		"--error=" + p.base + ".err",
+		"--input=" + p.base + ".in",
+	}
+	args = append(args, p.backend.Args...)
+	args = append(args, p.base+".sh")
+	out, err := exec.Command("sbatch", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	// --parsable prints "jobid" or "jobid;cluster"
+	jobId, _, _ := strings.Cut(strings.TrimSpace(string(out)), ";")
+	if jobId == "" {
+		return "", fmt.Errorf("no job id in output: %q", out)
+	}
+	return jobId, nil
+}
+
+func (p *slurmProcess) cleanup() {
+	for _, ext := range []string{".sh", ".in", ".out", ".err"} {
+		os.Remove(p.base + ext)
+	}
+}
+
+// squeueState returns the job's current state, or "" once it has left the queue.
+func squeueState(jobId string) (string, error) {
+	out, err := exec.Command("squeue", "-h", "-j", jobId, "-o", "%T").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// sacctResult returns the job's final state and its shell-style exit code.
+func sacctResult(jobId string) (string, int, error) {
+	out, err := exec.Command("sacct", "-n", "-P", "-X", "-j", jobId, "-o", "State,ExitCode").Output()
+	if err != nil {
+		return "", 0, err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	state, exit, ok := strings.Cut(line, "|")
+	if !ok {
+		return "", 0, fmt.Errorf("unexpected output: %q", out)
+	}
+	// states may carry a suffix, e.g. "CANCELLED by 1000"
+	state, _, _ = strings.Cut(state, " ")
+	codeStr, sigStr, _ := strings.Cut(exit, ":")
+	code, err := strconv.Atoi(codeStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("bad exit code %q: %w", exit, err)
+	}
+	if sig, _ := strconv.Atoi(sigStr); sig > 0 {
+		code = 128 + sig
+	}
+	if code == 0 && state != "COMPLETED" {
+		code = 1
+	}
+	return state, code, nil
+}
+
+// fileTail copies whatever has been appended to a file since the last poll.
+type fileTail struct {
+	path   string
+	offset int64
+	w      io.Writer
+}
+
+func (t *fileTail) poll() {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return
+	}
+	n, _ := io.Copy(t.w, f)
+	t.offset += n
+}
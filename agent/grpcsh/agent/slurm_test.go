@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeSlurm puts sbatch, squeue and sacct on the PATH that run jobs at
+// once on the local machine. sacct prints $FAKE_SACCT if it is set.
+func fakeSlurm(t *testing.T) string {
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"sbatch": `#!/bin/bash
+for a in "$@"; do
+  case $a in
+    --output=*) o=${a#*=} ;;
+    --error=*) e=${a#*=} ;;
+    --input=*) i=${a#*=} ;;
+    -*) ;;
+    *) s=$a ;;
+  esac
+done
+bash "$s" <"$i" >"$o" 2>"$e"
+echo $? >"$(dirname "$0")/rc"
+echo "42;cluster"
+`,
+		"squeue": "#!/bin/bash\nexit 0\n",
+		"sacct": `#!/bin/bash
+if [ -n "$FAKE_SACCT" ]; then echo "$FAKE_SACCT"; exit 0; fi
+rc=$(cat "$(dirname "$0")/rc")
+if [ "$rc" = 0 ]; then echo "COMPLETED|0:0"; else echo "FAILED|$rc:0"; fi
+`,
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestSlurmBackendRunsJob(t *testing.T) {
+	fakeSlurm(t)
+	spool := t.TempDir()
+	b := &SlurmBackend{SpoolDir: spool, Poll: 10 * time.Millisecond}
+	proc, err := b.Start("cat; echo oops >&2; exit 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(proc.Stdin(), "hello\n")
+	proc.Stdin().Close()
+
+	stderr := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(proc.Stderr())
+		stderr <- string(data)
+	}()
+	stdout, _ := io.ReadAll(proc.Stdout())
+	code, err := proc.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stdout) != "hello\n" {
+		t.Errorf("stdout = %q, want %q", stdout, "hello\n")
+	}
+	if got := <-stderr; got != "oops\n" {
+		t.Errorf("stderr = %q, want %q", got, "oops\n")
+	}
+	if code != 3 {
+		t.Errorf("code = %d, want 3", code)
+	}
+	if left, _ := os.ReadDir(spool); len(left) != 0 {
+		t.Errorf("spool not cleaned up: %d files left", len(left))
+	}
+}
+
+func TestSlurmSignalBeforeSubmit(t *testing.T) {
+	dir := fakeSlurm(t)
+	b := &SlurmBackend{SpoolDir: t.TempDir(), Poll: 10 * time.Millisecond}
+	proc, err := b.Start("echo ran")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proc.Signal(syscall.SIGTERM)
+	proc.Stdin().Close()
+	go io.Copy(io.Discard, proc.Stderr())
+	stdout, _ := io.ReadAll(proc.Stdout())
+	code, _ := proc.Wait()
+	if code != 128+int(syscall.SIGTERM) {
+		t.Errorf("code = %d, want %d", code, 128+int(syscall.SIGTERM))
+	}
+	if len(stdout) != 0 {
+		t.Errorf("stdout = %q, want none", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rc")); err == nil {
+		t.Error("job was submitted")
+	}
+}
+
+func TestSacctResult(t *testing.T) {
+	fakeSlurm(t)
+	for _, tc := range []struct {
+		out   string
+		state string
+		code  int
+	}{
+		{"COMPLETED|0:0", "COMPLETED", 0},
+		{"FAILED|3:0", "FAILED", 3},
+		{"CANCELLED by 1000|0:15", "CANCELLED", 143},
+		{"TIMEOUT|0:0", "TIMEOUT", 1},
+	} {
+		t.Setenv("FAKE_SACCT", tc.out)
+		state, code, err := sacctResult("42")
+		if err != nil {
+			t.Errorf("%q: %v", tc.out, err)
+			continue
+		}
+		if state != tc.state || code != tc.code {
+			t.Errorf("%q: got %s %d, want %s %d", tc.out, state, code, tc.state, tc.code)
+		}
+	}
+	t.Setenv("FAKE_SACCT", "garbage")
+	if _, _, err := sacctResult("42"); err == nil || !strings.Contains(err.Error(), "unexpected output") {
+		t.Errorf("garbage: err = %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SOCKS5 (RFC 1928) constants; only CONNECT without authentication is
+// supported.
+const (
+	socksVersion     = 5
+	socksNoAuth      = 0
+	socksNoMethods   = 0xff
+	socksConnect     = 1
+	socksIPv4        = 1
+	socksDomain      = 3
+	socksIPv6        = 4
+	socksSucceeded   = 0
+	socksFailure     = 1
+	socksNotAllowed  = 2
+	socksUnreachable = 4
+	socksRefused     = 5
+	socksBadCommand  = 7
+	socksBadAddress  = 8
+)
+
+// socksRequest reads the greeting and CONNECT request of a SOCKS5 client
+// and returns the address it asks for. Requests that cannot be served are
+// answered before an error is returned.
+func socksRequest(conn net.Conn) (string, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	if header[0] != socksVersion {
+		return "", fmt.Errorf("not a SOCKS5 client: version %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", err
+	}
+	method := byte(socksNoMethods)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return "", err
+	}
+	if method == socksNoMethods {
+		return "", fmt.Errorf("SOCKS5 client requires authentication")
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", err
+	}
+	if request[1] != socksConnect {
+		socksReply(conn, socksBadCommand)
+		return "", fmt.Errorf("unsupported SOCKS5 command %d", request[1])
+	}
+	var host string
+	switch request[3] {
+	case socksIPv4, socksIPv6:
+		ip := make(net.IP, 4)
+		if request[3] == socksIPv6 {
+			ip = make(net.IP, 16)
+		}
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case socksDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+		host = string(domain)
+	default:
+		socksReply(conn, socksBadAddress)
+		return "", fmt.Errorf("unsupported SOCKS5 address type %d", request[3])
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// socksReply answers a CONNECT request. The bound address is not reported.
+func socksReply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socksVersion, reply, 0, socksIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// socksError picks the reply for a failed dial.
+func socksError(err error) byte {
+	switch status.Code(err) {
+	case codes.PermissionDenied:
+		return socksNotAllowed
+	case codes.Unavailable:
+		if strings.Contains(status.Convert(err).Message(), "refused") {
+			return socksRefused
+		}
+		return socksUnreachable
+	}
+	return socksFailure
+}
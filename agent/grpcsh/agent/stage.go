@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// how often a stage reports progress
+const progressInterval = 500 * time.Millisecond
+
+// Stage uploads a file to another peer. The upload goes through this
+// agent's own socket, which relays it to the peer like any call naming one,
+// so the peer resumes and verifies it as it would a client's.
+func (s *fileServer) Stage(req *pb.StageRequest, stream pb.FileService_StageServer) error {
+	if req.Peer == "" {
+		return status.Error(codes.InvalidArgument, "peer must not be empty")
+	}
+	path, err := resolvePath(stream.Context(), req.Path)
+	if err != nil {
+		return err
+	}
+	header, err := statHeader(path)
+	if err != nil {
+		return fileError(err)
+	}
+	if header.Sha256, err = fileDigest(path); err != nil {
+		return fileError(err)
+	}
+	header.Path = req.Dest
+	if header.Path == "" || strings.HasSuffix(header.Path, "/") {
+		header.Path += filepath.Base(path)
+	}
+	log.Printf("[%s] staging %s to %s:%s (%d bytes)\n", selfId, path, req.Peer, header.Path, header.Size)
+
+	ctx := metadata.AppendToOutgoingContext(stream.Context(), PeerKey, req.Peer)
+	up, err := pb.NewFileServiceClient(localConn).Upload(ctx)
+	if err != nil {
+		return err
+	}
+	if err := up.Send(&pb.FileChunk{Header: header}); err != nil {
+		_, err = up.Recv()
+		return err
+	}
+	resp, err := up.Recv()
+	if err != nil {
+		return err
+	}
+	offset := resp.Offset
+	if err := stream.Send(&pb.StageProgress{Header: header, Offset: offset, Sent: offset, Cached: resp.Cached}); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	buf := make([]byte, bufsize)
+	reported := time.Now()
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := up.Send(&pb.FileChunk{Offset: offset, Data: buf[:n]}); err != nil {
+				break // the peer's error follows on Recv
+			}
+			offset += int64(n)
+			if time.Since(reported) >= progressInterval {
+				if err := stream.Send(&pb.StageProgress{Sent: offset}); err != nil {
+					return err
+				}
+				reported = time.Now()
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fileError(err)
+		}
+	}
+	if err := up.CloseSend(); err != nil {
+		return err
+	}
+	resp, err = up.Recv()
+	if err != nil {
+		log.Printf("[%s] staging %s to %s failed: %s\n", selfId, path, req.Peer, err)
+		return err
+	}
+	log.Printf("[%s] staged %s to %s:%s\n", selfId, path, req.Peer, resp.Header.Path)
+	return stream.Send(&pb.StageProgress{Header: resp.Header, Sent: resp.Offset, Done: true})
+}
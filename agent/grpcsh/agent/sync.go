@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+
+	"grpcsh/filesync"
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type syncServer struct {
+	pb.UnimplementedSyncServiceServer
+}
+
+// syncStart reads the message a sync begins with and resolves its directory.
+func syncStart(stream pb.SyncService_PushServer) (*pb.SyncStart, string, error) {
+	msg, err := stream.Recv()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to receive start: %w", err)
+	}
+	start := msg.GetStart()
+	if start == nil {
+		return nil, "", status.Error(codes.InvalidArgument, "sync must begin with start")
+	}
+	root, err := resolvePath(stream.Context(), start.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	return start, root, nil
+}
+
+func (s *syncServer) Push(stream pb.SyncService_PushServer) error {
+	start, root, err := syncStart(stream)
+	if err != nil {
+		return err
+	}
+	log.Printf("[%s] syncing into %s\n", selfId, root)
+	done, err := filesync.Receive(root, start.Delete, stream)
+	if err != nil {
+		return fileError(err)
+	}
+	log.Printf("[%s] synced into %s: %d files, %d literal bytes, %d matched bytes, %d deleted\n", selfId, root, done.Files, done.LiteralBytes, done.MatchedBytes, done.Deleted)
+	return nil
+}
+
+func (s *syncServer) Pull(stream pb.SyncService_PullServer) error {
+	_, root, err := syncStart(stream)
+	if err != nil {
+		return err
+	}
+	log.Printf("[%s] syncing from %s\n", selfId, root)
+	if _, err := filesync.Send(root, stream); err != nil {
+		return fileError(err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// terminalProcess is a command run under a pseudo-terminal, for
+// TERMINAL channels. Its output all comes as stdout, as on a terminal.
+type terminalProcess struct {
+	cmd *exec.Cmd
+	tty *os.File // the master side
+}
+
+// startTerminal runs a script in a fresh `bash -c` whose stdin, stdout
+// and stderr are a new pseudo-terminal, as the leader of a new session.
+// Terminals are always local, whatever the backend.
+func startTerminal(script string) (*terminalProcess, error) {
+	tty, name, err := openPTY()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a terminal: %w", err)
+	}
+	slave, err := os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("failed to open a terminal: %w", err)
+	}
+	defer slave.Close()
+	cmd := exec.Command("bash", "-c", script)
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := cmd.Start(); err != nil {
+		tty.Close()
+		return nil, err
+	}
+	return &terminalProcess{cmd: cmd, tty: tty}, nil
+}
+
+// terminalInput writes to the terminal; closing it types ^D, which ends
+// the input of a program reading a line, rather than hanging it up.
+type terminalInput struct{ tty *os.File }
+
+func (t terminalInput) Write(p []byte) (int, error) { return t.tty.Write(p) }
+func (t terminalInput) Close() error {
+	_, err := t.tty.Write([]byte{4})
+	return err
+}
+
+// terminalOutput reads from the terminal. Reads fail with EIO once no
+// process has it open anymore, which ends the output.
+type terminalOutput struct{ tty *os.File }
+
+func (t terminalOutput) Read(p []byte) (int, error) {
+	n, err := t.tty.Read(p)
+	if errors.Is(err, syscall.EIO) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (p *terminalProcess) Stdin() io.WriteCloser { return terminalInput{p.tty} }
+func (p *terminalProcess) Stdout() io.Reader     { return terminalOutput{p.tty} }
+func (p *terminalProcess) Stderr() io.Reader     { return strings.NewReader("") }
+
+func (p *terminalProcess) Wait() (int, error) {
+	defer p.tty.Close()
+	return exitCode(p.cmd.Wait())
+}
+
+func (p *terminalProcess) Signal(sig syscall.Signal) error {
+	return syscall.Kill(-p.cmd.Process.Pid, sig)
+}
+
+// resizer is implemented by processes whose terminal can be resized.
+type resizer interface {
+	Resize(cols, rows uint16) error
+}
+
+func (p *terminalProcess) Resize(cols, rows uint16) error {
+	return setWinsize(p.tty, cols, rows)
+}
+
+// resizeProcess handles a RESIZE frame, whose data is "<cols> <rows>".
+func resizeProcess(proc Process, size []byte) {
+	r, ok := proc.(resizer)
+	if !ok {
+		return
+	}
+	var cols, rows uint16
+	if _, err := fmt.Sscanf(string(size), "%d %d", &cols, &rows); err != nil {
+		log.Printf("[%s] invalid terminal size: %q\n", selfId, size)
+		return
+	}
+	if err := r.Resize(cols, rows); err != nil {
+		log.Printf("[%s] failed to resize terminal: %s\n", selfId, err)
+	}
+}
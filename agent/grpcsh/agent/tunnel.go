@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"path"
+	"sync/atomic"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// bytes read from a connection per message
+	tunnelChunk = 32 * 1024
+	dialTimeout = 10 * time.Second
+)
+
+// allowDial are the host:port patterns, as for path.Match, that other
+// peers may have this agent dial; with none, any address is allowed.
+var allowDial []string
+
+// dialAllowed reports whether other peers may have this agent dial an address.
+func dialAllowed(address string) bool {
+	if len(allowDial) == 0 {
+		return true
+	}
+	for _, pattern := range allowDial {
+		if ok, _ := path.Match(pattern, address); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type tunnelServer struct {
+	pb.UnimplementedTunnelServiceServer
+}
+
+// callerOf returns the peer a relayed call came from, or "" for a call
+// made on this agent's socket.
+func callerOf(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if callers := md.Get(CallerKey); len(callers) > 0 {
+		return callers[0]
+	}
+	return ""
+}
+
+func (s *tunnelServer) Dial(stream pb.TunnelService_DialServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if _, _, err := net.SplitHostPort(first.Address); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid address %q: %s", first.Address, err)
+	}
+	caller := callerOf(stream.Context())
+	if caller != "" && !dialAllowed(first.Address) {
+		log.Printf("[%s] refused to connect to %s for %s\n", selfId, first.Address, caller)
+		return status.Errorf(codes.PermissionDenied, "%s may not be dialed through %s", first.Address, selfId)
+	}
+	conn, err := net.DialTimeout("tcp", first.Address, dialTimeout)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer conn.Close()
+	log.Printf("[%s] connected to %s for %s\n", selfId, first.Address, caller)
+	if err := stream.Send(&pb.TunnelData{}); err != nil {
+		return err
+	}
+	sent, received, err := splice(conn, stream)
+	log.Printf("[%s] closed connection to %s for %s (%d bytes out, %d in): %v\n", selfId, first.Address, caller, received, sent, err)
+	return err
+}
+
+// dialPeer connects to an address from a peer, or from this agent if peer
+// is "", through this agent's socket.
+func dialPeer(ctx context.Context, peer string, address string) (pb.TunnelService_DialClient, error) {
+	if peer != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, PeerKey, peer)
+	}
+	stream, err := pb.NewTunnelServiceClient(localConn).Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&pb.TunnelData{Address: address}); err != nil {
+		_, err = stream.Recv()
+		return nil, err
+	}
+	if _, err := stream.Recv(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// tunnelStream is either end of a Dial stream.
+type tunnelStream interface {
+	Send(*pb.TunnelData) error
+	Recv() (*pb.TunnelData, error)
+}
+
+// closeWriter is implemented by connections that can be half-closed.
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// splice relays bytes between a connection and a tunnel stream until both
+// have stopped sending, half-closing each side when the other is done. It
+// returns the bytes sent over the stream and received from it. On errors
+// it closes the connection and returns at once; callers must end the
+// stream then.
+func splice(conn net.Conn, stream tunnelStream) (int64, int64, error) {
+	var sent, received atomic.Int64
+	errs := make(chan error, 2)
+	go func() {
+		buf := make([]byte, tunnelChunk)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if err := stream.Send(&pb.TunnelData{Data: buf[:n]}); err != nil {
+					errs <- err
+					return
+				}
+				sent.Add(int64(n))
+			}
+			if err == io.EOF {
+				errs <- stream.Send(&pb.TunnelData{Eof: true})
+				return
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				// the other side ended the stream without an eof of its own
+				msg, err = &pb.TunnelData{Eof: true}, nil
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+			if len(msg.Data) > 0 {
+				if _, err := conn.Write(msg.Data); err != nil {
+					errs <- err
+					return
+				}
+				received.Add(int64(len(msg.Data)))
+			}
+			if msg.Eof {
+				if cw, ok := conn.(closeWriter); ok {
+					cw.CloseWrite()
+				}
+				errs <- nil
+				return
+			}
+		}
+	}()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			conn.Close()
+			return sent.Load(), received.Load(), err
+		}
+	}
+	return sent.Load(), received.Load(), nil
+}
@@ -0,0 +1,247 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "grpcsh/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// workerScript is run by a warm worker: a shell that has started and
+// waits for its command on the control pipe, fd 3. The command is run as
+// `bash -c` would run it, without the variable it came in.
+const workerScript = `IFS= read -r -d '' GRPCSH_SCRIPT <&3 || exit 0
+exec 3<&-
+unset IFS
+eval "unset GRPCSH_SCRIPT
+$GRPCSH_SCRIPT"`
+
+// samples kept per kind of start for the latency statistics
+const spawnSamples = 1024
+
+// how long a worker's replacement waits for its command to exit before it
+// is spawned anyway
+const refillDelay = 100 * time.Millisecond
+
+// WarmBackend runs commands like ShellBackend, but on shells spawned ahead
+// of time, which take the command through a control pipe, saving the
+// fork and exec of bash when a command arrives. Each worker runs a single
+// command, so every command still gets the agent's environment and working
+// directory, untouched by the ones before it. Commands for which no worker
+// is ready spawn a shell as ShellBackend does.
+type WarmBackend struct {
+	Size int // workers kept ready
+
+	ready     chan *warmWorker
+	slots     chan struct{}
+	warm      spawnStats
+	cold      spawnStats
+	fallbacks int64
+	mu        sync.Mutex
+}
+
+type warmWorker struct {
+	proc    *shellProcess
+	control *os.File
+}
+
+// spawnStats records how long commands of one kind took to start and run.
+type spawnStats struct {
+	commands int64
+	start    []time.Duration
+	run      []time.Duration
+}
+
+// NewWarmBackend starts keeping size workers ready.
+func NewWarmBackend(size int) *WarmBackend {
+	b := &WarmBackend{
+		Size:  size,
+		ready: make(chan *warmWorker, size),
+		slots: make(chan struct{}, size),
+	}
+	if size > 0 {
+		go b.fill()
+	}
+	return b
+}
+
+// fill spawns a worker whenever one of the pool's slots is free. A slot
+// is freed once the command run by its last worker exits, or has run for
+// refillDelay, so that spawning does not compete with short commands for
+// the CPU.
+func (b *WarmBackend) fill() {
+	for {
+		b.slots <- struct{}{}
+		w, err := spawnWorker()
+		if err != nil {
+			log.Printf("[%s] failed to spawn warm worker: %s\n", selfId, err)
+			<-b.slots
+			time.Sleep(time.Second)
+			continue
+		}
+		b.ready <- w
+	}
+}
+
+func spawnWorker() (*warmWorker, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	cmd := exec.Command("bash", "-c", workerScript)
+	cmd.ExtraFiles = []*os.File{r}
+	proc, err := startProcess(cmd)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return &warmWorker{proc: proc, control: w}, nil
+}
+
+// take hands a script to a ready worker, or returns nil if there is none
+// that is still alive.
+func (b *WarmBackend) take(script string) *warmWorker {
+	for {
+		var w *warmWorker
+		select {
+		case w = <-b.ready:
+		default:
+			return nil
+		}
+		_, err := io.WriteString(w.control, script+"\x00")
+		w.control.Close()
+		if err == nil {
+			return w
+		}
+		log.Printf("[%s] discarding warm worker: %s\n", selfId, err)
+		<-b.slots
+		go w.proc.Wait()
+	}
+}
+
+func (b *WarmBackend) Start(script string) (Process, error) {
+	start := time.Now()
+	// the script is passed as a NUL-terminated string, which a script
+	// with NUL bytes cannot be; bash -c rejects those
+	if !strings.Contains(script, "\x00") {
+		if w := b.take(script); w != nil {
+			p := &timedProcess{Process: w.proc, backend: b, warm: true, start: start, started: time.Now(), exited: make(chan struct{})}
+			go func() {
+				select {
+				case <-p.exited:
+				case <-time.After(refillDelay):
+				}
+				<-b.slots
+			}()
+			return p, nil
+		}
+	}
+	proc, err := ShellBackend{}.Start(script)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	if b.Size > 0 {
+		b.fallbacks++
+	}
+	b.mu.Unlock()
+	return &timedProcess{Process: proc, backend: b, start: start, started: time.Now()}, nil
+}
+
+// timedProcess records the latency of a command once it exits.
+type timedProcess struct {
+	Process
+	backend *WarmBackend
+	warm    bool
+	start   time.Time
+	started time.Time
+	exited  chan struct{} // closed when a warm worker's command exits
+}
+
+func (p *timedProcess) Wait() (int, error) {
+	code, err := p.Process.Wait()
+	b := p.backend
+	if p.warm {
+		close(p.exited)
+	}
+	b.mu.Lock()
+	stats := &b.cold
+	if p.warm {
+		stats = &b.warm
+	}
+	stats.record(p.started.Sub(p.start), time.Since(p.start))
+	b.mu.Unlock()
+	return code, err
+}
+
+func (s *spawnStats) record(start time.Duration, run time.Duration) {
+	s.commands++
+	if len(s.start) == spawnSamples {
+		s.start, s.run = s.start[1:], s.run[1:]
+	}
+	s.start = append(s.start, start)
+	s.run = append(s.run, run)
+}
+
+func (s *spawnStats) report() *pb.SpawnStats {
+	return &pb.SpawnStats{
+		Commands:    s.commands,
+		StartMeanMs: mean(s.start),
+		StartP99Ms:  quantile(s.start, 0.99),
+		RunMeanMs:   mean(s.run),
+		RunP50Ms:    quantile(s.run, 0.5),
+		RunP99Ms:    quantile(s.run, 0.99),
+	}
+}
+
+func mean(samples []time.Duration) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range samples {
+		total += d
+	}
+	return ms(total) / float64(len(samples))
+}
+
+func quantile(samples []time.Duration, q float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return ms(sorted[int(q*float64(len(sorted)-1))])
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func (s *executorServer) Stats(ctx context.Context, req *emptypb.Empty) (*pb.ExecStats, error) {
+	b, ok := backend.(*WarmBackend)
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "the agent's backend keeps no statistics")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &pb.ExecStats{
+		PoolSize:  int32(b.Size),
+		Ready:     int32(len(b.ready)),
+		Warm:      b.warm.report(),
+		Cold:      b.cold.report(),
+		Fallbacks: b.fallbacks,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// isWebSocket reports whether a router URL asks for the WebSocket transport.
+func isWebSocket(routerUrl string) bool {
+	return strings.HasPrefix(routerUrl, "ws://") || strings.HasPrefix(routerUrl, "wss://")
+}
+
+// dialRouter connects to the router over gRPC, or for ws:// and wss://
+// URLs over a WebSocket carrying the gRPC connection, for networks that
+// only let HTTP/1.1 out, possibly through a proxy.
+func dialRouter(routerUrl string) (*grpc.ClientConn, error) {
+	if !isWebSocket(routerUrl) {
+		return grpc.NewClient(routerUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	return grpc.NewClient("passthrough:///"+routerUrl,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return dialWebSocket(ctx, routerUrl)
+		}),
+	)
+}
+
+// dialWebSocket opens a WebSocket to the router, through the proxy named
+// by HTTPS_PROXY or HTTP_PROXY, if any, with HTTP CONNECT. A token
+// parameter of the URL is sent as a bearer token instead.
+func dialWebSocket(ctx context.Context, routerUrl string) (net.Conn, error) {
+	u, err := url.Parse(routerUrl)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	query := u.Query()
+	if token := query.Get("token"); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+		query.Del("token")
+		u.RawQuery = query.Encode()
+	}
+	origin := &url.URL{Scheme: "http", Host: u.Host}
+	port := "80"
+	if u.Scheme == "wss" {
+		origin.Scheme, port = "https", "443"
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: origin})
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	if proxy != nil {
+		conn, err = dialProxy(ctx, proxy, address)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	config, err := websocket.NewConfig(u.String(), origin.String())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	config.Header = header
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("WebSocket handshake with %s failed: %w", u.Host, err)
+	}
+	conn.SetDeadline(time.Time{})
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
+
+// dialProxy opens a tunnel to an address through an HTTP proxy.
+func dialProxy(ctx context.Context, proxy *url.URL, address string) (net.Conn, error) {
+	proxyAddress := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddress = net.JoinHostPort(proxy.Hostname(), "80")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// nothing follows the response until the tunnel is used, so the
+	// reader holds no bytes of it
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", proxy.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxy.Host, address, resp.Status)
+	}
+	return conn, nil
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Array submits and inspects job arrays, which run a command template once
+// per combination of parameter values.
+//
+//	grpcsh array submit -s agent.sock -i a,pool:gpu -n 8 -p lr=0.1,0.01 -r seed=1-10 -c "python train.py --lr {lr} --seed {seed}"
+//	grpcsh array status -s agent.sock [-v] array1
+//	grpcsh array output -s agent.sock array1 7
+//	grpcsh array cancel -s agent.sock array1
+func Array(args []string) {
+	commands := map[string]func([]string){
+		"submit": arraySubmit,
+		"status": arrayStatus,
+		"output": arrayOutput,
+		"cancel": arrayCancel,
+	}
+	if len(args) == 0 || commands[args[0]] == nil {
+		log.Fatal("usage: grpcsh array submit|status|output|cancel [flags]")
+	}
+	commands[args[0]](args[1:])
+}
+
+func arraySubmit(args []string) {
+	fs, sockPath := newFlagSet("array submit")
+	peers := fs.String("i", "", "Comma-separated peers or pools to spread the tasks across")
+	command := fs.String("c", "", "The command template; {name} is replaced by the value of a parameter")
+	concurrency := fs.Int("n", 0, "Tasks running at once (default: one per peer)")
+	wait := fs.Bool("w", false, "Wait for the array to finish, exiting non-zero if a task failed")
+	var values, ranges listFlag
+	fs.Var(&values, "p", "name=v1,v2,... parameter values; may be repeated")
+	fs.Var(&ranges, "r", "name=start-end[:step] integer parameter range; may be repeated")
+	fs.Parse(args)
+
+	if *peers == "" {
+		log.Fatal("Peers must be provided using -i")
+	}
+	if *command == "" {
+		log.Fatal("Shell command must be provided using -c")
+	}
+	req := &pb.ArrayRequest{Command: *command, Peers: strings.Split(*peers, ","), Concurrency: int32(*concurrency)}
+	params := map[string]*pb.ArrayParam{}
+	param := func(spec string) (*pb.ArrayParam, string) {
+		name, list, ok := strings.Cut(spec, "=")
+		if !ok {
+			log.Fatalf("Invalid parameter: %q", spec)
+		}
+		if params[name] == nil {
+			params[name] = &pb.ArrayParam{Name: name}
+			req.Params = append(req.Params, params[name])
+		}
+		return params[name], list
+	}
+	for _, spec := range values {
+		p, list := param(spec)
+		p.Values = append(p.Values, strings.Split(list, ",")...)
+	}
+	for _, spec := range ranges {
+		p, list := param(spec)
+		p.Ranges = append(p.Ranges, strings.Split(list, ",")...)
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewArrayServiceClient(conn)
+	status, err := client.Submit(context.Background(), req)
+	if err != nil {
+		log.Fatalf("Error submitting array: %v", err)
+	}
+	fmt.Println(status.Id)
+	if !*wait {
+		return
+	}
+	for status.Pending+status.Running > 0 {
+		time.Sleep(2 * time.Second)
+		if status, err = client.Status(context.Background(), &pb.ArrayId{Id: status.Id}); err != nil {
+			log.Fatalf("Error getting status: %v", err)
+		}
+		printCounts(os.Stderr, status)
+	}
+	if status.Failed+status.Cancelled > 0 {
+		os.Exit(1)
+	}
+}
+
+func arrayStatus(args []string) {
+	fs, sockPath := newFlagSet("array status")
+	verbose := fs.Bool("v", false, "List every task")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh array status [-s sock] [-v] <array>")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	status, err := pb.NewArrayServiceClient(conn).Status(context.Background(), &pb.ArrayId{Id: fs.Arg(0)})
+	if err != nil {
+		log.Fatalf("Error getting status: %v", err)
+	}
+	printCounts(os.Stdout, status)
+	if !*verbose {
+		return
+	}
+	for _, t := range status.Tasks {
+		names := make([]string, 0, len(t.Params))
+		for k := range t.Params {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		params := make([]string, len(names))
+		for i, k := range names {
+			params[i] = k + "=" + t.Params[k]
+		}
+		fmt.Printf("%d\t%s\t%s\t%d\t%s\n", t.Index, t.State, t.Peer, t.ExitCode, strings.Join(params, " "))
+	}
+}
+
+func arrayOutput(args []string) {
+	fs, sockPath := newFlagSet("array output")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		log.Fatal("usage: grpcsh array output [-s sock] <array> <task>")
+	}
+	index, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		log.Fatalf("Invalid task index: %q", fs.Arg(1))
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	output, err := pb.NewArrayServiceClient(conn).Output(context.Background(), &pb.TaskId{Array: fs.Arg(0), Index: int32(index)})
+	if err != nil {
+		log.Fatalf("Error getting output: %v", err)
+	}
+	if output.Truncated {
+		fmt.Fprintf(os.Stderr, "[output truncated]\n")
+	}
+	os.Stdout.Write(output.Stdout)
+	os.Stderr.Write(output.Stderr)
+	os.Exit(int(output.Status.ExitCode))
+}
+
+func arrayCancel(args []string) {
+	fs, sockPath := newFlagSet("array cancel")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh array cancel [-s sock] <array>")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	status, err := pb.NewArrayServiceClient(conn).Cancel(context.Background(), &pb.ArrayId{Id: fs.Arg(0)})
+	if err != nil {
+		log.Fatalf("Error cancelling array: %v", err)
+	}
+	printCounts(os.Stdout, status)
+}
+
+func printCounts(w io.Writer, s *pb.ArrayStatus) {
+	fmt.Fprintf(w, "%s: pending=%d running=%d done=%d failed=%d cancelled=%d\n", s.Id, s.Pending, s.Running, s.Done, s.Failed, s.Cancelled)
+}
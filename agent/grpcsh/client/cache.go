@@ -0,0 +1,60 @@
+package client
+
+import (
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Cache lists or purges the files an agent keeps by digest, from which
+// uploads of the same content are served without sending it again.
+//
+//	grpcsh cache ls -s agent.sock [-i compute-1]
+//	grpcsh cache purge -s agent.sock [-i compute-1] -all | <sha256 prefix>...
+func Cache(args []string) {
+	commands := map[string]func([]string){
+		"ls":    cacheList,
+		"purge": cachePurge,
+	}
+	if len(args) == 0 || commands[args[0]] == nil {
+		log.Fatal("usage: grpcsh cache ls|purge [flags]")
+	}
+	commands[args[0]](args[1:])
+}
+
+func cacheList(args []string) {
+	fs, sockPath := newFlagSet("cache ls")
+	peer := fs.String("i", "", "The peer whose cache to list (default: the local agent's)")
+	fs.Parse(args)
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	list, err := pb.NewCacheServiceClient(conn).List(peerContext(*peer), &emptypb.Empty{})
+	if err != nil {
+		log.Fatalf("Error listing cache: %v", err)
+	}
+	for _, obj := range list.Objects {
+		fmt.Printf("%s\t%d\t%s\n", obj.Sha256, obj.Size, formatUnix(obj.LastUsed))
+	}
+	fmt.Printf("%d objects, %d of %d bytes\n", len(list.Objects), list.Size, list.Limit)
+}
+
+func cachePurge(args []string) {
+	fs, sockPath := newFlagSet("cache purge")
+	peer := fs.String("i", "", "The peer whose cache to purge (default: the local agent's)")
+	all := fs.Bool("all", false, "Purge every object")
+	fs.Parse(args)
+	if !*all && fs.NArg() == 0 {
+		log.Fatal("usage: grpcsh cache purge [-s sock] [-i peer] -all | <sha256 prefix>...")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	resp, err := pb.NewCacheServiceClient(conn).Purge(peerContext(*peer), &pb.PurgeRequest{Sha256: fs.Args(), All: *all})
+	if err != nil {
+		log.Fatalf("Error purging cache: %v", err)
+	}
+	fmt.Printf("%d objects removed, %d bytes freed\n", resp.Removed, resp.Freed)
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"flag"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// size of the data in each chunk of a file transfer
+const chunkSize = 1024 * 1024
+
+// Commands are the grpcsh subcommands, each run with the arguments that
+// follow its name. Running grpcsh without a subcommand executes a command.
+var Commands = map[string]func(args []string){
+	"array":    Array,
+	"cache":    Cache,
+	"cp":       Cp,
+	"follow":   Follow,
+	"forward":  Forward,
+	"fs":       Fs,
+	"gang":     Gang,
+	"job":      Job,
+	"pipe":     Pipe,
+	"schedule": Schedule,
+	"session":  Session,
+	"socks":    Socks,
+	"stats":    Stats,
+	"tunnel":   Tunnel,
+	"sync":     Sync,
+}
+
+// newFlagSet creates the flag set of a subcommand, with the -s flag every
+// subcommand shares.
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("grpcsh "+name, flag.ExitOnError)
+	sockPath := fs.String("s", "agent.sock", "The socket to connect to")
+	return fs, sockPath
+}
+
+// dial connects to the agent listening on a socket.
+func dial(sockPath string) *grpc.ClientConn {
+	if sockPath == "" {
+		log.Fatal("Socket path must be provided using -s")
+	}
+	conn, err := grpc.NewClient("unix://"+sockPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("Failed to connect: %v", err)
+	}
+	return conn
+}
+
+// listFlag collects a flag that may be repeated.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return ""
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,293 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"grpcsh/agent"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Cp copies a file between this machine and a peer, verifying its digest
+// and keeping its mode and modification time. Remote paths are written
+// peer:path; an interrupted copy resumes when run again. A copy between
+// two peers goes from one to the other through the router, not through
+// this machine.
+//
+//	grpcsh cp -s agent.sock input.tar.gz compute-1:/scratch/input.tar.gz
+//	grpcsh cp -s agent.sock compute-1:/scratch/output.h5 .
+//	grpcsh cp -s agent.sock -v compute-1:/scratch/output.h5 viz-1:/data/
+func Cp(args []string) {
+	fs, sockPath := newFlagSet("cp")
+	verbose := fs.Bool("v", false, "Report resumed and cached transfers, and the progress of copies between peers")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		log.Fatal("usage: grpcsh cp [-s sock] [-v] <src> <dst>")
+	}
+	srcPeer, src := splitRemote(fs.Arg(0))
+	dstPeer, dst := splitRemote(fs.Arg(1))
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewFileServiceClient(conn)
+	var err error
+	switch {
+	case srcPeer == "" && dstPeer != "":
+		err = upload(peerContext(dstPeer), client, src, dst, *verbose)
+	case srcPeer != "" && dstPeer == "":
+		err = download(peerContext(srcPeer), client, src, dst)
+	case srcPeer != "" && dstPeer != "":
+		err = stage(peerContext(srcPeer), client, src, dstPeer, dst, *verbose)
+	default:
+		log.Fatal("At least one of <src> and <dst> must be a remote peer:path")
+	}
+	if err != nil {
+		log.Fatalf("Error copying: %v", err)
+	}
+}
+
+// splitRemote splits peer:path; paths without a peer are local. As with
+// scp, a colon after a slash is part of a local path.
+func splitRemote(arg string) (string, string) {
+	i := strings.Index(arg, ":")
+	if i <= 0 || strings.Contains(arg[:i], "/") {
+		return "", arg
+	}
+	return arg[:i], arg[i+1:]
+}
+
+// peerContext addresses calls made with it to a peer.
+func peerContext(peer string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), agent.PeerKey, peer)
+}
+
+func digestFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func upload(ctx context.Context, client pb.FileServiceClient, src string, dst string, verbose bool) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file: %s", src)
+	}
+	if dst == "" || strings.HasSuffix(dst, "/") {
+		dst += filepath.Base(src)
+	}
+	digest, err := digestFile(src)
+	if err != nil {
+		return err
+	}
+	header := &pb.FileHeader{Path: dst, Size: info.Size(), Mode: uint32(info.Mode().Perm()), Mtime: info.ModTime().UnixNano(), Sha256: digest}
+
+	stream, err := client.Upload(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.FileChunk{Header: header}); err != nil {
+		return err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	offset := resp.Offset
+	if verbose && resp.Cached {
+		fmt.Fprintf(os.Stderr, "the peer already has the content\n")
+	} else if verbose && offset > 0 {
+		fmt.Fprintf(os.Stderr, "resuming at %d\n", offset)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.FileChunk{Offset: offset, Data: buf[:n]}); err != nil {
+				break // the agent's error follows on Recv
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func download(ctx context.Context, client pb.FileServiceClient, src string, dst string) error {
+	if info, err := os.Stat(dst); (err == nil && info.IsDir()) || strings.HasSuffix(dst, "/") {
+		dst = filepath.Join(dst, filepath.Base(src))
+	}
+
+	// resume from the data an interrupted download left behind
+	req := &pb.DownloadRequest{Path: src}
+	pattern := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".*.part")
+	parts, _ := filepath.Glob(pattern)
+	for _, part := range parts {
+		if info, err := os.Stat(part); err == nil {
+			prefix := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(part), "."+filepath.Base(dst)+"."), ".part")
+			req.Sha256, req.Offset = prefix, info.Size()
+			break
+		}
+	}
+
+	stream, err := client.Download(ctx, req)
+	if err != nil {
+		return err
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	header := first.Header
+	offset := first.Offset
+	part := partFile(dst, header.Sha256)
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	for _, p := range parts {
+		if p != part || offset == 0 {
+			os.Remove(p)
+		}
+	}
+	f, err := os.OpenFile(part, flags, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if chunk.Offset != offset {
+			return fmt.Errorf("chunk at %d, expected %d", chunk.Offset, offset)
+		}
+		n, err := f.Write(chunk.Data)
+		offset += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if offset != header.Size {
+		return fmt.Errorf("received %d of %d bytes", offset, header.Size)
+	}
+	digest, err := digestFile(part)
+	if err != nil {
+		return err
+	}
+	if digest != header.Sha256 {
+		os.Remove(part)
+		return fmt.Errorf("digest mismatch: got %s, expected %s", digest, header.Sha256)
+	}
+	if err := os.Chmod(part, os.FileMode(header.Mode)&os.ModePerm); err != nil {
+		return err
+	}
+	mtime := time.Unix(0, header.Mtime)
+	if err := os.Chtimes(part, mtime, mtime); err != nil {
+		return err
+	}
+	return os.Rename(part, dst)
+}
+
+// stage has the peer holding src send it to dstPeer.
+func stage(ctx context.Context, client pb.FileServiceClient, src string, dstPeer string, dst string, verbose bool) error {
+	stream, err := client.Stage(ctx, &pb.StageRequest{Path: src, Peer: dstPeer, Dest: dst})
+	if err != nil {
+		return err
+	}
+	var size int64
+	start := time.Now()
+	for {
+		progress, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("transfer ended before %s:%s was verified", dstPeer, dst)
+		}
+		if err != nil {
+			if verbose {
+				fmt.Fprintln(os.Stderr)
+			}
+			return err
+		}
+		if progress.Header != nil && !progress.Done {
+			size = progress.Header.Size
+		}
+		if verbose && progress.Cached {
+			fmt.Fprintf(os.Stderr, "%s already has the content\n", dstPeer)
+		} else if verbose && progress.Offset > 0 {
+			fmt.Fprintf(os.Stderr, "resuming at %d\n", progress.Offset)
+		}
+		if verbose {
+			percent := 100.0
+			if size > 0 {
+				percent = float64(progress.Sent) * 100 / float64(size)
+			}
+			fmt.Fprintf(os.Stderr, "\r%d/%d bytes (%.0f%%)", progress.Sent, size, percent)
+		}
+		if progress.Done {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "\n%s:%s verified (sha256 %s) in %s\n", dstPeer, progress.Header.Path, progress.Header.Sha256, time.Since(start).Round(time.Millisecond))
+			}
+			return nil
+		}
+	}
+}
+
+// partFile is where a download is written until its digest has been verified.
+func partFile(path string, digest string) string {
+	if len(digest) > 16 {
+		digest = digest[:16]
+	}
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+"."+digest+".part")
+}
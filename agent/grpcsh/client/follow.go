@@ -0,0 +1,93 @@
+package client
+
+import (
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Follow prints what is appended to files on a peer, like tail -F. Files
+// that are rotated or truncated are read again from their start, and when
+// the connection is lost it is made again, resuming where it stopped.
+//
+//	grpcsh follow -s agent.sock [-n 10] compute-1:/scratch/run/sim.log compute-1:/scratch/run/err.log
+//	grpcsh follow -s agent.sock -o 0 compute-1:/scratch/run/sim.log
+func Follow(args []string) {
+	fs, sockPath := newFlagSet("follow")
+	lines := fs.Int("n", 10, "Start with this many lines before the end of each file")
+	offset := fs.Int64("o", -1, "Start at this byte offset of each file instead")
+	quiet := fs.Bool("q", false, "Never print headers naming the files")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("usage: grpcsh follow [-s sock] [-n lines] [-o offset] [-q] <peer:path>...")
+	}
+	req := &pb.FollowRequest{}
+	var peer string
+	for i, arg := range fs.Args() {
+		p, path := splitRemote(arg)
+		if i > 0 && p != peer {
+			log.Fatal("All files must be on the same peer")
+		}
+		peer = p
+		req.Files = append(req.Files, &pb.FollowFile{Path: path, Offset: *offset, Lines: int32(*lines)})
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewFileServiceClient(conn)
+	headers := len(req.Files) > 1 && !*quiet
+	current := -1
+	backoff := time.Second
+	for {
+		stream, err := client.Follow(peerContext(peer), req)
+		for err == nil {
+			var event *pb.FollowEvent
+			if event, err = stream.Recv(); err != nil {
+				break
+			}
+			backoff = time.Second
+			i := int(event.File)
+			if i < 0 || i >= len(req.Files) {
+				continue
+			}
+			file := req.Files[i]
+			// resume from here after a reconnect
+			file.Offset = event.Offset + int64(len(event.Data))
+			switch event.Kind {
+			case pb.FollowEvent_DATA:
+				if headers && current != i {
+					if current != -1 {
+						fmt.Println()
+					}
+					fmt.Printf("==> %s <==\n", fs.Arg(i))
+				}
+				current = i
+				os.Stdout.Write(event.Data)
+			case pb.FollowEvent_ROTATED:
+				fmt.Fprintf(os.Stderr, "grpcsh: %s has been replaced; following new file\n", fs.Arg(i))
+			case pb.FollowEvent_TRUNCATED:
+				fmt.Fprintf(os.Stderr, "grpcsh: %s: file truncated\n", fs.Arg(i))
+			case pb.FollowEvent_MISSING:
+				fmt.Fprintf(os.Stderr, "grpcsh: %s: no such file; waiting for it to appear\n", fs.Arg(i))
+			}
+		}
+		switch status.Code(err) {
+		case codes.InvalidArgument, codes.NotFound, codes.PermissionDenied, codes.Unimplemented:
+			log.Fatalf("Error following: %v", err)
+		}
+		if err == io.EOF {
+			err = fmt.Errorf("the agent ended the stream")
+		}
+		fmt.Fprintf(os.Stderr, "grpcsh: %v; reconnecting in %s\n", err, backoff)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+// Forward has the local agent listen on ports and tunnel each connection
+// through the router to a peer, which connects it to a host and port it
+// can reach, until grpcsh is interrupted. With -R, the peer listens and
+// the local agent connects, to targets it allows with -allow-dial. With -G,
+// the local agent serves gRPC instead, and the peer makes each call on the
+// gRPC server at the target, streaming, metadata and deadline included.
+//
+//	grpcsh forward -s agent.sock -i compute-1 -L 8888:localhost:8888 [-L 0.0.0.0:8265:localhost:8265] [-v]
+//	grpcsh forward -s agent.sock -i compute-1 -R 27000:license.example.com:27000
+//	grpcsh forward -s agent.sock -i compute-1 -G 50061:localhost:50051
+func Forward(args []string) {
+	fs, sockPath := newFlagSet("forward")
+	peer := fs.String("i", "", "The peer that connects to the targets")
+	verbose := fs.Bool("v", false, "Report connections and gRPC calls")
+	var local, remote, calls listFlag
+	fs.Var(&local, "L", "[bind:]port:host:hostport to listen on here and connect to from the peer; may be repeated")
+	fs.Var(&remote, "R", "[bind:]port:host:hostport to listen on at the peer and connect to from here; may be repeated")
+	fs.Var(&calls, "G", "[bind:]port:host:hostport to serve gRPC on here, making the calls on the server at host:hostport from the peer; may be repeated")
+	fs.Parse(args)
+	if *peer == "" || len(local)+len(remote)+len(calls) == 0 {
+		log.Fatal("usage: grpcsh forward [-s sock] -i peer [-L [bind:]port:host:hostport]... [-R [bind:]port:host:hostport]... [-G [bind:]port:host:hostport]... [-v]")
+	}
+	var reqs []*pb.ForwardRequest
+	for i, spec := range append(append(local, remote...), calls...) {
+		listen, target, err := parseForward(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reqs = append(reqs, &pb.ForwardRequest{
+			Listen:  listen,
+			Peer:    *peer,
+			Target:  target,
+			Reverse: i >= len(local) && i < len(local)+len(remote),
+			Grpc:    i >= len(local)+len(remote),
+		})
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewTunnelServiceClient(conn)
+	errs := make(chan error)
+	for _, req := range reqs {
+		go func(req *pb.ForwardRequest) {
+			stream, err := client.Forward(context.Background(), req)
+			if err == nil {
+				from, to := "local", req.Peer
+				if req.Reverse {
+					from, to = req.Peer, "local"
+				}
+				announce := func(listen string) string {
+					return fmt.Sprintf("Forwarding %s to %s:%s", listen, to, req.Target)
+				}
+				if req.Grpc {
+					announce = func(listen string) string {
+						return fmt.Sprintf("Serving gRPC on %s for %s:%s", listen, to, req.Target)
+					}
+				}
+				err = forward(stream, from, announce, *verbose)
+			}
+			errs <- err
+		}(req)
+	}
+	log.Fatalf("Error forwarding: %v", <-errs)
+}
+
+// forward reports the events of a forward until it ends, announcing where
+// it listens with the line announce returns for the address.
+func forward(stream pb.TunnelService_ForwardClient, from string, announce func(string) string, verbose bool) error {
+	var listen string
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch event.Kind {
+		case pb.ForwardEvent_LISTENING:
+			listen = from + ":" + event.Address
+			fmt.Fprintln(os.Stderr, announce(listen))
+		case pb.ForwardEvent_OPENED:
+			if verbose {
+				if strings.HasPrefix(event.Address, "/") {
+					fmt.Fprintf(os.Stderr, "%s #%d: call to %s\n", listen, event.Connection, event.Address)
+				} else {
+					fmt.Fprintf(os.Stderr, "%s #%d: connection from %s\n", listen, event.Connection, event.Address)
+				}
+			}
+		case pb.ForwardEvent_CLOSED:
+			if event.Error != "" {
+				fmt.Fprintf(os.Stderr, "%s #%d: %s\n", listen, event.Connection, event.Error)
+			} else if verbose {
+				fmt.Fprintf(os.Stderr, "%s #%d: closed connection to %s (%d bytes sent, %d received)\n", listen, event.Connection, event.Address, event.Sent, event.Received)
+			}
+		}
+	}
+}
+
+// parseForward splits [bind:]port:host:hostport into the address to listen
+// on, which is on localhost unless bind is given, and the target. IPv6
+// addresses are written in brackets.
+func parseForward(spec string) (string, string, error) {
+	var fields []string
+	depth, start := 0, 0
+	for i, c := range spec {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				fields = append(fields, spec[start:i])
+				start = i + 1
+			}
+		}
+	}
+	fields = append(fields, spec[start:])
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, "[]")
+	}
+	switch len(fields) {
+	case 3:
+		return net.JoinHostPort("localhost", fields[0]), net.JoinHostPort(fields[1], fields[2]), nil
+	case 4:
+		return net.JoinHostPort(fields[0], fields[1]), net.JoinHostPort(fields[2], fields[3]), nil
+	}
+	return "", "", fmt.Errorf("invalid forward %q: expected [bind:]port:host:hostport", spec)
+}
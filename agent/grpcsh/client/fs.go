@@ -0,0 +1,188 @@
+package client
+
+import (
+	"fmt"
+	pb "grpcsh/pb"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+)
+
+// Fs inspects and changes files on a peer. Paths are written peer:path;
+// paths without a peer are on the agent grpcsh talks to. Agents started
+// with -roots refuse paths outside their roots.
+//
+//	grpcsh fs stat -s agent.sock compute-1:/scratch/run/out.h5
+//	grpcsh fs ls -s agent.sock [-l] compute-1:/scratch/run
+//	grpcsh fs read -s agent.sock [-o 1048576] [-n 4096] compute-1:/scratch/run/log.txt
+//	grpcsh fs mkdir -s agent.sock [-p] compute-1:/scratch/run/plots
+//	grpcsh fs rm -s agent.sock [-r] compute-1:/scratch/run/tmp
+//	grpcsh fs mv -s agent.sock compute-1:/scratch/run/out.h5 /scratch/keep/out.h5
+func Fs(args []string) {
+	commands := map[string]func([]string){
+		"stat":  fsStat,
+		"ls":    fsList,
+		"read":  fsRead,
+		"mkdir": fsMkdir,
+		"rm":    fsRemove,
+		"mv":    fsRename,
+	}
+	if len(args) == 0 || commands[args[0]] == nil {
+		log.Fatal("usage: grpcsh fs stat|ls|read|mkdir|rm|mv [flags] <peer:path>")
+	}
+	commands[args[0]](args[1:])
+}
+
+func formatInfo(info *pb.FileInfo, name string) string {
+	line := fmt.Sprintf("%s %10d %s %s", fs.FileMode(info.Mode), info.Size, time.Unix(0, info.Mtime).Format("2006-01-02 15:04"), name)
+	if info.Link != "" {
+		line += " -> " + info.Link
+	}
+	return line
+}
+
+func fsStat(args []string) {
+	fs, sockPath := newFlagSet("fs stat")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh fs stat [-s sock] <peer:path>")
+	}
+	peer, path := splitRemote(fs.Arg(0))
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	info, err := pb.NewFileServiceClient(conn).Stat(peerContext(peer), &pb.PathRequest{Path: path})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	fmt.Println(formatInfo(info, info.Path))
+}
+
+func fsList(args []string) {
+	fs, sockPath := newFlagSet("fs ls")
+	long := fs.Bool("l", false, "Show mode, size and modification time")
+	pageSize := fs.Int("page", 0, "Entries fetched per call (default: the agent's)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh fs ls [-s sock] [-l] <peer:path>")
+	}
+	peer, path := splitRemote(fs.Arg(0))
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewFileServiceClient(conn)
+	req := &pb.ListDirRequest{Path: path, PageSize: int32(*pageSize)}
+	for {
+		resp, err := client.ListDir(peerContext(peer), req)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		for _, entry := range resp.Entries {
+			name := entry.Name
+			if entry.IsDir {
+				name += "/"
+			}
+			if *long {
+				name = formatInfo(entry, name)
+			}
+			fmt.Println(name)
+		}
+		if resp.NextPageToken == "" {
+			return
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+func fsRead(args []string) {
+	fs, sockPath := newFlagSet("fs read")
+	offset := fs.Int64("o", 0, "The offset to start reading at")
+	length := fs.Int64("n", -1, "The number of bytes to read (default: to the end)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh fs read [-s sock] [-o offset] [-n length] <peer:path>")
+	}
+	peer, path := splitRemote(fs.Arg(0))
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewFileServiceClient(conn)
+	left := *length
+	req := &pb.ReadRangeRequest{Path: path, Offset: *offset}
+	for left != 0 {
+		req.Length = chunkSize
+		if left > 0 && left < chunkSize {
+			req.Length = left
+		}
+		resp, err := client.ReadRange(peerContext(peer), req)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if _, err := os.Stdout.Write(resp.Data); err != nil {
+			log.Fatalf("Error writing output: %v", err)
+		}
+		req.Offset += int64(len(resp.Data))
+		if left > 0 {
+			left -= int64(len(resp.Data))
+		}
+		if resp.Eof || len(resp.Data) == 0 {
+			return
+		}
+	}
+}
+
+func fsMkdir(args []string) {
+	fs, sockPath := newFlagSet("fs mkdir")
+	parents := fs.Bool("p", false, "Create missing parents, and do not fail if the directory exists")
+	mode := fs.Uint("m", 0755, "The permissions of the directory")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh fs mkdir [-s sock] [-p] [-m mode] <peer:path>")
+	}
+	peer, path := splitRemote(fs.Arg(0))
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	_, err := pb.NewFileServiceClient(conn).Mkdir(peerContext(peer), &pb.MkdirRequest{Path: path, Parents: *parents, Mode: uint32(*mode)})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+func fsRemove(args []string) {
+	fs, sockPath := newFlagSet("fs rm")
+	recursive := fs.Bool("r", false, "Remove directories and their contents")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh fs rm [-s sock] [-r] <peer:path>")
+	}
+	peer, path := splitRemote(fs.Arg(0))
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	_, err := pb.NewFileServiceClient(conn).Remove(peerContext(peer), &pb.RemoveRequest{Path: path, Recursive: *recursive})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+func fsRename(args []string) {
+	fs, sockPath := newFlagSet("fs mv")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		log.Fatal("usage: grpcsh fs mv [-s sock] <peer:from> <to>")
+	}
+	peer, from := splitRemote(fs.Arg(0))
+	toPeer, to := splitRemote(fs.Arg(1))
+	if toPeer != "" && toPeer != peer {
+		log.Fatal("Cannot move files between peers; use grpcsh cp")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	_, err := pb.NewFileServiceClient(conn).Rename(peerContext(peer), &pb.RenameRequest{From: from, To: to})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
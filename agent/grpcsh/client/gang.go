@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Gang runs a command on several peers as one job, one rank per peer.
+//
+//	grpcsh gang -s agent.sock -i a,b,c [-e KEY=VALUE]... [-rdzv host] [-port 29500] -c "python train.py"
+func Gang(args []string) {
+	fs, sockPath := newFlagSet("gang")
+	peers := fs.String("i", "", "Comma-separated peers or pools, one per rank")
+	command := fs.String("c", "", "The command each rank executes")
+	rendezvous := fs.String("rdzv", "", "The MASTER_ADDR handed to ranks (default: host of rank 0)")
+	port := fs.Int("port", 29500, "The MASTER_PORT handed to ranks")
+	startTimeout := fs.Int("timeout", 60, "Seconds to wait for all ranks to start")
+	var env listFlag
+	fs.Var(&env, "e", "KEY=VALUE set for every rank; may be repeated")
+	fs.Parse(args)
+
+	if *peers == "" {
+		log.Fatal("Peers must be provided using -i")
+	}
+	if *command == "" {
+		log.Fatal("Shell command must be provided using -c")
+	}
+	req := &pb.GangRequest{
+		Peers:        strings.Split(*peers, ","),
+		Command:      *command,
+		Env:          map[string]string{},
+		Rendezvous:   *rendezvous,
+		Port:         int32(*port),
+		StartTimeout: int32(*startTimeout),
+	}
+	for _, kv := range env {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			log.Fatalf("Invalid environment variable: %q", kv)
+		}
+		req.Env[k] = v
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	stream, err := pb.NewGangServiceClient(conn).Launch(context.Background(), req)
+	if err != nil {
+		log.Fatalf("Error launching gang: %v", err)
+	}
+
+	// exit with the code of the first rank that failed
+	code := 0
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error receiving from stream: %v", err)
+		}
+		switch event.Flag {
+		case pb.Flag_MSG_STDOUT:
+			os.Stdout.Write(event.Data)
+		case pb.Flag_MSG_STDERR:
+			os.Stderr.Write(event.Data)
+		case pb.Flag_EXIT:
+			rc, _ := strconv.Atoi(string(event.Data))
+			fmt.Fprintf(os.Stderr, "[rank %d on %s] exited with code %d\n", event.Rank, event.Peer, rc)
+			if code == 0 {
+				code = rc
+			}
+		}
+	}
+	os.Exit(code)
+}
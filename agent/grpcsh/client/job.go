@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Job runs commands on an agent detached from the client, which can go
+// away while they run. The agent spools their output, which any client can
+// read later by the job's ID, from an offset, or follow while it is
+// written; ^C stops following and leaves the job running.
+//
+//	grpcsh job submit -s agent.sock [-i compute-1] [-f] "make -j8 all"
+//	grpcsh job output -s agent.sock [-i compute-1] [-f] [-o 4096] [-e 0] 3f9a0c1b2d4e
+//	grpcsh job status -s agent.sock [-i compute-1] 3f9a0c1b2d4e
+//	grpcsh job ls -s agent.sock [-i compute-1]
+//	grpcsh job cancel -s agent.sock [-i compute-1] [-signal INT] 3f9a0c1b2d4e
+//	grpcsh job rm -s agent.sock [-i compute-1] 3f9a0c1b2d4e
+func Job(args []string) {
+	commands := map[string]func([]string){
+		"submit": jobSubmit,
+		"output": jobOutput,
+		"status": jobStatus,
+		"ls":     jobList,
+		"cancel": jobCancel,
+		"rm":     jobRemove,
+	}
+	if len(args) == 0 || commands[args[0]] == nil {
+		log.Fatal("usage: grpcsh job submit|output|status|ls|cancel|rm [flags]")
+	}
+	commands[args[0]](args[1:])
+}
+
+// printJobOutput copies the output of a job and returns its exit code, or
+// -1 if it was still running.
+func printJobOutput(ctx context.Context, client pb.JobServiceClient, req *pb.OutputRequest) int {
+	stream, err := client.Output(ctx, req)
+	if err != nil {
+		log.Fatalf("Error getting output: %v", err)
+	}
+	for {
+		out, err := stream.Recv()
+		if err == io.EOF && !req.Follow {
+			return -1
+		}
+		if err != nil {
+			log.Fatalf("Error receiving from stream: %v", err)
+		}
+		switch out.Flag {
+		case pb.Flag_MSG_STDOUT:
+			os.Stdout.Write(out.Data)
+		case pb.Flag_MSG_STDERR:
+			os.Stderr.Write(out.Data)
+		case pb.Flag_EXIT:
+			code, err := strconv.Atoi(string(out.Data))
+			if err != nil || code < 0 {
+				code = 255
+			}
+			return code
+		}
+	}
+}
+
+func formatJob(j *pb.Job) string {
+	code := "-"
+	if j.State != pb.Job_RUNNING {
+		code = strconv.Itoa(int(j.ExitCode))
+	}
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%d/%d bytes\t%s", j.Id, strings.ToLower(j.State.String()), code,
+		formatUnix(j.Started), formatUnix(j.Ended), j.StdoutSize, j.StderrSize, j.Command)
+}
+
+func jobSubmit(args []string) {
+	fs, sockPath := newFlagSet("job submit")
+	peer := fs.String("i", "", "The peer to run the job on (default: the local agent)")
+	follow := fs.Bool("f", false, "Follow the output of the job once it is submitted")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("usage: grpcsh job submit [-s sock] [-i peer] [-f] <command>...")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewJobServiceClient(conn)
+	j, err := client.Submit(peerContext(*peer), &pb.JobRequest{Command: strings.Join(fs.Args(), " ")})
+	if err != nil {
+		log.Fatalf("Error submitting job: %v", err)
+	}
+	if !*follow {
+		fmt.Println(j.Id)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "job %s\n", j.Id)
+	os.Exit(printJobOutput(peerContext(*peer), client, &pb.OutputRequest{Id: j.Id, Follow: true}))
+}
+
+func jobOutput(args []string) {
+	fs, sockPath := newFlagSet("job output")
+	peer := fs.String("i", "", "The peer running the job (default: the local agent)")
+	follow := fs.Bool("f", false, "Keep printing output as it is written, until the job ends")
+	stdoutOffset := fs.Int64("o", 0, "The offset in the job's stdout to start from")
+	stderrOffset := fs.Int64("e", 0, "The offset in the job's stderr to start from")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh job output [-s sock] [-i peer] [-f] [-o offset] [-e offset] <id>")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	req := &pb.OutputRequest{Id: fs.Arg(0), StdoutOffset: *stdoutOffset, StderrOffset: *stderrOffset, Follow: *follow}
+	code := printJobOutput(peerContext(*peer), pb.NewJobServiceClient(conn), req)
+	if code >= 0 {
+		os.Exit(code)
+	}
+}
+
+func jobStatus(args []string) {
+	fs, sockPath := newFlagSet("job status")
+	peer := fs.String("i", "", "The peer running the job (default: the local agent)")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("usage: grpcsh job status [-s sock] [-i peer] <id>...")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewJobServiceClient(conn)
+	for _, id := range fs.Args() {
+		j, err := client.Status(peerContext(*peer), &pb.JobId{Id: id})
+		if err != nil {
+			log.Fatalf("Error getting status of job %s: %v", id, err)
+		}
+		fmt.Println(formatJob(j))
+	}
+}
+
+func jobList(args []string) {
+	fs, sockPath := newFlagSet("job ls")
+	peer := fs.String("i", "", "The peer whose jobs to list (default: the local agent's)")
+	fs.Parse(args)
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	list, err := pb.NewJobServiceClient(conn).List(peerContext(*peer), &emptypb.Empty{})
+	if err != nil {
+		log.Fatalf("Error listing jobs: %v", err)
+	}
+	for _, j := range list.Jobs {
+		fmt.Println(formatJob(j))
+	}
+}
+
+func jobCancel(args []string) {
+	fs, sockPath := newFlagSet("job cancel")
+	peer := fs.String("i", "", "The peer running the job (default: the local agent)")
+	signal := fs.String("signal", "TERM", "The signal to send, e.g. INT or KILL")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("usage: grpcsh job cancel [-s sock] [-i peer] [-signal name] <id>...")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewJobServiceClient(conn)
+	for _, id := range fs.Args() {
+		if _, err := client.Cancel(peerContext(*peer), &pb.CancelRequest{Id: id, Signal: strings.TrimPrefix(*signal, "SIG")}); err != nil {
+			log.Fatalf("Error cancelling job %s: %v", id, err)
+		}
+	}
+}
+
+func jobRemove(args []string) {
+	fs, sockPath := newFlagSet("job rm")
+	peer := fs.String("i", "", "The peer that ran the job (default: the local agent)")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("usage: grpcsh job rm [-s sock] [-i peer] <id>...")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewJobServiceClient(conn)
+	for _, id := range fs.Args() {
+		if _, err := client.Remove(peerContext(*peer), &pb.JobId{Id: id}); err != nil {
+			log.Fatalf("Error removing job %s: %v", id, err)
+		}
+	}
+}
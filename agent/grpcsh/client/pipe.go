@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Pipe runs a pipeline whose stages are on different peers, given by pairs
+// of -i and -c in order. The stdout of each stage goes straight to the
+// next; grpcsh only sends stdin to the first stage and gets the output of
+// the last, and exits with its code, or with -pipefail with the code of the
+// last stage that failed, as bash does.
+//
+//	grpcsh pipe -s agent.sock -i a -c "tar c data" -i b -c "tar x -C /scratch" [-pipefail] [-v]
+func Pipe(args []string) {
+	fs, sockPath := newFlagSet("pipe")
+	var peers, commands listFlag
+	fs.Var(&peers, "i", "The peer or pool of the next stage; may be repeated")
+	fs.Var(&commands, "c", "The command of the next stage; may be repeated")
+	pipefail := fs.Bool("pipefail", false, "Exit with the code of the last stage that failed")
+	verbose := fs.Bool("v", false, "Report the exit code of every stage")
+	fs.Parse(args)
+	if len(peers) == 0 || len(peers) != len(commands) {
+		log.Fatal("usage: grpcsh pipe [-s sock] -i peer -c command [-i peer -c command]... [-pipefail] [-v]")
+	}
+	req := &pb.PipelineRequest{}
+	for i := range peers {
+		req.Stages = append(req.Stages, &pb.PipelineStage{Peer: peers[i], Command: commands[i]})
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	stream, err := pb.NewPipelineServiceClient(conn).Run(context.Background())
+	if err != nil {
+		log.Fatalf("Error creating stream: %v", err)
+	}
+	if err := stream.Send(&pb.PipelineInput{Request: req}); err != nil {
+		log.Fatalf("Error sending pipeline: %v", err)
+	}
+
+	// stdin goes to the first stage, unless it is a terminal
+	go func() {
+		defer stream.CloseSend()
+		if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice != 0 {
+			return
+		}
+		buf := make([]byte, chunkSize)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if n > 0 {
+				if err := stream.Send(&pb.PipelineInput{Flag: pb.Flag_MSG_STDIN, Data: buf[:n]}); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					log.Printf("Error reading stdin: %v", err)
+				}
+				return
+			}
+		}
+	}()
+
+	codes := make([]int, len(peers))
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Error receiving from stream: %v", err)
+		}
+		switch event.Flag {
+		case pb.Flag_MSG_STDOUT:
+			os.Stdout.Write(event.Data)
+		case pb.Flag_MSG_STDERR:
+			os.Stderr.Write(event.Data)
+		case pb.Flag_EXIT:
+			rc, err := strconv.Atoi(string(event.Data))
+			if err != nil {
+				rc = 255
+			}
+			codes[event.Stage] = rc
+			if *verbose || rc != 0 {
+				fmt.Fprintf(os.Stderr, "[stage %d on %s] exited with code %d\n", event.Stage, event.Peer, rc)
+			}
+		}
+	}
+
+	code := codes[len(codes)-1]
+	if *pipefail {
+		for _, rc := range codes {
+			if rc != 0 {
+				code = rc
+			}
+		}
+	}
+	os.Exit(code)
+}
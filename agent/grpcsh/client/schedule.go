@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Schedule manages commands the router runs on cron schedules.
+//
+//	grpcsh schedule add -s agent.sock -n cleanup -cron "0 2 * * *" -i pool:scratch [-catchup] [-overlap] -c "rm -rf /scratch/tmp/*"
+//	grpcsh schedule list -s agent.sock
+//	grpcsh schedule history -s agent.sock [-o] cleanup
+//	grpcsh schedule rm -s agent.sock cleanup
+func Schedule(args []string) {
+	commands := map[string]func([]string){
+		"add":     scheduleAdd,
+		"list":    scheduleList,
+		"history": scheduleHistory,
+		"rm":      scheduleRemove,
+	}
+	if len(args) == 0 || commands[args[0]] == nil {
+		log.Fatal("usage: grpcsh schedule add|list|history|rm [flags]")
+	}
+	commands[args[0]](args[1:])
+}
+
+func scheduleAdd(args []string) {
+	fs, sockPath := newFlagSet("schedule add")
+	name := fs.String("n", "", "The name of the schedule")
+	cron := fs.String("cron", "", `When to run, e.g. "0 2 * * *" or "@hourly" (router's local time)`)
+	peerId := fs.String("i", "", "The peer or pool to run the command on")
+	command := fs.String("c", "", "The command to execute")
+	catchup := fs.Bool("catchup", false, "Run once on router startup if runs were missed while it was down")
+	overlap := fs.Bool("overlap", false, "Start a run even if the previous one is still going")
+	fs.Parse(args)
+
+	schedule := &pb.Schedule{Name: *name, Cron: *cron, To: *peerId, Command: *command, AllowOverlap: *overlap}
+	if *catchup {
+		schedule.Missed = pb.MissedRunPolicy_RUN_MISSED_ONCE
+	}
+	conn := dial(*sockPath)
+	defer conn.Close()
+	created, err := pb.NewScheduleServiceClient(conn).Create(context.Background(), schedule)
+	if err != nil {
+		log.Fatalf("Error creating schedule: %v", err)
+	}
+	fmt.Printf("%s: next run at %s\n", created.Name, formatUnix(created.NextRun))
+}
+
+func scheduleList(args []string) {
+	fs, sockPath := newFlagSet("schedule list")
+	fs.Parse(args)
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	list, err := pb.NewScheduleServiceClient(conn).List(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		log.Fatalf("Error listing schedules: %v", err)
+	}
+	for _, s := range list.Schedules {
+		state := "idle"
+		if s.Running {
+			state = "running"
+		}
+		fmt.Printf("%s\t%s\t%s\tnext=%s\t%s\t%s\n", s.Name, s.Cron, s.To, formatUnix(s.NextRun), state, s.Command)
+	}
+}
+
+func scheduleHistory(args []string) {
+	fs, sockPath := newFlagSet("schedule history")
+	showOutput := fs.Bool("o", false, "Show the output of each run")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh schedule history [-s sock] [-o] <name>")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	history, err := pb.NewScheduleServiceClient(conn).History(context.Background(), &pb.ScheduleName{Name: fs.Arg(0)})
+	if err != nil {
+		log.Fatalf("Error getting history: %v", err)
+	}
+	for _, run := range history.Runs {
+		fmt.Printf("%s\t%s\t%s\tcode=%d\tstarted=%s\tfinished=%s\n", formatUnix(run.Scheduled), run.State, run.Peer, run.ExitCode, formatUnix(run.Started), formatUnix(run.Finished))
+		if *showOutput && len(run.Output) > 0 {
+			os.Stdout.Write(run.Output)
+		}
+	}
+}
+
+func scheduleRemove(args []string) {
+	fs, sockPath := newFlagSet("schedule rm")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh schedule rm [-s sock] <name>")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	if _, err := pb.NewScheduleServiceClient(conn).Delete(context.Background(), &pb.ScheduleName{Name: fs.Arg(0)}); err != nil {
+		log.Fatalf("Error deleting schedule: %v", err)
+	}
+}
+
+func formatUnix(sec int64) string {
+	if sec == 0 {
+		return "-"
+	}
+	return time.Unix(sec, 0).Format(time.RFC3339)
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Session runs commands in a named shell that an agent keeps between
+// commands, so that cd, exported variables and activated environments
+// carry over. Attach runs the lines of stdin one after another, as a shell
+// would; ^C interrupts the running command, and ^D detaches, leaving the
+// session for later.
+//
+//	grpcsh session run -s agent.sock [-i compute-1] build "cd ~/src && source .venv/bin/activate"
+//	grpcsh session attach -s agent.sock [-i compute-1] build
+//	grpcsh session ls -s agent.sock [-i compute-1]
+//	grpcsh session close -s agent.sock [-i compute-1] build
+func Session(args []string) {
+	commands := map[string]func([]string){
+		"run":    sessionRun,
+		"attach": sessionAttach,
+		"ls":     sessionList,
+		"close":  sessionClose,
+	}
+	if len(args) == 0 || commands[args[0]] == nil {
+		log.Fatal("usage: grpcsh session run|attach|ls|close [flags]")
+	}
+	commands[args[0]](args[1:])
+}
+
+// runInSession copies the output of a command run in a session and returns
+// its exit code and the working directory it left, or closed if the shell
+// exited.
+func runInSession(ctx context.Context, client pb.SessionServiceClient, name string, command string) (code int, cwd string, closed bool) {
+	stream, err := client.Run(ctx, &pb.SessionCommand{Session: name, Command: command})
+	if err != nil {
+		log.Fatalf("Error running command: %v", err)
+	}
+	for {
+		out, err := stream.Recv()
+		if status.Code(err) == codes.Canceled {
+			// interrupted; the session stays
+			fmt.Fprintln(os.Stderr)
+			return 130, "", false
+		}
+		if err != nil {
+			log.Fatalf("Error receiving from stream: %v", err)
+		}
+		switch out.Flag {
+		case pb.Flag_MSG_STDOUT:
+			os.Stdout.Write(out.Data)
+		case pb.Flag_MSG_STDERR:
+			os.Stderr.Write(out.Data)
+		case pb.Flag_EXIT:
+			code, err := strconv.Atoi(string(out.Data))
+			if err != nil {
+				code = 255
+			}
+			if out.Closed {
+				fmt.Fprintf(os.Stderr, "session %s ended\n", name)
+			}
+			return code, out.Cwd, out.Closed
+		}
+	}
+}
+
+func sessionRun(args []string) {
+	fs, sockPath := newFlagSet("session run")
+	peer := fs.String("i", "", "The peer that keeps the session (default: the local agent)")
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		log.Fatal("usage: grpcsh session run [-s sock] [-i peer] <name> <command>...")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	code, _, _ := runInSession(peerContext(*peer), pb.NewSessionServiceClient(conn), fs.Arg(0), strings.Join(fs.Args()[1:], " "))
+	os.Exit(code)
+}
+
+func sessionAttach(args []string) {
+	fs, sockPath := newFlagSet("session attach")
+	peer := fs.String("i", "", "The peer that keeps the session (default: the local agent)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh session attach [-s sock] [-i peer] <name>")
+	}
+	name := fs.Arg(0)
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewSessionServiceClient(conn)
+	interactive := false
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		interactive = true
+	}
+	cwd := ""
+	if list, err := client.List(peerContext(*peer), &emptypb.Empty{}); err == nil {
+		for _, s := range list.Sessions {
+			if s.Name == name {
+				cwd = s.Cwd
+			}
+		}
+	}
+
+	// ^C cancels the command running, and is ignored at the prompt
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	code := 0
+	lines := bufio.NewScanner(os.Stdin)
+	lines.Buffer(make([]byte, 64*1024), chunkSize)
+	for {
+		if interactive {
+			fmt.Fprintf(os.Stderr, "%s:%s$ ", name, cwd)
+		}
+		if !lines.Scan() {
+			break
+		}
+		if strings.TrimSpace(lines.Text()) == "" {
+			continue
+		}
+		ctx, cancel := context.WithCancel(peerContext(*peer))
+		go func() {
+			select {
+			case <-interrupts:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		var closed bool
+		var dir string
+		code, dir, closed = runInSession(ctx, client, name, lines.Text())
+		cancel()
+		if closed {
+			os.Exit(code)
+		}
+		if dir != "" {
+			cwd = dir
+		}
+	}
+	if interactive {
+		fmt.Fprintln(os.Stderr)
+	}
+	os.Exit(code)
+}
+
+func sessionList(args []string) {
+	fs, sockPath := newFlagSet("session ls")
+	peer := fs.String("i", "", "The peer whose sessions to list (default: the local agent's)")
+	fs.Parse(args)
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	list, err := pb.NewSessionServiceClient(conn).List(peerContext(*peer), &emptypb.Empty{})
+	if err != nil {
+		log.Fatalf("Error listing sessions: %v", err)
+	}
+	for _, s := range list.Sessions {
+		state := "idle"
+		if s.Busy {
+			state = "busy"
+		}
+		fmt.Printf("%s\t%s\t%d commands\t%s\t%s\n", s.Name, state, s.Commands, formatUnix(s.LastUsed), s.Cwd)
+	}
+}
+
+func sessionClose(args []string) {
+	fs, sockPath := newFlagSet("session close")
+	peer := fs.String("i", "", "The peer that keeps the session (default: the local agent)")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("usage: grpcsh session close [-s sock] [-i peer] <name>...")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewSessionServiceClient(conn)
+	for _, name := range fs.Args() {
+		if _, err := client.Close(peerContext(*peer), &pb.SessionName{Name: name}); err != nil {
+			log.Fatalf("Error closing session %s: %v", name, err)
+		}
+	}
+}
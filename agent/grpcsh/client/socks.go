@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"net"
+	"strconv"
+)
+
+// Socks has the local agent serve SOCKS5 and connect each client's
+// CONNECT request from a peer, through the router, until grpcsh is
+// interrupted.
+//
+//	grpcsh socks -s agent.sock -i compute-1 [-p 1080] [-b localhost] [-v]
+//	curl --socks5-hostname localhost:1080 http://dashboard.internal:8265/
+func Socks(args []string) {
+	fs, sockPath := newFlagSet("socks")
+	peer := fs.String("i", "", "The peer that connects to the requested addresses")
+	port := fs.Int("p", 1080, "The port to listen on")
+	bind := fs.String("b", "localhost", "The address to listen on")
+	verbose := fs.Bool("v", false, "Report connections")
+	fs.Parse(args)
+	if *peer == "" {
+		log.Fatal("usage: grpcsh socks [-s sock] -i peer [-p port] [-b bind] [-v]")
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	req := &pb.ForwardRequest{Listen: net.JoinHostPort(*bind, strconv.Itoa(*port)), Peer: *peer, Socks: true}
+	stream, err := pb.NewTunnelServiceClient(conn).Forward(context.Background(), req)
+	if err == nil {
+		err = forward(stream, "local", func(listen string) string {
+			return fmt.Sprintf("Serving SOCKS5 on %s, connecting from %s", listen, *peer)
+		}, *verbose)
+	}
+	log.Fatalf("Error serving SOCKS5: %v", err)
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Stats compares how long commands took when run on an agent's warm
+// workers and when they spawned a shell.
+//
+//	grpcsh stats -s agent.sock [-i compute-1]
+func Stats(args []string) {
+	fs, sockPath := newFlagSet("stats")
+	peer := fs.String("i", "", "The peer whose statistics to show (default: the local agent's)")
+	fs.Parse(args)
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	stats, err := pb.NewExecutorServiceClient(conn).Stats(peerContext(*peer), &emptypb.Empty{})
+	if err != nil {
+		log.Fatalf("Error getting statistics: %v", err)
+	}
+	fmt.Printf("%d of %d warm workers ready, %d cold fallbacks\n", stats.Ready, stats.PoolSize, stats.Fallbacks)
+	fmt.Printf("%-6s %10s %12s %12s %12s %12s %12s\n", "", "commands", "start mean", "start p99", "run mean", "run p50", "run p99")
+	for _, row := range []struct {
+		name  string
+		stats *pb.SpawnStats
+	}{{"warm", stats.Warm}, {"cold", stats.Cold}} {
+		s := row.stats
+		fmt.Printf("%-6s %10d %10.3fms %10.3fms %10.3fms %10.3fms %10.3fms\n", row.name, s.Commands, s.StartMeanMs, s.StartP99Ms, s.RunMeanMs, s.RunP50Ms, s.RunP99Ms)
+	}
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"fmt"
+	"grpcsh/filesync"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"os"
+)
+
+// Sync brings a directory on a peer up to date with a local one, or the
+// other way round. Only the files that differ are sent, as deltas against
+// the copy the other side already has.
+//
+//	grpcsh sync -s agent.sock [-delete] ./deck compute-1:/scratch/deck
+//	grpcsh sync -s agent.sock compute-1:/scratch/results ./results
+func Sync(args []string) {
+	fs, sockPath := newFlagSet("sync")
+	del := fs.Bool("delete", false, "Delete files the source does not have")
+	verbose := fs.Bool("v", false, "Print what was transferred")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		log.Fatal("usage: grpcsh sync [-s sock] [-delete] <src> <dst>")
+	}
+	srcPeer, src := splitRemote(fs.Arg(0))
+	dstPeer, dst := splitRemote(fs.Arg(1))
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	client := pb.NewSyncServiceClient(conn)
+	var done *pb.SyncDone
+	var err error
+	switch {
+	case srcPeer == "" && dstPeer != "":
+		done, err = push(client, dstPeer, src, dst, *del)
+	case srcPeer != "" && dstPeer == "":
+		done, err = pull(client, srcPeer, src, dst, *del)
+	default:
+		log.Fatal("Exactly one of <src> and <dst> must be a remote peer:path")
+	}
+	if err != nil {
+		log.Fatalf("Error syncing: %v", err)
+	}
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "%d files transferred (%d bytes sent, %d bytes matched), %d deleted\n", done.Files, done.LiteralBytes, done.MatchedBytes, done.Deleted)
+	}
+}
+
+func push(client pb.SyncServiceClient, peer string, src string, dst string, del bool) (*pb.SyncDone, error) {
+	stream, err := client.Push(peerContext(peer))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Start{Start: &pb.SyncStart{Path: dst, Delete: del}}}); err != nil {
+		return nil, err
+	}
+	done, err := filesync.Send(src, stream)
+	if err == io.EOF {
+		// the agent gave up; its error comes with the end of the stream
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		return nil, err
+	}
+	return done, nil
+}
+
+func pull(client pb.SyncServiceClient, peer string, src string, dst string, del bool) (*pb.SyncDone, error) {
+	stream, err := client.Pull(peerContext(peer))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Start{Start: &pb.SyncStart{Path: src}}}); err != nil {
+		return nil, err
+	}
+	done, err := filesync.Receive(dst, del, stream)
+	if err == io.EOF {
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		return nil, err
+	}
+	return done, nil
+}
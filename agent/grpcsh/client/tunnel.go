@@ -0,0 +1,74 @@
+package client
+
+import (
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"net"
+	"os"
+)
+
+// Tunnel connects its stdin and stdout to a TCP connection a peer makes,
+// through the router, so that ssh reaches hosts behind the peer with it
+// as a ProxyCommand:
+//
+//	grpcsh tunnel -s agent.sock -i compute-1 localhost:22
+//	ssh -o ProxyCommand='grpcsh tunnel -s ~/agent.sock -i %h localhost:%p' ubuntu@compute-1
+func Tunnel(args []string) {
+	fs, sockPath := newFlagSet("tunnel")
+	peer := fs.String("i", "", "The peer that connects to the address")
+	fs.Parse(args)
+	if *peer == "" || fs.NArg() != 1 {
+		log.Fatal("usage: grpcsh tunnel [-s sock] -i peer host:port")
+	}
+	address := fs.Arg(0)
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		log.Fatalf("Invalid address %q: %v", address, err)
+	}
+
+	conn := dial(*sockPath)
+	defer conn.Close()
+	stream, err := pb.NewTunnelServiceClient(conn).Dial(peerContext(*peer))
+	if err == nil {
+		err = stream.Send(&pb.TunnelData{Address: address})
+	}
+	if err == nil {
+		// the peer answers with an empty message once connected
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		log.Fatalf("Error connecting to %s from %s: %v", address, *peer, err)
+	}
+
+	go func() {
+		buf := make([]byte, chunkSize)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if n > 0 {
+				if stream.Send(&pb.TunnelData{Data: buf[:n]}) != nil {
+					return
+				}
+			}
+			if err != nil {
+				// a failed read of stdin ends it as well
+				stream.Send(&pb.TunnelData{Eof: true})
+				return
+			}
+		}
+	}()
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatalf("Error tunnelling to %s from %s: %v", address, *peer, err)
+		}
+		if _, err := os.Stdout.Write(msg.Data); err != nil {
+			return
+		}
+		if msg.Eof {
+			return
+		}
+	}
+}
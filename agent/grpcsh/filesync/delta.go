@@ -0,0 +1,196 @@
+package filesync
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"io"
+	"math"
+	"os"
+
+	pb "grpcsh/pb"
+)
+
+const (
+	minBlockSize = 2 * 1024
+	maxBlockSize = 128 * 1024
+	// literal bytes sent per data message
+	literalChunk = 256 * 1024
+)
+
+// blockSize picks a block size for a file, growing with the square root of
+// its size as rsync does.
+func blockSize(size int64) int {
+	bs := int(math.Sqrt(float64(size))) &^ 7
+	if bs < minBlockSize {
+		return minBlockSize
+	}
+	if bs > maxBlockSize {
+		return maxBlockSize
+	}
+	return bs
+}
+
+// rolling is the rsync weak checksum of a window, which can be moved along
+// by one byte at a time.
+type rolling struct {
+	a, b uint32
+	n    uint32
+}
+
+func newRolling(window []byte) rolling {
+	r := rolling{n: uint32(len(window))}
+	for i, c := range window {
+		r.a += uint32(c)
+		r.b += uint32(len(window)-i) * uint32(c)
+	}
+	return r
+}
+
+func (r *rolling) roll(out, in byte) {
+	r.a += uint32(in) - uint32(out)
+	r.b += r.a - r.n*uint32(out)
+}
+
+func (r *rolling) rollOut(out byte) {
+	r.a -= uint32(out)
+	r.b -= r.n * uint32(out)
+	r.n--
+}
+
+func (r *rolling) sum() uint32 {
+	return r.a&0xffff | r.b<<16
+}
+
+// signature computes the block sums of a file.
+func signature(path string, bs int) ([]*pb.BlockSum, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var blocks []*pb.BlockSum
+	buf := make([]byte, bs)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			weak := newRolling(buf[:n])
+			strong := md5.Sum(buf[:n])
+			blocks = append(blocks, &pb.BlockSum{Weak: weak.sum(), Strong: strong[:]})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// delta compares a file against the block sums of the receiver's copy and
+// emits data messages: ranges of the receiver's copy where blocks match,
+// and literal data everywhere else.
+func delta(r io.Reader, bs int, blocks []*pb.BlockSum, emit func(*pb.SyncData) error) error {
+	index := make(map[uint32][]int, len(blocks))
+	for i, b := range blocks {
+		index[b.Weak] = append(index[b.Weak], i)
+	}
+	blockLen := func(i int) int {
+		if i == len(blocks)-1 {
+			// only the last block may be short; its length follows from the size
+			// of the receiver's copy, which is not known here, so it is checked
+			// by the strong sum instead
+			return -1
+		}
+		return bs
+	}
+
+	br := bufio.NewReaderSize(r, maxBlockSize*4)
+	// pending holds literal bytes not yet sent, followed by the window
+	pending := make([]byte, 0, literalChunk+bs)
+	fill := func() (int, error) {
+		start := len(pending)
+		pending = pending[:start+bs]
+		n, err := io.ReadFull(br, pending[start:])
+		pending = pending[:start+n]
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return n, err
+	}
+	flush := func(n int) error {
+		if n == 0 {
+			return nil
+		}
+		err := emit(&pb.SyncData{Literal: append([]byte(nil), pending[:n]...)})
+		pending = append(pending[:0], pending[n:]...)
+		return err
+	}
+	match := func(sum uint32, window []byte) int {
+		candidates := index[sum]
+		if len(candidates) == 0 {
+			return -1
+		}
+		strong := md5.Sum(window)
+		for _, i := range candidates {
+			if l := blockLen(i); l != -1 && l != len(window) {
+				continue
+			}
+			if bytes.Equal(blocks[i].Strong, strong[:]) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	w, err := fill()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	eof := err == io.EOF
+	sum := newRolling(pending[len(pending)-w:])
+	for w > 0 {
+		window := pending[len(pending)-w:]
+		if i := match(sum.sum(), window); i >= 0 {
+			if err := flush(len(pending) - w); err != nil {
+				return err
+			}
+			if err := emit(&pb.SyncData{CopyOffset: int64(i) * int64(bs), CopyLength: int64(w)}); err != nil {
+				return err
+			}
+			pending = pending[:0]
+			if eof {
+				return nil
+			}
+			w, err = fill()
+			if err != nil && err != io.EOF {
+				return err
+			}
+			eof = err == io.EOF
+			sum = newRolling(pending[len(pending)-w:])
+			continue
+		}
+		if eof {
+			// shrink the window, as the tail may still match the short last block
+			sum.rollOut(window[0])
+			w--
+			continue
+		}
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			eof = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		sum.roll(window[0], c)
+		pending = append(pending, c)
+		if len(pending)-w >= literalChunk {
+			if err := flush(len(pending) - w); err != nil {
+				return err
+			}
+		}
+	}
+	return flush(len(pending))
+}
@@ -0,0 +1,104 @@
+package filesync
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "grpcsh/pb"
+)
+
+// apply rebuilds a file from the receiver's copy and the data messages of
+// a delta, as Receive does.
+func apply(t *testing.T, old []byte, msgs []*pb.SyncData) []byte {
+	var out []byte
+	for _, m := range msgs {
+		if m.CopyLength > 0 {
+			if m.CopyOffset+m.CopyLength > int64(len(old)) {
+				t.Fatalf("copy of %d bytes at %d beyond the %d bytes of the old copy", m.CopyLength, m.CopyOffset, len(old))
+			}
+			out = append(out, old[m.CopyOffset:m.CopyOffset+m.CopyLength]...)
+		}
+		out = append(out, m.Literal...)
+	}
+	return out
+}
+
+func TestDelta(t *testing.T) {
+	const bs = minBlockSize
+	random := func(n int, seed int64) []byte {
+		b := make([]byte, n)
+		rand.New(rand.NewSource(seed)).Read(b)
+		return b
+	}
+	old := random(10*bs+123, 1)
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	tests := []struct {
+		name    string
+		new     []byte
+		literal int // most literal bytes expected
+	}{
+		{"unchanged", old, 0},
+		{"empty", nil, 0},
+		// the short last block only matches at the end of the file
+		{"appended", join(old, random(500, 2)), 123 + 500},
+		{"prepended", join(random(7, 3), old), 7},
+		{"edited", join(old[:3*bs], []byte("edit"), old[3*bs+4:]), bs},
+		{"truncated", old[:4*bs+10], bs},
+		{"new", random(3*bs, 4), 3 * bs},
+		{"large literal", join(random(2*literalChunk, 5), old), 2 * literalChunk},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "old")
+		if err := os.WriteFile(path, old, 0600); err != nil {
+			t.Fatal(err)
+		}
+		blocks, err := signature(path, bs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msgs []*pb.SyncData
+		err = delta(bytes.NewReader(test.new), bs, blocks, func(m *pb.SyncData) error {
+			if len(m.Literal) > literalChunk {
+				t.Errorf("%s: %d literal bytes in one message", test.name, len(m.Literal))
+			}
+			msgs = append(msgs, m)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if got := apply(t, old, msgs); !bytes.Equal(got, test.new) {
+			t.Errorf("%s: rebuilt %d bytes differ from the %d bytes sent", test.name, len(got), len(test.new))
+		}
+		literal := 0
+		for _, m := range msgs {
+			literal += len(m.Literal)
+		}
+		if literal > test.literal {
+			t.Errorf("%s: %d literal bytes, want at most %d", test.name, literal, test.literal)
+		}
+	}
+}
+
+func TestBlockSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want int
+	}{
+		{0, minBlockSize},
+		{1 << 20, minBlockSize},
+		{1 << 30, 32768},
+		{1 << 40, maxBlockSize},
+	}
+	for _, test := range tests {
+		if got := blockSize(test.size); got != test.want {
+			t.Errorf("blockSize(%d) = %d, want %d", test.size, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+// Package filesync brings a directory up to date with a copy of it on the
+// other end of a stream. The sender lists its entries, the receiver asks
+// for the files that differ from its own, with block signatures of its copy
+// where it has one, and the sender answers with literal data for new files
+// and rsync-style deltas for changed ones.
+package filesync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	pb "grpcsh/pb"
+)
+
+// block sums sent per request
+const requestBlocks = 32 * 1024
+
+// Conn is either end of a sync stream.
+type Conn interface {
+	Send(*pb.SyncMessage) error
+	Recv() (*pb.SyncMessage, error)
+}
+
+// Send streams the directory at root to a receiver, which has already been
+// told where to put it, and returns the receiver's account of the sync.
+func Send(root string, conn Conn) (*pb.SyncDone, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", root)
+	}
+
+	// list the entries
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		entry := &pb.SyncEntry{Path: filepath.ToSlash(rel), Mode: uint32(info.Mode()), Mtime: info.ModTime().UnixNano()}
+		switch {
+		case info.Mode().IsRegular():
+			entry.Size = info.Size()
+		case info.Mode()&fs.ModeSymlink != 0:
+			if entry.Link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case info.IsDir():
+		default:
+			// sockets, devices and the like are not synced
+			return nil
+		}
+		return conn.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Entry{Entry: entry}})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Done{Done: &pb.SyncDone{}}}); err != nil {
+		return nil, err
+	}
+
+	// collect the requests, whose block sums may be split over several
+	var requests []*pb.SyncRequest
+	for {
+		msg, err := conn.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if msg.GetDone() != nil {
+			break
+		}
+		req := msg.GetRequest()
+		if req == nil {
+			return nil, fmt.Errorf("expected request, got %T", msg.Msg)
+		}
+		if n := len(requests); n > 0 && requests[n-1].More && requests[n-1].Path == req.Path {
+			requests[n-1].Blocks = append(requests[n-1].Blocks, req.Blocks...)
+			requests[n-1].More = req.More
+			continue
+		}
+		requests = append(requests, req)
+	}
+
+	// answer them
+	for _, req := range requests {
+		if err := sendFile(root, req, conn); err != nil {
+			return nil, err
+		}
+	}
+	if err := conn.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Done{Done: &pb.SyncDone{}}}); err != nil {
+		return nil, err
+	}
+
+	// wait for the receiver to apply the data
+	msg, err := conn.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if msg.GetDone() == nil {
+		return nil, fmt.Errorf("expected summary, got %T", msg.Msg)
+	}
+	return msg.GetDone(), nil
+}
+
+func sendFile(root string, req *pb.SyncRequest, conn Conn) error {
+	path, err := localPath(root, req.Path)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	emit := func(data *pb.SyncData) error {
+		data.Path = req.Path
+		return conn.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Data{Data: data}})
+	}
+	r := io.TeeReader(f, h)
+	if len(req.Blocks) == 0 {
+		buf := make([]byte, literalChunk)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				if err := emit(&pb.SyncData{Literal: append([]byte(nil), buf[:n]...)}); err != nil {
+					return err
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+	} else if err := delta(r, int(req.BlockSize), req.Blocks, emit); err != nil {
+		return err
+	}
+	return emit(&pb.SyncData{End: true, Sha256: hex.EncodeToString(h.Sum(nil))})
+}
+
+// localPath joins a path received from the other end to root, refusing
+// paths that would leave it, also by way of a symlink among its parents.
+func localPath(root string, rel string) (string, error) {
+	local := filepath.FromSlash(rel)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("invalid path: %q", rel)
+	}
+	dir := root
+	for _, name := range strings.Split(filepath.Dir(local), string(filepath.Separator)) {
+		if name == "." {
+			continue
+		}
+		dir = filepath.Join(dir, name)
+		if info, err := os.Lstat(dir); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("path under a symlink: %q", rel)
+		}
+	}
+	return filepath.Join(root, local), nil
+}
+
+// Receive brings the directory at root up to date with what a sender
+// streams, optionally deleting what the sender does not have, and reports
+// what was transferred to the sender as well as to the caller.
+func Receive(root string, del bool, conn Conn) (*pb.SyncDone, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	// compare the entries to what is here
+	entries := map[string]*pb.SyncEntry{}
+	var dirs []*pb.SyncEntry
+	var files []*pb.SyncEntry
+	for {
+		msg, err := conn.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if msg.GetDone() != nil {
+			break
+		}
+		entry := msg.GetEntry()
+		if entry == nil {
+			return nil, fmt.Errorf("expected entry, got %T", msg.Msg)
+		}
+		path, err := localPath(root, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		entries[entry.Path] = entry
+		mode := fs.FileMode(entry.Mode)
+		switch {
+		case mode.IsDir():
+			if err := makeDir(path, mode); err != nil {
+				return nil, err
+			}
+			dirs = append(dirs, entry)
+		case mode&fs.ModeSymlink != 0:
+			if err := makeLink(path, entry.Link); err != nil {
+				return nil, err
+			}
+		case mode.IsRegular():
+			files = append(files, entry)
+		}
+	}
+
+	// ask for the files that differ
+	var wanted []*pb.SyncEntry
+	for _, entry := range files {
+		path, err := localPath(root, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Lstat(path)
+		if err == nil && info.Mode().IsRegular() && info.Size() == entry.Size && info.ModTime().UnixNano() == entry.Mtime {
+			if info.Mode().Perm() != fs.FileMode(entry.Mode).Perm() {
+				os.Chmod(path, fs.FileMode(entry.Mode).Perm())
+			}
+			continue
+		}
+		req := &pb.SyncRequest{Path: entry.Path}
+		if err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+			req.BlockSize = int32(blockSize(info.Size()))
+			if req.Blocks, err = signature(path, int(req.BlockSize)); err != nil {
+				return nil, err
+			}
+		} else if err == nil {
+			// a directory or link in the way of the file
+			if err := os.RemoveAll(path); err != nil {
+				return nil, err
+			}
+		}
+		blocks := req.Blocks
+		for {
+			part := &pb.SyncRequest{Path: req.Path, BlockSize: req.BlockSize, Blocks: blocks}
+			if len(blocks) > requestBlocks {
+				part.Blocks, part.More = blocks[:requestBlocks], true
+			}
+			if err := conn.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Request{Request: part}}); err != nil {
+				return nil, err
+			}
+			blocks = blocks[len(part.Blocks):]
+			if !part.More {
+				break
+			}
+		}
+		wanted = append(wanted, entry)
+	}
+	if err := conn.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Done{Done: &pb.SyncDone{}}}); err != nil {
+		return nil, err
+	}
+
+	// apply the data, one file after another
+	done := &pb.SyncDone{}
+	for _, entry := range wanted {
+		if err := receiveFile(root, entry, conn, done); err != nil {
+			return nil, err
+		}
+		done.Files++
+	}
+	msg, err := conn.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if msg.GetDone() == nil {
+		return nil, fmt.Errorf("expected end of data, got %T", msg.Msg)
+	}
+
+	if del {
+		if done.Deleted, err = deleteExtraneous(root, entries); err != nil {
+			return nil, err
+		}
+	}
+	// directories last, as their mtime changes with their contents; deepest first
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Path > dirs[j].Path })
+	for _, entry := range dirs {
+		path, err := localPath(root, entry.Path)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Lstat(path); err == nil && info.IsDir() {
+			mtime := time.Unix(0, entry.Mtime)
+			os.Chtimes(path, mtime, mtime)
+		}
+	}
+	if err := conn.Send(&pb.SyncMessage{Msg: &pb.SyncMessage_Done{Done: done}}); err != nil {
+		return nil, err
+	}
+	return done, nil
+}
+
+func makeDir(path string, mode fs.FileMode) error {
+	info, err := os.Lstat(path)
+	if err == nil && !info.IsDir() {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode.Perm())
+}
+
+func makeLink(path string, target string) error {
+	if current, err := os.Readlink(path); err == nil && current == target {
+		return nil
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	return os.Symlink(target, path)
+}
+
+func receiveFile(root string, entry *pb.SyncEntry, conn Conn, done *pb.SyncDone) error {
+	path, err := localPath(root, entry.Path)
+	if err != nil {
+		return err
+	}
+	basis, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err == nil {
+		defer basis.Close()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.sync")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	h := sha256.New()
+	w := io.MultiWriter(tmp, h)
+
+	for {
+		msg, err := conn.Recv()
+		if err != nil {
+			return err
+		}
+		data := msg.GetData()
+		if data == nil || data.Path != entry.Path {
+			return fmt.Errorf("expected data for %s, got %v", entry.Path, msg)
+		}
+		if data.End {
+			if got := hex.EncodeToString(h.Sum(nil)); got != data.Sha256 {
+				return fmt.Errorf("digest mismatch for %s: got %s, expected %s", entry.Path, got, data.Sha256)
+			}
+			break
+		}
+		if len(data.Literal) > 0 {
+			if _, err := w.Write(data.Literal); err != nil {
+				return err
+			}
+			done.LiteralBytes += int64(len(data.Literal))
+		}
+		if data.CopyLength > 0 {
+			if basis == nil {
+				return fmt.Errorf("no copy of %s to take blocks from", entry.Path)
+			}
+			if _, err := io.Copy(w, io.NewSectionReader(basis, data.CopyOffset, data.CopyLength)); err != nil {
+				return err
+			}
+			done.MatchedBytes += data.CopyLength
+		}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), fs.FileMode(entry.Mode).Perm()); err != nil {
+		return err
+	}
+	mtime := time.Unix(0, entry.Mtime)
+	if err := os.Chtimes(tmp.Name(), mtime, mtime); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// deleteExtraneous removes what is under root but was not listed by the sender.
+func deleteExtraneous(root string, entries map[string]*pb.SyncEntry) (int32, error) {
+	var deleted int32
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if _, exists := entries[filepath.ToSlash(rel)]; exists {
+			return nil
+		}
+		if strings.HasSuffix(path, ".sync") && strings.HasPrefix(d.Name(), ".") {
+			// a transfer still in progress elsewhere
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		deleted++
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"grpcsh/client"
 	pb "grpcsh/pb"
 	"io"
 	"log"
 	"os"
+	"strconv"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -15,6 +17,14 @@ import (
 
 func main() {
 
+	// subcommands
+	if len(os.Args) > 1 {
+		if run, exists := client.Commands[os.Args[1]]; exists {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	// argument parsing
 	peerId := flag.String("i", "local", "The peer to run the command on")
 	sockPath := flag.String("s", "agent.sock", "The socket to connect to")
@@ -59,18 +69,16 @@ func main() {
 			errChan <- fmt.Errorf("Error sending command: %v", err)
 			return
 		}
-		// send stdin if present
+		// send stdin if present; a terminal is not read, but still closed,
+		// so that commands reading stdin, or waiting for it to close, as
+		// SLURM jobs do before they are submitted, do not hang
 		stat, err := os.Stdin.Stat()
 		if err != nil {
 			errChan <- fmt.Errorf("error stating stdin: %v", err)
 			return
 		}
-		if (stat.Mode() & os.ModeCharDevice) != 0 {
-			errChan <- nil
-			return
-		}
 		buf := make([]byte, 1024)
-		for {
+		for (stat.Mode() & os.ModeCharDevice) == 0 {
 			n, err := os.Stdin.Read(buf)
 			if err != nil {
 				if err != io.EOF {
@@ -91,15 +99,12 @@ func main() {
 		os.Stdin.Close()
 	}()
 
+	// Exit code of the remote command
+	exitChan := make(chan int, 1)
+
 	// inbound stream
 	go func() {
-		eof_stdout := false
-		eof_stderr := false
 		for {
-			if eof_stdout && eof_stderr {
-				errChan <- nil
-				return
-			}
 			result, err := stream.Recv()
 			if err != nil {
 				errChan <- fmt.Errorf("error receiving from stream: %v", err)
@@ -118,21 +123,31 @@ func main() {
 					return
 				}
 			case pb.Flag_EOF_STDERR:
-				eof_stderr = true
 				os.Stderr.Close()
 			case pb.Flag_EOF_STDOUT:
-				eof_stdout = true
 				os.Stdout.Close()
+			case pb.Flag_EXIT:
+				code, err := strconv.Atoi(string(result.Data))
+				if err != nil {
+					errChan <- fmt.Errorf("invalid exit code: %q", result.Data)
+					return
+				}
+				exitChan <- code
+				return
 			}
 		}
 	}()
 
-	// Wait for both goroutines to complete
-	for i := 0; i < 2; i++ {
-		if err := <-errChan; err != nil {
-			log.Fatal(err)
+	// Wait for the exit code; stdin may still be open if the command never read it
+	for {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				log.Fatal(err)
+			}
+		case code := <-exitChan:
+			stream.CloseSend()
+			os.Exit(code)
 		}
 	}
-
-	stream.CloseSend()
 }
@@ -0,0 +1,800 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.29.3
+// source: array_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TaskState int32
+
+const (
+	TaskState_PENDING   TaskState = 0
+	TaskState_RUNNING   TaskState = 1
+	TaskState_DONE      TaskState = 2
+	TaskState_FAILED    TaskState = 3
+	TaskState_CANCELLED TaskState = 4
+)
+
+// Enum value maps for TaskState.
+var (
+	TaskState_name = map[int32]string{
+		0: "PENDING",
+		1: "RUNNING",
+		2: "DONE",
+		3: "FAILED",
+		4: "CANCELLED",
+	}
+	TaskState_value = map[string]int32{
+		"PENDING":   0,
+		"RUNNING":   1,
+		"DONE":      2,
+		"FAILED":    3,
+		"CANCELLED": 4,
+	}
+)
+
+func (x TaskState) Enum() *TaskState {
+	p := new(TaskState)
+	*p = x
+	return p
+}
+
+func (x TaskState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskState) Descriptor() protoreflect.EnumDescriptor {
+	return file_array_service_proto_enumTypes[0].Descriptor()
+}
+
+func (TaskState) Type() protoreflect.EnumType {
+	return &file_array_service_proto_enumTypes[0]
+}
+
+func (x TaskState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskState.Descriptor instead.
+func (TaskState) EnumDescriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{0}
+}
+
+// ArrayRequest runs a command template once for every combination of the
+// parameter values. "{name}" in the command is replaced by the value of the
+// parameter, which is also exported as $name.
+type ArrayRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Command     string        `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Params      []*ArrayParam `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty"`
+	Peers       []string      `protobuf:"bytes,3,rep,name=peers,proto3" json:"peers,omitempty"`              // peers or pools the tasks are spread across
+	Concurrency int32         `protobuf:"varint,4,opt,name=concurrency,proto3" json:"concurrency,omitempty"` // tasks running at once; defaults to the number of peers
+}
+
+func (x *ArrayRequest) Reset() {
+	*x = ArrayRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_array_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArrayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArrayRequest) ProtoMessage() {}
+
+func (x *ArrayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_array_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArrayRequest.ProtoReflect.Descriptor instead.
+func (*ArrayRequest) Descriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ArrayRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *ArrayRequest) GetParams() []*ArrayParam {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *ArrayRequest) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *ArrayRequest) GetConcurrency() int32 {
+	if x != nil {
+		return x.Concurrency
+	}
+	return 0
+}
+
+type ArrayParam struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Values []string `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+	Ranges []string `protobuf:"bytes,3,rep,name=ranges,proto3" json:"ranges,omitempty"` // integer ranges, "start-end" or "start-end:step"
+}
+
+func (x *ArrayParam) Reset() {
+	*x = ArrayParam{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_array_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArrayParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArrayParam) ProtoMessage() {}
+
+func (x *ArrayParam) ProtoReflect() protoreflect.Message {
+	mi := &file_array_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArrayParam.ProtoReflect.Descriptor instead.
+func (*ArrayParam) Descriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ArrayParam) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArrayParam) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *ArrayParam) GetRanges() []string {
+	if x != nil {
+		return x.Ranges
+	}
+	return nil
+}
+
+type ArrayId struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *ArrayId) Reset() {
+	*x = ArrayId{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_array_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArrayId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArrayId) ProtoMessage() {}
+
+func (x *ArrayId) ProtoReflect() protoreflect.Message {
+	mi := &file_array_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArrayId.ProtoReflect.Descriptor instead.
+func (*ArrayId) Descriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ArrayId) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type TaskId struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Array string `protobuf:"bytes,1,opt,name=array,proto3" json:"array,omitempty"`
+	Index int32  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+}
+
+func (x *TaskId) Reset() {
+	*x = TaskId{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_array_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskId) ProtoMessage() {}
+
+func (x *TaskId) ProtoReflect() protoreflect.Message {
+	mi := &file_array_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskId.ProtoReflect.Descriptor instead.
+func (*TaskId) Descriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TaskId) GetArray() string {
+	if x != nil {
+		return x.Array
+	}
+	return ""
+}
+
+func (x *TaskId) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type TaskStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index    int32             `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	State    TaskState         `protobuf:"varint,2,opt,name=state,proto3,enum=grpcsh.TaskState" json:"state,omitempty"`
+	Peer     string            `protobuf:"bytes,3,opt,name=peer,proto3" json:"peer,omitempty"`
+	ExitCode int32             `protobuf:"varint,4,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Params   map[string]string `protobuf:"bytes,5,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *TaskStatus) Reset() {
+	*x = TaskStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_array_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStatus) ProtoMessage() {}
+
+func (x *TaskStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_array_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStatus.ProtoReflect.Descriptor instead.
+func (*TaskStatus) Descriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TaskStatus) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *TaskStatus) GetState() TaskState {
+	if x != nil {
+		return x.State
+	}
+	return TaskState_PENDING
+}
+
+func (x *TaskStatus) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *TaskStatus) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type ArrayStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Pending   int32         `protobuf:"varint,2,opt,name=pending,proto3" json:"pending,omitempty"`
+	Running   int32         `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	Done      int32         `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	Failed    int32         `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	Cancelled int32         `protobuf:"varint,6,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	Tasks     []*TaskStatus `protobuf:"bytes,7,rep,name=tasks,proto3" json:"tasks,omitempty"`
+}
+
+func (x *ArrayStatus) Reset() {
+	*x = ArrayStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_array_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArrayStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArrayStatus) ProtoMessage() {}
+
+func (x *ArrayStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_array_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArrayStatus.ProtoReflect.Descriptor instead.
+func (*ArrayStatus) Descriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ArrayStatus) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ArrayStatus) GetPending() int32 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *ArrayStatus) GetRunning() int32 {
+	if x != nil {
+		return x.Running
+	}
+	return 0
+}
+
+func (x *ArrayStatus) GetDone() int32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *ArrayStatus) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ArrayStatus) GetCancelled() int32 {
+	if x != nil {
+		return x.Cancelled
+	}
+	return 0
+}
+
+func (x *ArrayStatus) GetTasks() []*TaskStatus {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type TaskOutput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status    *TaskStatus `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Stdout    []byte      `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"` // the last 256KiB of each stream
+	Stderr    []byte      `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	Truncated bool        `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`
+}
+
+func (x *TaskOutput) Reset() {
+	*x = TaskOutput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_array_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskOutput) ProtoMessage() {}
+
+func (x *TaskOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_array_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskOutput.ProtoReflect.Descriptor instead.
+func (*TaskOutput) Descriptor() ([]byte, []int) {
+	return file_array_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TaskOutput) GetStatus() *TaskStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *TaskOutput) GetStdout() []byte {
+	if x != nil {
+		return x.Stdout
+	}
+	return nil
+}
+
+func (x *TaskOutput) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *TaskOutput) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+var File_array_service_proto protoreflect.FileDescriptor
+
+var file_array_service_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x61, 0x72, 0x72, 0x61, 0x79, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x22, 0x8c, 0x01,
+	0x0a, 0x0c, 0x41, 0x72, 0x72, 0x61, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73,
+	0x68, 0x2e, 0x41, 0x72, 0x72, 0x61, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52, 0x06, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0b, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x22, 0x50, 0x0a, 0x0a,
+	0x41, 0x72, 0x72, 0x61, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0x19,
+	0x0a, 0x07, 0x41, 0x72, 0x72, 0x61, 0x79, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x34, 0x0a, 0x06, 0x54, 0x61, 0x73,
+	0x6b, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x72, 0x72, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x72, 0x72, 0x61, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22,
+	0xef, 0x01, 0x0a, 0x0a, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x27, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x54, 0x61, 0x73,
+	0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x70, 0x65, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x65, 0x65,
+	0x72, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x36,
+	0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06,
+	0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0xc5, 0x01, 0x0a, 0x0b, 0x41, 0x72, 0x72, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x72,
+	0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x72, 0x75,
+	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x61, 0x69,
+	0x6c, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65,
+	0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65, 0x64, 0x12,
+	0x28, 0x0a, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x22, 0x86, 0x01, 0x0a, 0x0a, 0x54, 0x61,
+	0x73, 0x6b, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x2a, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73,
+	0x68, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x74, 0x64, 0x65, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x74,
+	0x64, 0x65, 0x72, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x2a, 0x4a, 0x0a, 0x09, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x0b, 0x0a, 0x07, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07,
+	0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x4f, 0x4e,
+	0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x12,
+	0x0d, 0x0a, 0x09, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x32, 0xd1,
+	0x01, 0x0a, 0x0c, 0x41, 0x72, 0x72, 0x61, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x33, 0x0a, 0x06, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x12, 0x14, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x73, 0x68, 0x2e, 0x41, 0x72, 0x72, 0x61, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x41, 0x72, 0x72, 0x61, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x2e, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0f,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x41, 0x72, 0x72, 0x61, 0x79, 0x49, 0x64, 0x1a,
+	0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x41, 0x72, 0x72, 0x61, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x2c, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x0e,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x1a, 0x12,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x4f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x12, 0x2e, 0x0a, 0x06, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x12, 0x0f, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x41, 0x72, 0x72, 0x61, 0x79, 0x49, 0x64, 0x1a, 0x13, 0x2e,
+	0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x41, 0x72, 0x72, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x42, 0x0b, 0x5a, 0x09, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2f, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_array_service_proto_rawDescOnce sync.Once
+	file_array_service_proto_rawDescData = file_array_service_proto_rawDesc
+)
+
+func file_array_service_proto_rawDescGZIP() []byte {
+	file_array_service_proto_rawDescOnce.Do(func() {
+		file_array_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_array_service_proto_rawDescData)
+	})
+	return file_array_service_proto_rawDescData
+}
+
+var file_array_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_array_service_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_array_service_proto_goTypes = []any{
+	(TaskState)(0),       // 0: grpcsh.TaskState
+	(*ArrayRequest)(nil), // 1: grpcsh.ArrayRequest
+	(*ArrayParam)(nil),   // 2: grpcsh.ArrayParam
+	(*ArrayId)(nil),      // 3: grpcsh.ArrayId
+	(*TaskId)(nil),       // 4: grpcsh.TaskId
+	(*TaskStatus)(nil),   // 5: grpcsh.TaskStatus
+	(*ArrayStatus)(nil),  // 6: grpcsh.ArrayStatus
+	(*TaskOutput)(nil),   // 7: grpcsh.TaskOutput
+	nil,                  // 8: grpcsh.TaskStatus.ParamsEntry
+}
+var file_array_service_proto_depIdxs = []int32{
+	2, // 0: grpcsh.ArrayRequest.params:type_name -> grpcsh.ArrayParam
+	0, // 1: grpcsh.TaskStatus.state:type_name -> grpcsh.TaskState
+	8, // 2: grpcsh.TaskStatus.params:type_name -> grpcsh.TaskStatus.ParamsEntry
+	5, // 3: grpcsh.ArrayStatus.tasks:type_name -> grpcsh.TaskStatus
+	5, // 4: grpcsh.TaskOutput.status:type_name -> grpcsh.TaskStatus
+	1, // 5: grpcsh.ArrayService.Submit:input_type -> grpcsh.ArrayRequest
+	3, // 6: grpcsh.ArrayService.Status:input_type -> grpcsh.ArrayId
+	4, // 7: grpcsh.ArrayService.Output:input_type -> grpcsh.TaskId
+	3, // 8: grpcsh.ArrayService.Cancel:input_type -> grpcsh.ArrayId
+	6, // 9: grpcsh.ArrayService.Submit:output_type -> grpcsh.ArrayStatus
+	6, // 10: grpcsh.ArrayService.Status:output_type -> grpcsh.ArrayStatus
+	7, // 11: grpcsh.ArrayService.Output:output_type -> grpcsh.TaskOutput
+	6, // 12: grpcsh.ArrayService.Cancel:output_type -> grpcsh.ArrayStatus
+	9, // [9:13] is the sub-list for method output_type
+	5, // [5:9] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_array_service_proto_init() }
+func file_array_service_proto_init() {
+	if File_array_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_array_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ArrayRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_array_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ArrayParam); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_array_service_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ArrayId); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_array_service_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*TaskId); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_array_service_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*TaskStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_array_service_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ArrayStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_array_service_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*TaskOutput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_array_service_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_array_service_proto_goTypes,
+		DependencyIndexes: file_array_service_proto_depIdxs,
+		EnumInfos:         file_array_service_proto_enumTypes,
+		MessageInfos:      file_array_service_proto_msgTypes,
+	}.Build()
+	File_array_service_proto = out.File
+	file_array_service_proto_rawDesc = nil
+	file_array_service_proto_goTypes = nil
+	file_array_service_proto_depIdxs = nil
+}
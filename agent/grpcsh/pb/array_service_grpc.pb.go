@@ -0,0 +1,224 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: array_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	ArrayService_Submit_FullMethodName = "/grpcsh.ArrayService/Submit"
+	ArrayService_Status_FullMethodName = "/grpcsh.ArrayService/Status"
+	ArrayService_Output_FullMethodName = "/grpcsh.ArrayService/Output"
+	ArrayService_Cancel_FullMethodName = "/grpcsh.ArrayService/Cancel"
+)
+
+// ArrayServiceClient is the client API for ArrayService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ArrayServiceClient interface {
+	Submit(ctx context.Context, in *ArrayRequest, opts ...grpc.CallOption) (*ArrayStatus, error)
+	Status(ctx context.Context, in *ArrayId, opts ...grpc.CallOption) (*ArrayStatus, error)
+	Output(ctx context.Context, in *TaskId, opts ...grpc.CallOption) (*TaskOutput, error)
+	Cancel(ctx context.Context, in *ArrayId, opts ...grpc.CallOption) (*ArrayStatus, error)
+}
+
+type arrayServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArrayServiceClient(cc grpc.ClientConnInterface) ArrayServiceClient {
+	return &arrayServiceClient{cc}
+}
+
+func (c *arrayServiceClient) Submit(ctx context.Context, in *ArrayRequest, opts ...grpc.CallOption) (*ArrayStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArrayStatus)
+	err := c.cc.Invoke(ctx, ArrayService_Submit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arrayServiceClient) Status(ctx context.Context, in *ArrayId, opts ...grpc.CallOption) (*ArrayStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArrayStatus)
+	err := c.cc.Invoke(ctx, ArrayService_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arrayServiceClient) Output(ctx context.Context, in *TaskId, opts ...grpc.CallOption) (*TaskOutput, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskOutput)
+	err := c.cc.Invoke(ctx, ArrayService_Output_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arrayServiceClient) Cancel(ctx context.Context, in *ArrayId, opts ...grpc.CallOption) (*ArrayStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArrayStatus)
+	err := c.cc.Invoke(ctx, ArrayService_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ArrayServiceServer is the server API for ArrayService service.
+// All implementations must embed UnimplementedArrayServiceServer
+// for forward compatibility
+type ArrayServiceServer interface {
+	Submit(context.Context, *ArrayRequest) (*ArrayStatus, error)
+	Status(context.Context, *ArrayId) (*ArrayStatus, error)
+	Output(context.Context, *TaskId) (*TaskOutput, error)
+	Cancel(context.Context, *ArrayId) (*ArrayStatus, error)
+	mustEmbedUnimplementedArrayServiceServer()
+}
+
+// UnimplementedArrayServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedArrayServiceServer struct {
+}
+
+func (UnimplementedArrayServiceServer) Submit(context.Context, *ArrayRequest) (*ArrayStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedArrayServiceServer) Status(context.Context, *ArrayId) (*ArrayStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedArrayServiceServer) Output(context.Context, *TaskId) (*TaskOutput, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Output not implemented")
+}
+func (UnimplementedArrayServiceServer) Cancel(context.Context, *ArrayId) (*ArrayStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedArrayServiceServer) mustEmbedUnimplementedArrayServiceServer() {}
+
+// UnsafeArrayServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArrayServiceServer will
+// result in compilation errors.
+type UnsafeArrayServiceServer interface {
+	mustEmbedUnimplementedArrayServiceServer()
+}
+
+func RegisterArrayServiceServer(s grpc.ServiceRegistrar, srv ArrayServiceServer) {
+	s.RegisterService(&ArrayService_ServiceDesc, srv)
+}
+
+func _ArrayService_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArrayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArrayServiceServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArrayService_Submit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArrayServiceServer).Submit(ctx, req.(*ArrayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArrayService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArrayId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArrayServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArrayService_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArrayServiceServer).Status(ctx, req.(*ArrayId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArrayService_Output_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArrayServiceServer).Output(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArrayService_Output_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArrayServiceServer).Output(ctx, req.(*TaskId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArrayService_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArrayId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArrayServiceServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArrayService_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArrayServiceServer).Cancel(ctx, req.(*ArrayId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ArrayService_ServiceDesc is the grpc.ServiceDesc for ArrayService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ArrayService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.ArrayService",
+	HandlerType: (*ArrayServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Submit",
+			Handler:    _ArrayService_Submit_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _ArrayService_Status_Handler,
+		},
+		{
+			MethodName: "Output",
+			Handler:    _ArrayService_Output_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _ArrayService_Cancel_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "array_service.proto",
+}
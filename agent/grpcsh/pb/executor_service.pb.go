@@ -9,7 +9,9 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	reflect "reflect"
+	sync "sync"
 )
 
 const (
@@ -19,32 +21,248 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ExecStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PoolSize  int32       `protobuf:"varint,1,opt,name=pool_size,json=poolSize,proto3" json:"pool_size,omitempty"` // workers kept ready; 0 without a warm pool
+	Ready     int32       `protobuf:"varint,2,opt,name=ready,proto3" json:"ready,omitempty"`                       // workers ready now
+	Warm      *SpawnStats `protobuf:"bytes,3,opt,name=warm,proto3" json:"warm,omitempty"`                          // commands run on a ready worker
+	Cold      *SpawnStats `protobuf:"bytes,4,opt,name=cold,proto3" json:"cold,omitempty"`                          // commands that spawned a shell
+	Fallbacks int64       `protobuf:"varint,5,opt,name=fallbacks,proto3" json:"fallbacks,omitempty"`               // cold commands for which no worker was ready
+}
+
+func (x *ExecStats) Reset() {
+	*x = ExecStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_executor_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecStats) ProtoMessage() {}
+
+func (x *ExecStats) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecStats.ProtoReflect.Descriptor instead.
+func (*ExecStats) Descriptor() ([]byte, []int) {
+	return file_executor_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExecStats) GetPoolSize() int32 {
+	if x != nil {
+		return x.PoolSize
+	}
+	return 0
+}
+
+func (x *ExecStats) GetReady() int32 {
+	if x != nil {
+		return x.Ready
+	}
+	return 0
+}
+
+func (x *ExecStats) GetWarm() *SpawnStats {
+	if x != nil {
+		return x.Warm
+	}
+	return nil
+}
+
+func (x *ExecStats) GetCold() *SpawnStats {
+	if x != nil {
+		return x.Cold
+	}
+	return nil
+}
+
+func (x *ExecStats) GetFallbacks() int64 {
+	if x != nil {
+		return x.Fallbacks
+	}
+	return 0
+}
+
+// SpawnStats covers the last 1024 commands of their kind.
+type SpawnStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Commands    int64   `protobuf:"varint,1,opt,name=commands,proto3" json:"commands,omitempty"`                             // run since the agent started
+	StartMeanMs float64 `protobuf:"fixed64,2,opt,name=start_mean_ms,json=startMeanMs,proto3" json:"start_mean_ms,omitempty"` // until the command was handed to its shell
+	StartP99Ms  float64 `protobuf:"fixed64,3,opt,name=start_p99_ms,json=startP99Ms,proto3" json:"start_p99_ms,omitempty"`
+	RunMeanMs   float64 `protobuf:"fixed64,4,opt,name=run_mean_ms,json=runMeanMs,proto3" json:"run_mean_ms,omitempty"` // from its start until it exited
+	RunP50Ms    float64 `protobuf:"fixed64,5,opt,name=run_p50_ms,json=runP50Ms,proto3" json:"run_p50_ms,omitempty"`
+	RunP99Ms    float64 `protobuf:"fixed64,6,opt,name=run_p99_ms,json=runP99Ms,proto3" json:"run_p99_ms,omitempty"`
+}
+
+func (x *SpawnStats) Reset() {
+	*x = SpawnStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_executor_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SpawnStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpawnStats) ProtoMessage() {}
+
+func (x *SpawnStats) ProtoReflect() protoreflect.Message {
+	mi := &file_executor_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpawnStats.ProtoReflect.Descriptor instead.
+func (*SpawnStats) Descriptor() ([]byte, []int) {
+	return file_executor_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SpawnStats) GetCommands() int64 {
+	if x != nil {
+		return x.Commands
+	}
+	return 0
+}
+
+func (x *SpawnStats) GetStartMeanMs() float64 {
+	if x != nil {
+		return x.StartMeanMs
+	}
+	return 0
+}
+
+func (x *SpawnStats) GetStartP99Ms() float64 {
+	if x != nil {
+		return x.StartP99Ms
+	}
+	return 0
+}
+
+func (x *SpawnStats) GetRunMeanMs() float64 {
+	if x != nil {
+		return x.RunMeanMs
+	}
+	return 0
+}
+
+func (x *SpawnStats) GetRunP50Ms() float64 {
+	if x != nil {
+		return x.RunP50Ms
+	}
+	return 0
+}
+
+func (x *SpawnStats) GetRunP99Ms() float64 {
+	if x != nil {
+		return x.RunP99Ms
+	}
+	return 0
+}
+
 var File_executor_service_proto protoreflect.FileDescriptor
 
 var file_executor_service_proto_rawDesc = []byte{
 	0x0a, 0x16, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
 	0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68,
-	0x1a, 0x0e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x32, 0x3e, 0x0a, 0x0f, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x12, 0x2b, 0x0a, 0x04, 0x45, 0x78, 0x65, 0x63, 0x12, 0x0f, 0x2e, 0x67, 0x72,
-	0x70, 0x63, 0x73, 0x68, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x0e, 0x2e, 0x67,
-	0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x28, 0x01, 0x30, 0x01,
-	0x42, 0x0b, 0x5a, 0x09, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0e, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xac, 0x01,
+	0x0a, 0x09, 0x45, 0x78, 0x65, 0x63, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x70,
+	0x6f, 0x6f, 0x6c, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x70, 0x6f, 0x6f, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x61, 0x64,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x65, 0x61, 0x64, 0x79, 0x12, 0x26,
+	0x0a, 0x04, 0x77, 0x61, 0x72, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x70, 0x61, 0x77, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x04, 0x77, 0x61, 0x72, 0x6d, 0x12, 0x26, 0x0a, 0x04, 0x63, 0x6f, 0x6c, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x70,
+	0x61, 0x77, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x04, 0x63, 0x6f, 0x6c, 0x64, 0x12, 0x1c,
+	0x0a, 0x09, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x73, 0x22, 0xca, 0x01, 0x0a,
+	0x0a, 0x53, 0x70, 0x61, 0x77, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x5f, 0x6d, 0x65, 0x61, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x4d, 0x65, 0x61, 0x6e, 0x4d, 0x73, 0x12, 0x20, 0x0a, 0x0c, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x5f, 0x70, 0x39, 0x39, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x39, 0x39, 0x4d, 0x73, 0x12, 0x1e, 0x0a,
+	0x0b, 0x72, 0x75, 0x6e, 0x5f, 0x6d, 0x65, 0x61, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x09, 0x72, 0x75, 0x6e, 0x4d, 0x65, 0x61, 0x6e, 0x4d, 0x73, 0x12, 0x1c, 0x0a,
+	0x0a, 0x72, 0x75, 0x6e, 0x5f, 0x70, 0x35, 0x30, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x72, 0x75, 0x6e, 0x50, 0x35, 0x30, 0x4d, 0x73, 0x12, 0x1c, 0x0a, 0x0a, 0x72,
+	0x75, 0x6e, 0x5f, 0x70, 0x39, 0x39, 0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x08, 0x72, 0x75, 0x6e, 0x50, 0x39, 0x39, 0x4d, 0x73, 0x32, 0x72, 0x0a, 0x0f, 0x45, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2b, 0x0a, 0x04,
+	0x45, 0x78, 0x65, 0x63, 0x12, 0x0f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x0e, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x28, 0x01, 0x30, 0x01, 0x12, 0x32, 0x0a, 0x05, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x67, 0x72, 0x70,
+	0x63, 0x73, 0x68, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x53, 0x74, 0x61, 0x74, 0x73, 0x42, 0x0b, 0x5a,
+	0x09, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_executor_service_proto_rawDescOnce sync.Once
+	file_executor_service_proto_rawDescData = file_executor_service_proto_rawDesc
+)
+
+func file_executor_service_proto_rawDescGZIP() []byte {
+	file_executor_service_proto_rawDescOnce.Do(func() {
+		file_executor_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_executor_service_proto_rawDescData)
+	})
+	return file_executor_service_proto_rawDescData
 }
 
+var file_executor_service_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_executor_service_proto_goTypes = []any{
-	(*Message)(nil), // 0: grpcsh.Message
-	(*Result)(nil),  // 1: grpcsh.Result
+	(*ExecStats)(nil),     // 0: grpcsh.ExecStats
+	(*SpawnStats)(nil),    // 1: grpcsh.SpawnStats
+	(*Message)(nil),       // 2: grpcsh.Message
+	(*emptypb.Empty)(nil), // 3: google.protobuf.Empty
+	(*Result)(nil),        // 4: grpcsh.Result
 }
 var file_executor_service_proto_depIdxs = []int32{
-	0, // 0: grpcsh.ExecutorService.Exec:input_type -> grpcsh.Message
-	1, // 1: grpcsh.ExecutorService.Exec:output_type -> grpcsh.Result
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: grpcsh.ExecStats.warm:type_name -> grpcsh.SpawnStats
+	1, // 1: grpcsh.ExecStats.cold:type_name -> grpcsh.SpawnStats
+	2, // 2: grpcsh.ExecutorService.Exec:input_type -> grpcsh.Message
+	3, // 3: grpcsh.ExecutorService.Stats:input_type -> google.protobuf.Empty
+	4, // 4: grpcsh.ExecutorService.Exec:output_type -> grpcsh.Result
+	0, // 5: grpcsh.ExecutorService.Stats:output_type -> grpcsh.ExecStats
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_executor_service_proto_init() }
@@ -53,18 +271,45 @@ func file_executor_service_proto_init() {
 		return
 	}
 	file_messages_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_executor_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ExecStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_executor_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*SpawnStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_executor_service_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   0,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_executor_service_proto_goTypes,
 		DependencyIndexes: file_executor_service_proto_depIdxs,
+		MessageInfos:      file_executor_service_proto_msgTypes,
 	}.Build()
 	File_executor_service_proto = out.File
 	file_executor_service_proto_rawDesc = nil
@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,7 +20,8 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	ExecutorService_Exec_FullMethodName = "/grpcsh.ExecutorService/Exec"
+	ExecutorService_Exec_FullMethodName  = "/grpcsh.ExecutorService/Exec"
+	ExecutorService_Stats_FullMethodName = "/grpcsh.ExecutorService/Stats"
 )
 
 // ExecutorServiceClient is the client API for ExecutorService service.
@@ -27,6 +29,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ExecutorServiceClient interface {
 	Exec(ctx context.Context, opts ...grpc.CallOption) (ExecutorService_ExecClient, error)
+	// Stats compares the latency of commands started on the agent's warm
+	// workers with those that spawned a shell.
+	Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ExecStats, error)
 }
 
 type executorServiceClient struct {
@@ -69,11 +74,24 @@ func (x *executorServiceExecClient) Recv() (*Result, error) {
 	return m, nil
 }
 
+func (c *executorServiceClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ExecStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecStats)
+	err := c.cc.Invoke(ctx, ExecutorService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ExecutorServiceServer is the server API for ExecutorService service.
 // All implementations must embed UnimplementedExecutorServiceServer
 // for forward compatibility
 type ExecutorServiceServer interface {
 	Exec(ExecutorService_ExecServer) error
+	// Stats compares the latency of commands started on the agent's warm
+	// workers with those that spawned a shell.
+	Stats(context.Context, *emptypb.Empty) (*ExecStats, error)
 	mustEmbedUnimplementedExecutorServiceServer()
 }
 
@@ -84,6 +102,9 @@ type UnimplementedExecutorServiceServer struct {
 func (UnimplementedExecutorServiceServer) Exec(ExecutorService_ExecServer) error {
 	return status.Errorf(codes.Unimplemented, "method Exec not implemented")
 }
+func (UnimplementedExecutorServiceServer) Stats(context.Context, *emptypb.Empty) (*ExecStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
 func (UnimplementedExecutorServiceServer) mustEmbedUnimplementedExecutorServiceServer() {}
 
 // UnsafeExecutorServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -123,13 +144,36 @@ func (x *executorServiceExecServer) Recv() (*Message, error) {
 	return m, nil
 }
 
+func _ExecutorService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecutorServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExecutorService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecutorServiceServer).Stats(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ExecutorService_ServiceDesc is the grpc.ServiceDesc for ExecutorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var ExecutorService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "grpcsh.ExecutorService",
 	HandlerType: (*ExecutorServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Stats",
+			Handler:    _ExecutorService_Stats_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Exec",
@@ -0,0 +1,593 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: file_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	FileService_Upload_FullMethodName    = "/grpcsh.FileService/Upload"
+	FileService_Download_FullMethodName  = "/grpcsh.FileService/Download"
+	FileService_Stage_FullMethodName     = "/grpcsh.FileService/Stage"
+	FileService_Follow_FullMethodName    = "/grpcsh.FileService/Follow"
+	FileService_Stat_FullMethodName      = "/grpcsh.FileService/Stat"
+	FileService_ListDir_FullMethodName   = "/grpcsh.FileService/ListDir"
+	FileService_ReadRange_FullMethodName = "/grpcsh.FileService/ReadRange"
+	FileService_Mkdir_FullMethodName     = "/grpcsh.FileService/Mkdir"
+	FileService_Remove_FullMethodName    = "/grpcsh.FileService/Remove"
+	FileService_Rename_FullMethodName    = "/grpcsh.FileService/Rename"
+)
+
+// FileServiceClient is the client API for FileService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FileServiceClient interface {
+	// Upload writes a file. The first chunk carries the header; the agent
+	// answers with the offset to continue from, which is past the bytes kept
+	// from an interrupted upload of the same content, or at the end if the
+	// agent's cache holds it, and, once the digest checks out, with the
+	// header of the stored file.
+	Upload(ctx context.Context, opts ...grpc.CallOption) (FileService_UploadClient, error)
+	// Download sends the header of a file, with the offset the data starts
+	// at, followed by the data.
+	Download(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (FileService_DownloadClient, error)
+	// Stage sends a file from this agent straight to another peer over a
+	// router channel, as an upload to it, and reports progress until the peer
+	// has verified the digest.
+	Stage(ctx context.Context, in *StageRequest, opts ...grpc.CallOption) (FileService_StageClient, error)
+	// Follow streams what is appended to several files, like tail -F, until
+	// the client goes away. Each file is reopened when it is rotated and
+	// read again from the start when it is truncated.
+	Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (FileService_FollowClient, error)
+	Stat(ctx context.Context, in *PathRequest, opts ...grpc.CallOption) (*FileInfo, error)
+	ListDir(ctx context.Context, in *ListDirRequest, opts ...grpc.CallOption) (*ListDirResponse, error)
+	ReadRange(ctx context.Context, in *ReadRangeRequest, opts ...grpc.CallOption) (*ReadRangeResponse, error)
+	Mkdir(ctx context.Context, in *MkdirRequest, opts ...grpc.CallOption) (*FileInfo, error)
+	Remove(ctx context.Context, in *RemoveRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*FileInfo, error)
+}
+
+type fileServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFileServiceClient(cc grpc.ClientConnInterface) FileServiceClient {
+	return &fileServiceClient{cc}
+}
+
+func (c *fileServiceClient) Upload(ctx context.Context, opts ...grpc.CallOption) (FileService_UploadClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileService_ServiceDesc.Streams[0], FileService_Upload_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServiceUploadClient{ClientStream: stream}
+	return x, nil
+}
+
+type FileService_UploadClient interface {
+	Send(*FileChunk) error
+	Recv() (*FileChunk, error)
+	grpc.ClientStream
+}
+
+type fileServiceUploadClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServiceUploadClient) Send(m *FileChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *fileServiceUploadClient) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *fileServiceClient) Download(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (FileService_DownloadClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileService_ServiceDesc.Streams[1], FileService_Download_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServiceDownloadClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FileService_DownloadClient interface {
+	Recv() (*FileChunk, error)
+	grpc.ClientStream
+}
+
+type fileServiceDownloadClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServiceDownloadClient) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *fileServiceClient) Stage(ctx context.Context, in *StageRequest, opts ...grpc.CallOption) (FileService_StageClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileService_ServiceDesc.Streams[2], FileService_Stage_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServiceStageClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FileService_StageClient interface {
+	Recv() (*StageProgress, error)
+	grpc.ClientStream
+}
+
+type fileServiceStageClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServiceStageClient) Recv() (*StageProgress, error) {
+	m := new(StageProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *fileServiceClient) Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (FileService_FollowClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileService_ServiceDesc.Streams[3], FileService_Follow_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServiceFollowClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FileService_FollowClient interface {
+	Recv() (*FollowEvent, error)
+	grpc.ClientStream
+}
+
+type fileServiceFollowClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServiceFollowClient) Recv() (*FollowEvent, error) {
+	m := new(FollowEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *fileServiceClient) Stat(ctx context.Context, in *PathRequest, opts ...grpc.CallOption) (*FileInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, FileService_Stat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileServiceClient) ListDir(ctx context.Context, in *ListDirRequest, opts ...grpc.CallOption) (*ListDirResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDirResponse)
+	err := c.cc.Invoke(ctx, FileService_ListDir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileServiceClient) ReadRange(ctx context.Context, in *ReadRangeRequest, opts ...grpc.CallOption) (*ReadRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadRangeResponse)
+	err := c.cc.Invoke(ctx, FileService_ReadRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileServiceClient) Mkdir(ctx context.Context, in *MkdirRequest, opts ...grpc.CallOption) (*FileInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, FileService_Mkdir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileServiceClient) Remove(ctx context.Context, in *RemoveRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, FileService_Remove_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileServiceClient) Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*FileInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileInfo)
+	err := c.cc.Invoke(ctx, FileService_Rename_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FileServiceServer is the server API for FileService service.
+// All implementations must embed UnimplementedFileServiceServer
+// for forward compatibility
+type FileServiceServer interface {
+	// Upload writes a file. The first chunk carries the header; the agent
+	// answers with the offset to continue from, which is past the bytes kept
+	// from an interrupted upload of the same content, or at the end if the
+	// agent's cache holds it, and, once the digest checks out, with the
+	// header of the stored file.
+	Upload(FileService_UploadServer) error
+	// Download sends the header of a file, with the offset the data starts
+	// at, followed by the data.
+	Download(*DownloadRequest, FileService_DownloadServer) error
+	// Stage sends a file from this agent straight to another peer over a
+	// router channel, as an upload to it, and reports progress until the peer
+	// has verified the digest.
+	Stage(*StageRequest, FileService_StageServer) error
+	// Follow streams what is appended to several files, like tail -F, until
+	// the client goes away. Each file is reopened when it is rotated and
+	// read again from the start when it is truncated.
+	Follow(*FollowRequest, FileService_FollowServer) error
+	Stat(context.Context, *PathRequest) (*FileInfo, error)
+	ListDir(context.Context, *ListDirRequest) (*ListDirResponse, error)
+	ReadRange(context.Context, *ReadRangeRequest) (*ReadRangeResponse, error)
+	Mkdir(context.Context, *MkdirRequest) (*FileInfo, error)
+	Remove(context.Context, *RemoveRequest) (*emptypb.Empty, error)
+	Rename(context.Context, *RenameRequest) (*FileInfo, error)
+	mustEmbedUnimplementedFileServiceServer()
+}
+
+// UnimplementedFileServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedFileServiceServer struct {
+}
+
+func (UnimplementedFileServiceServer) Upload(FileService_UploadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
+func (UnimplementedFileServiceServer) Download(*DownloadRequest, FileService_DownloadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Download not implemented")
+}
+func (UnimplementedFileServiceServer) Stage(*StageRequest, FileService_StageServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stage not implemented")
+}
+func (UnimplementedFileServiceServer) Follow(*FollowRequest, FileService_FollowServer) error {
+	return status.Errorf(codes.Unimplemented, "method Follow not implemented")
+}
+func (UnimplementedFileServiceServer) Stat(context.Context, *PathRequest) (*FileInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stat not implemented")
+}
+func (UnimplementedFileServiceServer) ListDir(context.Context, *ListDirRequest) (*ListDirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDir not implemented")
+}
+func (UnimplementedFileServiceServer) ReadRange(context.Context, *ReadRangeRequest) (*ReadRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadRange not implemented")
+}
+func (UnimplementedFileServiceServer) Mkdir(context.Context, *MkdirRequest) (*FileInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Mkdir not implemented")
+}
+func (UnimplementedFileServiceServer) Remove(context.Context, *RemoveRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Remove not implemented")
+}
+func (UnimplementedFileServiceServer) Rename(context.Context, *RenameRequest) (*FileInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rename not implemented")
+}
+func (UnimplementedFileServiceServer) mustEmbedUnimplementedFileServiceServer() {}
+
+// UnsafeFileServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FileServiceServer will
+// result in compilation errors.
+type UnsafeFileServiceServer interface {
+	mustEmbedUnimplementedFileServiceServer()
+}
+
+func RegisterFileServiceServer(s grpc.ServiceRegistrar, srv FileServiceServer) {
+	s.RegisterService(&FileService_ServiceDesc, srv)
+}
+
+func _FileService_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FileServiceServer).Upload(&fileServiceUploadServer{ServerStream: stream})
+}
+
+type FileService_UploadServer interface {
+	Send(*FileChunk) error
+	Recv() (*FileChunk, error)
+	grpc.ServerStream
+}
+
+type fileServiceUploadServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServiceUploadServer) Send(m *FileChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *fileServiceUploadServer) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _FileService_Download_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileServiceServer).Download(m, &fileServiceDownloadServer{ServerStream: stream})
+}
+
+type FileService_DownloadServer interface {
+	Send(*FileChunk) error
+	grpc.ServerStream
+}
+
+type fileServiceDownloadServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServiceDownloadServer) Send(m *FileChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FileService_Stage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileServiceServer).Stage(m, &fileServiceStageServer{ServerStream: stream})
+}
+
+type FileService_StageServer interface {
+	Send(*StageProgress) error
+	grpc.ServerStream
+}
+
+type fileServiceStageServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServiceStageServer) Send(m *StageProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FileService_Follow_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FollowRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileServiceServer).Follow(m, &fileServiceFollowServer{ServerStream: stream})
+}
+
+type FileService_FollowServer interface {
+	Send(*FollowEvent) error
+	grpc.ServerStream
+}
+
+type fileServiceFollowServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServiceFollowServer) Send(m *FollowEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FileService_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileServiceServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileService_Stat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileServiceServer).Stat(ctx, req.(*PathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileService_ListDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileServiceServer).ListDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileService_ListDir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileServiceServer).ListDir(ctx, req.(*ListDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileService_ReadRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileServiceServer).ReadRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileService_ReadRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileServiceServer).ReadRange(ctx, req.(*ReadRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileService_Mkdir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MkdirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileServiceServer).Mkdir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileService_Mkdir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileServiceServer).Mkdir(ctx, req.(*MkdirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileService_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileServiceServer).Remove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileService_Remove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileServiceServer).Remove(ctx, req.(*RemoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileService_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileServiceServer).Rename(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileService_Rename_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileServiceServer).Rename(ctx, req.(*RenameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FileService_ServiceDesc is the grpc.ServiceDesc for FileService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FileService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.FileService",
+	HandlerType: (*FileServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Stat",
+			Handler:    _FileService_Stat_Handler,
+		},
+		{
+			MethodName: "ListDir",
+			Handler:    _FileService_ListDir_Handler,
+		},
+		{
+			MethodName: "ReadRange",
+			Handler:    _FileService_ReadRange_Handler,
+		},
+		{
+			MethodName: "Mkdir",
+			Handler:    _FileService_Mkdir_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _FileService_Remove_Handler,
+		},
+		{
+			MethodName: "Rename",
+			Handler:    _FileService_Rename_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Upload",
+			Handler:       _FileService_Upload_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Download",
+			Handler:       _FileService_Download_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Stage",
+			Handler:       _FileService_Stage_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Follow",
+			Handler:       _FileService_Follow_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "file_service.proto",
+}
@@ -0,0 +1,298 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.29.3
+// source: gang_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GangRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Peers        []string          `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"` // one rank per peer or pool, in rank order
+	Command      string            `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Env          map[string]string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Rendezvous   string            `protobuf:"bytes,4,opt,name=rendezvous,proto3" json:"rendezvous,omitempty"`                          // MASTER_ADDR; defaults to the host of rank 0
+	Port         int32             `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`                                     // MASTER_PORT; defaults to 29500
+	StartTimeout int32             `protobuf:"varint,6,opt,name=start_timeout,json=startTimeout,proto3" json:"start_timeout,omitempty"` // seconds for all ranks to start; defaults to 60
+}
+
+func (x *GangRequest) Reset() {
+	*x = GangRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gang_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GangRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GangRequest) ProtoMessage() {}
+
+func (x *GangRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gang_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GangRequest.ProtoReflect.Descriptor instead.
+func (*GangRequest) Descriptor() ([]byte, []int) {
+	return file_gang_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GangRequest) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *GangRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *GangRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *GangRequest) GetRendezvous() string {
+	if x != nil {
+		return x.Rendezvous
+	}
+	return ""
+}
+
+func (x *GangRequest) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *GangRequest) GetStartTimeout() int32 {
+	if x != nil {
+		return x.StartTimeout
+	}
+	return 0
+}
+
+type GangEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rank int32  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	Peer string `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	Flag Flag   `protobuf:"varint,3,opt,name=flag,proto3,enum=grpcsh.Flag" json:"flag,omitempty"` // STARTED, MSG_STDOUT, MSG_STDERR or EXIT
+	Data []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *GangEvent) Reset() {
+	*x = GangEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gang_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GangEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GangEvent) ProtoMessage() {}
+
+func (x *GangEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_gang_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GangEvent.ProtoReflect.Descriptor instead.
+func (*GangEvent) Descriptor() ([]byte, []int) {
+	return file_gang_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GangEvent) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *GangEvent) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *GangEvent) GetFlag() Flag {
+	if x != nil {
+		return x.Flag
+	}
+	return Flag_NONE
+}
+
+func (x *GangEvent) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_gang_service_proto protoreflect.FileDescriptor
+
+var file_gang_service_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x67, 0x61, 0x6e, 0x67, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x1a, 0x0e, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xfe, 0x01, 0x0a,
+	0x0b, 0x47, 0x61, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x65, 0x65,
+	0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x2e, 0x0a, 0x03,
+	0x65, 0x6e, 0x76, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x73, 0x68, 0x2e, 0x47, 0x61, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x45,
+	0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x65, 0x6e, 0x76, 0x12, 0x1e, 0x0a, 0x0a,
+	0x72, 0x65, 0x6e, 0x64, 0x65, 0x7a, 0x76, 0x6f, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x72, 0x65, 0x6e, 0x64, 0x65, 0x7a, 0x76, 0x6f, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74,
+	0x12, 0x23, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x1a, 0x36, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x69, 0x0a,
+	0x09, 0x47, 0x61, 0x6e, 0x67, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61,
+	0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x65, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x65,
+	0x65, 0x72, 0x12, 0x20, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0c, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x04,
+	0x66, 0x6c, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x32, 0x41, 0x0a, 0x0b, 0x47, 0x61, 0x6e, 0x67,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x06, 0x4c, 0x61, 0x75, 0x6e, 0x63,
+	0x68, 0x12, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x47, 0x61, 0x6e, 0x67, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e,
+	0x47, 0x61, 0x6e, 0x67, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x0b, 0x5a, 0x09, 0x67,
+	0x72, 0x70, 0x63, 0x73, 0x68, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gang_service_proto_rawDescOnce sync.Once
+	file_gang_service_proto_rawDescData = file_gang_service_proto_rawDesc
+)
+
+func file_gang_service_proto_rawDescGZIP() []byte {
+	file_gang_service_proto_rawDescOnce.Do(func() {
+		file_gang_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_gang_service_proto_rawDescData)
+	})
+	return file_gang_service_proto_rawDescData
+}
+
+var file_gang_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_gang_service_proto_goTypes = []any{
+	(*GangRequest)(nil), // 0: grpcsh.GangRequest
+	(*GangEvent)(nil),   // 1: grpcsh.GangEvent
+	nil,                 // 2: grpcsh.GangRequest.EnvEntry
+	(Flag)(0),           // 3: grpcsh.Flag
+}
+var file_gang_service_proto_depIdxs = []int32{
+	2, // 0: grpcsh.GangRequest.env:type_name -> grpcsh.GangRequest.EnvEntry
+	3, // 1: grpcsh.GangEvent.flag:type_name -> grpcsh.Flag
+	0, // 2: grpcsh.GangService.Launch:input_type -> grpcsh.GangRequest
+	1, // 3: grpcsh.GangService.Launch:output_type -> grpcsh.GangEvent
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_gang_service_proto_init() }
+func file_gang_service_proto_init() {
+	if File_gang_service_proto != nil {
+		return
+	}
+	file_messages_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_gang_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*GangRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gang_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*GangEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gang_service_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gang_service_proto_goTypes,
+		DependencyIndexes: file_gang_service_proto_depIdxs,
+		MessageInfos:      file_gang_service_proto_msgTypes,
+	}.Build()
+	File_gang_service_proto = out.File
+	file_gang_service_proto_rawDesc = nil
+	file_gang_service_proto_goTypes = nil
+	file_gang_service_proto_depIdxs = nil
+}
@@ -0,0 +1,137 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: gang_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	GangService_Launch_FullMethodName = "/grpcsh.GangService/Launch"
+)
+
+// GangServiceClient is the client API for GangService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GangServiceClient interface {
+	Launch(ctx context.Context, in *GangRequest, opts ...grpc.CallOption) (GangService_LaunchClient, error)
+}
+
+type gangServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGangServiceClient(cc grpc.ClientConnInterface) GangServiceClient {
+	return &gangServiceClient{cc}
+}
+
+func (c *gangServiceClient) Launch(ctx context.Context, in *GangRequest, opts ...grpc.CallOption) (GangService_LaunchClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GangService_ServiceDesc.Streams[0], GangService_Launch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gangServiceLaunchClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GangService_LaunchClient interface {
+	Recv() (*GangEvent, error)
+	grpc.ClientStream
+}
+
+type gangServiceLaunchClient struct {
+	grpc.ClientStream
+}
+
+func (x *gangServiceLaunchClient) Recv() (*GangEvent, error) {
+	m := new(GangEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GangServiceServer is the server API for GangService service.
+// All implementations must embed UnimplementedGangServiceServer
+// for forward compatibility
+type GangServiceServer interface {
+	Launch(*GangRequest, GangService_LaunchServer) error
+	mustEmbedUnimplementedGangServiceServer()
+}
+
+// UnimplementedGangServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedGangServiceServer struct {
+}
+
+func (UnimplementedGangServiceServer) Launch(*GangRequest, GangService_LaunchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Launch not implemented")
+}
+func (UnimplementedGangServiceServer) mustEmbedUnimplementedGangServiceServer() {}
+
+// UnsafeGangServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GangServiceServer will
+// result in compilation errors.
+type UnsafeGangServiceServer interface {
+	mustEmbedUnimplementedGangServiceServer()
+}
+
+func RegisterGangServiceServer(s grpc.ServiceRegistrar, srv GangServiceServer) {
+	s.RegisterService(&GangService_ServiceDesc, srv)
+}
+
+func _GangService_Launch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GangRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GangServiceServer).Launch(m, &gangServiceLaunchServer{ServerStream: stream})
+}
+
+type GangService_LaunchServer interface {
+	Send(*GangEvent) error
+	grpc.ServerStream
+}
+
+type gangServiceLaunchServer struct {
+	grpc.ServerStream
+}
+
+func (x *gangServiceLaunchServer) Send(m *GangEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// GangService_ServiceDesc is the grpc.ServiceDesc for GangService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GangService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.GangService",
+	HandlerType: (*GangServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Launch",
+			Handler:       _GangService_Launch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gang_service.proto",
+}
@@ -0,0 +1,349 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: job_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	JobService_Submit_FullMethodName = "/grpcsh.JobService/Submit"
+	JobService_List_FullMethodName   = "/grpcsh.JobService/List"
+	JobService_Status_FullMethodName = "/grpcsh.JobService/Status"
+	JobService_Output_FullMethodName = "/grpcsh.JobService/Output"
+	JobService_Cancel_FullMethodName = "/grpcsh.JobService/Cancel"
+	JobService_Remove_FullMethodName = "/grpcsh.JobService/Remove"
+)
+
+// JobServiceClient is the client API for JobService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// JobService runs commands detached from the call that submits them. The
+// agent spools a job's stdout and stderr to files, so that any client can
+// read them later, from where it left off, while the job runs or after it
+// exits.
+type JobServiceClient interface {
+	// Submit starts a command, with stdin from /dev/null, and returns at once.
+	Submit(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Job, error)
+	List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*JobList, error)
+	Status(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*Job, error)
+	// Output sends what a job wrote from the given offsets, then EXIT if it
+	// has ended. With follow, it waits for more output until the job ends.
+	Output(ctx context.Context, in *OutputRequest, opts ...grpc.CallOption) (JobService_OutputClient, error)
+	// Cancel signals a running job and everything it started.
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*Job, error)
+	// Remove forgets a job that has ended and deletes its output.
+	Remove(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type jobServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJobServiceClient(cc grpc.ClientConnInterface) JobServiceClient {
+	return &jobServiceClient{cc}
+}
+
+func (c *jobServiceClient) Submit(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, JobService_Submit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobServiceClient) List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*JobList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobList)
+	err := c.cc.Invoke(ctx, JobService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobServiceClient) Status(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, JobService_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobServiceClient) Output(ctx context.Context, in *OutputRequest, opts ...grpc.CallOption) (JobService_OutputClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &JobService_ServiceDesc.Streams[0], JobService_Output_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobServiceOutputClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobService_OutputClient interface {
+	Recv() (*JobOutput, error)
+	grpc.ClientStream
+}
+
+type jobServiceOutputClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobServiceOutputClient) Recv() (*JobOutput, error) {
+	m := new(JobOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobServiceClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, JobService_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobServiceClient) Remove(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, JobService_Remove_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JobServiceServer is the server API for JobService service.
+// All implementations must embed UnimplementedJobServiceServer
+// for forward compatibility
+//
+// JobService runs commands detached from the call that submits them. The
+// agent spools a job's stdout and stderr to files, so that any client can
+// read them later, from where it left off, while the job runs or after it
+// exits.
+type JobServiceServer interface {
+	// Submit starts a command, with stdin from /dev/null, and returns at once.
+	Submit(context.Context, *JobRequest) (*Job, error)
+	List(context.Context, *emptypb.Empty) (*JobList, error)
+	Status(context.Context, *JobId) (*Job, error)
+	// Output sends what a job wrote from the given offsets, then EXIT if it
+	// has ended. With follow, it waits for more output until the job ends.
+	Output(*OutputRequest, JobService_OutputServer) error
+	// Cancel signals a running job and everything it started.
+	Cancel(context.Context, *CancelRequest) (*Job, error)
+	// Remove forgets a job that has ended and deletes its output.
+	Remove(context.Context, *JobId) (*emptypb.Empty, error)
+	mustEmbedUnimplementedJobServiceServer()
+}
+
+// UnimplementedJobServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedJobServiceServer struct {
+}
+
+func (UnimplementedJobServiceServer) Submit(context.Context, *JobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedJobServiceServer) List(context.Context, *emptypb.Empty) (*JobList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedJobServiceServer) Status(context.Context, *JobId) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedJobServiceServer) Output(*OutputRequest, JobService_OutputServer) error {
+	return status.Errorf(codes.Unimplemented, "method Output not implemented")
+}
+func (UnimplementedJobServiceServer) Cancel(context.Context, *CancelRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedJobServiceServer) Remove(context.Context, *JobId) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Remove not implemented")
+}
+func (UnimplementedJobServiceServer) mustEmbedUnimplementedJobServiceServer() {}
+
+// UnsafeJobServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JobServiceServer will
+// result in compilation errors.
+type UnsafeJobServiceServer interface {
+	mustEmbedUnimplementedJobServiceServer()
+}
+
+func RegisterJobServiceServer(s grpc.ServiceRegistrar, srv JobServiceServer) {
+	s.RegisterService(&JobService_ServiceDesc, srv)
+}
+
+func _JobService_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobServiceServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobService_Submit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobServiceServer).Submit(ctx, req.(*JobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobServiceServer).List(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobService_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobServiceServer).Status(ctx, req.(*JobId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobService_Output_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(OutputRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobServiceServer).Output(m, &jobServiceOutputServer{ServerStream: stream})
+}
+
+type JobService_OutputServer interface {
+	Send(*JobOutput) error
+	grpc.ServerStream
+}
+
+type jobServiceOutputServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobServiceOutputServer) Send(m *JobOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobService_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobServiceServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobService_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobServiceServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobService_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobServiceServer).Remove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobService_Remove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobServiceServer).Remove(ctx, req.(*JobId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// JobService_ServiceDesc is the grpc.ServiceDesc for JobService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JobService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.JobService",
+	HandlerType: (*JobServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Submit",
+			Handler:    _JobService_Submit_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _JobService_List_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _JobService_Status_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _JobService_Cancel_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _JobService_Remove_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Output",
+			Handler:       _JobService_Output_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "job_service.proto",
+}
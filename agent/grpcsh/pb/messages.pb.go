@@ -9,6 +9,7 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -31,19 +32,44 @@ const (
 	Flag_EOF_STDIN  Flag = 5
 	Flag_EOF_STDOUT Flag = 6
 	Flag_EOF_STDERR Flag = 7
+	Flag_EXIT       Flag = 8
+	Flag_STARTED    Flag = 9
+	Flag_SIGNAL     Flag = 10
+	Flag_CALL       Flag = 11
+	Flag_TERMINAL   Flag = 12
+	Flag_RESIZE     Flag = 13
+	Flag_HEADER     Flag = 14
+	Flag_TRAILER    Flag = 15
+	Flag_PIPE       Flag = 16
+	// "in <n>" or "out <n>": the receiver of a channel consumed n more bytes
+	// of the MSG_STDIN, or of the MSG_STDOUT and MSG_STDERR, frames the peer
+	// sent on it. A peer sends at most a window of each ahead of the WINDOW
+	// frames it got back, so that a slow receiver holds up its own channel
+	// only.
+	Flag_WINDOW Flag = 17
 )
 
 // Enum value maps for Flag.
 var (
 	Flag_name = map[int32]string{
-		0: "NONE",
-		1: "COMMAND",
-		2: "MSG_STDIN",
-		3: "MSG_STDOUT",
-		4: "MSG_STDERR",
-		5: "EOF_STDIN",
-		6: "EOF_STDOUT",
-		7: "EOF_STDERR",
+		0:  "NONE",
+		1:  "COMMAND",
+		2:  "MSG_STDIN",
+		3:  "MSG_STDOUT",
+		4:  "MSG_STDERR",
+		5:  "EOF_STDIN",
+		6:  "EOF_STDOUT",
+		7:  "EOF_STDERR",
+		8:  "EXIT",
+		9:  "STARTED",
+		10: "SIGNAL",
+		11: "CALL",
+		12: "TERMINAL",
+		13: "RESIZE",
+		14: "HEADER",
+		15: "TRAILER",
+		16: "PIPE",
+		17: "WINDOW",
 	}
 	Flag_value = map[string]int32{
 		"NONE":       0,
@@ -54,6 +80,16 @@ var (
 		"EOF_STDIN":  5,
 		"EOF_STDOUT": 6,
 		"EOF_STDERR": 7,
+		"EXIT":       8,
+		"STARTED":    9,
+		"SIGNAL":     10,
+		"CALL":       11,
+		"TERMINAL":   12,
+		"RESIZE":     13,
+		"HEADER":     14,
+		"TRAILER":    15,
+		"PIPE":       16,
+		"WINDOW":     17,
 	}
 )
 
@@ -305,11 +341,194 @@ func (x *PeerMessage) GetData() []byte {
 	return nil
 }
 
+// CallMetadata is the data of the HEADER and TRAILER frames of a relayed
+// call: the caller sends its metadata and deadline with HEADER after CALL,
+// and the callee the response's headers with HEADER and trailers with
+// TRAILER before EXIT.
+type CallMetadata struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*MetadataEntry     `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Timeout *durationpb.Duration `protobuf:"bytes,2,opt,name=timeout,proto3" json:"timeout,omitempty"` // left until the deadline of the call
+}
+
+func (x *CallMetadata) Reset() {
+	*x = CallMetadata{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CallMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallMetadata) ProtoMessage() {}
+
+func (x *CallMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallMetadata.ProtoReflect.Descriptor instead.
+func (*CallMetadata) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CallMetadata) GetEntries() []*MetadataEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *CallMetadata) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type MetadataEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key    string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Values [][]byte `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"` // binary for keys ending in -bin
+}
+
+func (x *MetadataEntry) Reset() {
+	*x = MetadataEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetadataEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataEntry) ProtoMessage() {}
+
+func (x *MetadataEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataEntry.ProtoReflect.Descriptor instead.
+func (*MetadataEntry) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MetadataEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MetadataEntry) GetValues() [][]byte {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// Pipe is the data of a PIPE frame, which opens a stage of a pipeline like
+// COMMAND does, except that the stage's stdout is sent on as the stdin of
+// the next stage, with MSG_STDIN and EOF_STDIN frames on its channel; only
+// EOF_STDOUT still comes back, once the stage is done writing.
+type Pipe struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Command string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Peer    string `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`       // the peer or pool running the next stage
+	Channel string `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"` // the channel of the next stage
+}
+
+func (x *Pipe) Reset() {
+	*x = Pipe{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Pipe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pipe) ProtoMessage() {}
+
+func (x *Pipe) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pipe.ProtoReflect.Descriptor instead.
+func (*Pipe) Descriptor() ([]byte, []int) {
+	return file_messages_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Pipe) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *Pipe) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *Pipe) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
 var File_messages_proto protoreflect.FileDescriptor
 
 var file_messages_proto_rawDesc = []byte{
 	0x0a, 0x0e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x22, 0x63, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73,
+	0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x63, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73,
 	0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x20, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x18,
@@ -330,16 +549,39 @@ var file_messages_proto_rawDesc = []byte{
 	0x20, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e,
 	0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x04, 0x66, 0x6c, 0x61,
 	0x67, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x04, 0x64, 0x61, 0x74, 0x61, 0x2a, 0x7b, 0x0a, 0x04, 0x46, 0x6c, 0x61, 0x67, 0x12, 0x08, 0x0a,
-	0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4f, 0x4d, 0x4d, 0x41,
-	0x4e, 0x44, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x4d, 0x53, 0x47, 0x5f, 0x53, 0x54, 0x44, 0x49,
-	0x4e, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x53, 0x47, 0x5f, 0x53, 0x54, 0x44, 0x4f, 0x55,
-	0x54, 0x10, 0x03, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x53, 0x47, 0x5f, 0x53, 0x54, 0x44, 0x45, 0x52,
-	0x52, 0x10, 0x04, 0x12, 0x0d, 0x0a, 0x09, 0x45, 0x4f, 0x46, 0x5f, 0x53, 0x54, 0x44, 0x49, 0x4e,
-	0x10, 0x05, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x4f, 0x46, 0x5f, 0x53, 0x54, 0x44, 0x4f, 0x55, 0x54,
-	0x10, 0x06, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x4f, 0x46, 0x5f, 0x53, 0x54, 0x44, 0x45, 0x52, 0x52,
-	0x10, 0x07, 0x42, 0x0b, 0x5a, 0x09, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2f, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x74, 0x0a, 0x0c, 0x43, 0x61, 0x6c, 0x6c, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x2f, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x39, 0x0a, 0x0d, 0x4d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x16,
+	0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x4e, 0x0a, 0x04, 0x50, 0x69, 0x70, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x65, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x65, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x2a, 0xf1, 0x01, 0x0a, 0x04, 0x46, 0x6c, 0x61, 0x67, 0x12,
+	0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4f, 0x4d,
+	0x4d, 0x41, 0x4e, 0x44, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x4d, 0x53, 0x47, 0x5f, 0x53, 0x54,
+	0x44, 0x49, 0x4e, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x53, 0x47, 0x5f, 0x53, 0x54, 0x44,
+	0x4f, 0x55, 0x54, 0x10, 0x03, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x53, 0x47, 0x5f, 0x53, 0x54, 0x44,
+	0x45, 0x52, 0x52, 0x10, 0x04, 0x12, 0x0d, 0x0a, 0x09, 0x45, 0x4f, 0x46, 0x5f, 0x53, 0x54, 0x44,
+	0x49, 0x4e, 0x10, 0x05, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x4f, 0x46, 0x5f, 0x53, 0x54, 0x44, 0x4f,
+	0x55, 0x54, 0x10, 0x06, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x4f, 0x46, 0x5f, 0x53, 0x54, 0x44, 0x45,
+	0x52, 0x52, 0x10, 0x07, 0x12, 0x08, 0x0a, 0x04, 0x45, 0x58, 0x49, 0x54, 0x10, 0x08, 0x12, 0x0b,
+	0x0a, 0x07, 0x53, 0x54, 0x41, 0x52, 0x54, 0x45, 0x44, 0x10, 0x09, 0x12, 0x0a, 0x0a, 0x06, 0x53,
+	0x49, 0x47, 0x4e, 0x41, 0x4c, 0x10, 0x0a, 0x12, 0x08, 0x0a, 0x04, 0x43, 0x41, 0x4c, 0x4c, 0x10,
+	0x0b, 0x12, 0x0c, 0x0a, 0x08, 0x54, 0x45, 0x52, 0x4d, 0x49, 0x4e, 0x41, 0x4c, 0x10, 0x0c, 0x12,
+	0x0a, 0x0a, 0x06, 0x52, 0x45, 0x53, 0x49, 0x5a, 0x45, 0x10, 0x0d, 0x12, 0x0a, 0x0a, 0x06, 0x48,
+	0x45, 0x41, 0x44, 0x45, 0x52, 0x10, 0x0e, 0x12, 0x0b, 0x0a, 0x07, 0x54, 0x52, 0x41, 0x49, 0x4c,
+	0x45, 0x52, 0x10, 0x0f, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x49, 0x50, 0x45, 0x10, 0x10, 0x12, 0x0a,
+	0x0a, 0x06, 0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x10, 0x11, 0x42, 0x0b, 0x5a, 0x09, 0x67, 0x72,
+	0x70, 0x63, 0x73, 0x68, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -355,22 +597,28 @@ func file_messages_proto_rawDescGZIP() []byte {
 }
 
 var file_messages_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_messages_proto_goTypes = []any{
-	(Flag)(0),           // 0: grpcsh.Flag
-	(*Message)(nil),     // 1: grpcsh.Message
-	(*Result)(nil),      // 2: grpcsh.Result
-	(*PeerMessage)(nil), // 3: grpcsh.PeerMessage
+	(Flag)(0),                   // 0: grpcsh.Flag
+	(*Message)(nil),             // 1: grpcsh.Message
+	(*Result)(nil),              // 2: grpcsh.Result
+	(*PeerMessage)(nil),         // 3: grpcsh.PeerMessage
+	(*CallMetadata)(nil),        // 4: grpcsh.CallMetadata
+	(*MetadataEntry)(nil),       // 5: grpcsh.MetadataEntry
+	(*Pipe)(nil),                // 6: grpcsh.Pipe
+	(*durationpb.Duration)(nil), // 7: google.protobuf.Duration
 }
 var file_messages_proto_depIdxs = []int32{
 	0, // 0: grpcsh.Message.flag:type_name -> grpcsh.Flag
 	0, // 1: grpcsh.Result.flag:type_name -> grpcsh.Flag
 	0, // 2: grpcsh.PeerMessage.flag:type_name -> grpcsh.Flag
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5, // 3: grpcsh.CallMetadata.entries:type_name -> grpcsh.MetadataEntry
+	7, // 4: grpcsh.CallMetadata.timeout:type_name -> google.protobuf.Duration
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_messages_proto_init() }
@@ -415,6 +663,42 @@ func file_messages_proto_init() {
 				return nil
 			}
 		}
+		file_messages_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*CallMetadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*MetadataEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Pipe); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -422,7 +706,7 @@ func file_messages_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_messages_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,406 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.29.3
+// source: pipeline_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PipelineRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stages []*PipelineStage `protobuf:"bytes,1,rep,name=stages,proto3" json:"stages,omitempty"`
+}
+
+func (x *PipelineRequest) Reset() {
+	*x = PipelineRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pipeline_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PipelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PipelineRequest) ProtoMessage() {}
+
+func (x *PipelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pipeline_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PipelineRequest.ProtoReflect.Descriptor instead.
+func (*PipelineRequest) Descriptor() ([]byte, []int) {
+	return file_pipeline_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PipelineRequest) GetStages() []*PipelineStage {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+type PipelineStage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Peer    string `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"` // a peer or pool
+	Command string `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+}
+
+func (x *PipelineStage) Reset() {
+	*x = PipelineStage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pipeline_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PipelineStage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PipelineStage) ProtoMessage() {}
+
+func (x *PipelineStage) ProtoReflect() protoreflect.Message {
+	mi := &file_pipeline_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PipelineStage.ProtoReflect.Descriptor instead.
+func (*PipelineStage) Descriptor() ([]byte, []int) {
+	return file_pipeline_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PipelineStage) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *PipelineStage) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+// The first input carries the request; the rest MSG_STDIN and EOF_STDIN
+// for the first stage, or SIGNAL for every stage still running.
+type PipelineInput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Request *PipelineRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	Flag    Flag             `protobuf:"varint,2,opt,name=flag,proto3,enum=grpcsh.Flag" json:"flag,omitempty"`
+	Data    []byte           `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *PipelineInput) Reset() {
+	*x = PipelineInput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pipeline_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PipelineInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PipelineInput) ProtoMessage() {}
+
+func (x *PipelineInput) ProtoReflect() protoreflect.Message {
+	mi := &file_pipeline_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PipelineInput.ProtoReflect.Descriptor instead.
+func (*PipelineInput) Descriptor() ([]byte, []int) {
+	return file_pipeline_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PipelineInput) GetRequest() *PipelineRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *PipelineInput) GetFlag() Flag {
+	if x != nil {
+		return x.Flag
+	}
+	return Flag_NONE
+}
+
+func (x *PipelineInput) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type PipelineEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stage int32  `protobuf:"varint,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	Peer  string `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	Flag  Flag   `protobuf:"varint,3,opt,name=flag,proto3,enum=grpcsh.Flag" json:"flag,omitempty"` // STARTED, MSG_STDOUT (last stage only), MSG_STDERR or EXIT
+	Data  []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *PipelineEvent) Reset() {
+	*x = PipelineEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pipeline_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PipelineEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PipelineEvent) ProtoMessage() {}
+
+func (x *PipelineEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pipeline_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PipelineEvent.ProtoReflect.Descriptor instead.
+func (*PipelineEvent) Descriptor() ([]byte, []int) {
+	return file_pipeline_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PipelineEvent) GetStage() int32 {
+	if x != nil {
+		return x.Stage
+	}
+	return 0
+}
+
+func (x *PipelineEvent) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *PipelineEvent) GetFlag() Flag {
+	if x != nil {
+		return x.Flag
+	}
+	return Flag_NONE
+}
+
+func (x *PipelineEvent) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_pipeline_service_proto protoreflect.FileDescriptor
+
+var file_pipeline_service_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x70, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68,
+	0x1a, 0x0e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0x40, 0x0a, 0x0f, 0x50, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x50, 0x69, 0x70,
+	0x65, 0x6c, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x67, 0x65, 0x52, 0x06, 0x73, 0x74, 0x61, 0x67,
+	0x65, 0x73, 0x22, 0x3d, 0x0a, 0x0d, 0x50, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x53, 0x74,
+	0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x65, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x65, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x22, 0x78, 0x0a, 0x0d, 0x50, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x50, 0x69, 0x70,
+	0x65, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x46, 0x6c, 0x61,
+	0x67, 0x52, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x6f, 0x0a, 0x0d, 0x50,
+	0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x74, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x74, 0x61,
+	0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x65, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x65, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x46, 0x6c,
+	0x61, 0x67, 0x52, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x32, 0x4a, 0x0a, 0x0f,
+	0x50, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x37, 0x0a, 0x03, 0x52, 0x75, 0x6e, 0x12, 0x15, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e,
+	0x50, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x1a, 0x15, 0x2e,
+	0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x50, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x28, 0x01, 0x30, 0x01, 0x42, 0x0b, 0x5a, 0x09, 0x67, 0x72, 0x70, 0x63,
+	0x73, 0x68, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pipeline_service_proto_rawDescOnce sync.Once
+	file_pipeline_service_proto_rawDescData = file_pipeline_service_proto_rawDesc
+)
+
+func file_pipeline_service_proto_rawDescGZIP() []byte {
+	file_pipeline_service_proto_rawDescOnce.Do(func() {
+		file_pipeline_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_pipeline_service_proto_rawDescData)
+	})
+	return file_pipeline_service_proto_rawDescData
+}
+
+var file_pipeline_service_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_pipeline_service_proto_goTypes = []any{
+	(*PipelineRequest)(nil), // 0: grpcsh.PipelineRequest
+	(*PipelineStage)(nil),   // 1: grpcsh.PipelineStage
+	(*PipelineInput)(nil),   // 2: grpcsh.PipelineInput
+	(*PipelineEvent)(nil),   // 3: grpcsh.PipelineEvent
+	(Flag)(0),               // 4: grpcsh.Flag
+}
+var file_pipeline_service_proto_depIdxs = []int32{
+	1, // 0: grpcsh.PipelineRequest.stages:type_name -> grpcsh.PipelineStage
+	0, // 1: grpcsh.PipelineInput.request:type_name -> grpcsh.PipelineRequest
+	4, // 2: grpcsh.PipelineInput.flag:type_name -> grpcsh.Flag
+	4, // 3: grpcsh.PipelineEvent.flag:type_name -> grpcsh.Flag
+	2, // 4: grpcsh.PipelineService.Run:input_type -> grpcsh.PipelineInput
+	3, // 5: grpcsh.PipelineService.Run:output_type -> grpcsh.PipelineEvent
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_pipeline_service_proto_init() }
+func file_pipeline_service_proto_init() {
+	if File_pipeline_service_proto != nil {
+		return
+	}
+	file_messages_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_pipeline_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*PipelineRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pipeline_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*PipelineStage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pipeline_service_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*PipelineInput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pipeline_service_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*PipelineEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pipeline_service_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pipeline_service_proto_goTypes,
+		DependencyIndexes: file_pipeline_service_proto_depIdxs,
+		MessageInfos:      file_pipeline_service_proto_msgTypes,
+	}.Build()
+	File_pipeline_service_proto = out.File
+	file_pipeline_service_proto_rawDesc = nil
+	file_pipeline_service_proto_goTypes = nil
+	file_pipeline_service_proto_depIdxs = nil
+}
@@ -0,0 +1,150 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: pipeline_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	PipelineService_Run_FullMethodName = "/grpcsh.PipelineService/Run"
+)
+
+// PipelineServiceClient is the client API for PipelineService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PipelineService runs a pipeline whose stages are on different peers: the
+// stdout of each stage goes straight to the stdin of the next over router
+// channels, and only the output of the last stage comes back.
+type PipelineServiceClient interface {
+	Run(ctx context.Context, opts ...grpc.CallOption) (PipelineService_RunClient, error)
+}
+
+type pipelineServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPipelineServiceClient(cc grpc.ClientConnInterface) PipelineServiceClient {
+	return &pipelineServiceClient{cc}
+}
+
+func (c *pipelineServiceClient) Run(ctx context.Context, opts ...grpc.CallOption) (PipelineService_RunClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PipelineService_ServiceDesc.Streams[0], PipelineService_Run_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pipelineServiceRunClient{ClientStream: stream}
+	return x, nil
+}
+
+type PipelineService_RunClient interface {
+	Send(*PipelineInput) error
+	Recv() (*PipelineEvent, error)
+	grpc.ClientStream
+}
+
+type pipelineServiceRunClient struct {
+	grpc.ClientStream
+}
+
+func (x *pipelineServiceRunClient) Send(m *PipelineInput) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *pipelineServiceRunClient) Recv() (*PipelineEvent, error) {
+	m := new(PipelineEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PipelineServiceServer is the server API for PipelineService service.
+// All implementations must embed UnimplementedPipelineServiceServer
+// for forward compatibility
+//
+// PipelineService runs a pipeline whose stages are on different peers: the
+// stdout of each stage goes straight to the stdin of the next over router
+// channels, and only the output of the last stage comes back.
+type PipelineServiceServer interface {
+	Run(PipelineService_RunServer) error
+	mustEmbedUnimplementedPipelineServiceServer()
+}
+
+// UnimplementedPipelineServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPipelineServiceServer struct {
+}
+
+func (UnimplementedPipelineServiceServer) Run(PipelineService_RunServer) error {
+	return status.Errorf(codes.Unimplemented, "method Run not implemented")
+}
+func (UnimplementedPipelineServiceServer) mustEmbedUnimplementedPipelineServiceServer() {}
+
+// UnsafePipelineServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PipelineServiceServer will
+// result in compilation errors.
+type UnsafePipelineServiceServer interface {
+	mustEmbedUnimplementedPipelineServiceServer()
+}
+
+func RegisterPipelineServiceServer(s grpc.ServiceRegistrar, srv PipelineServiceServer) {
+	s.RegisterService(&PipelineService_ServiceDesc, srv)
+}
+
+func _PipelineService_Run_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PipelineServiceServer).Run(&pipelineServiceRunServer{ServerStream: stream})
+}
+
+type PipelineService_RunServer interface {
+	Send(*PipelineEvent) error
+	Recv() (*PipelineInput, error)
+	grpc.ServerStream
+}
+
+type pipelineServiceRunServer struct {
+	grpc.ServerStream
+}
+
+func (x *pipelineServiceRunServer) Send(m *PipelineEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *pipelineServiceRunServer) Recv() (*PipelineInput, error) {
+	m := new(PipelineInput)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PipelineService_ServiceDesc is the grpc.ServiceDesc for PipelineService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PipelineService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.PipelineService",
+	HandlerType: (*PipelineServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Run",
+			Handler:       _PipelineService_Run_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pipeline_service.proto",
+}
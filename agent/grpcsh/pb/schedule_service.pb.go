@@ -0,0 +1,667 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.29.3
+// source: schedule_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// what to do about runs that were due while the router was down
+type MissedRunPolicy int32
+
+const (
+	MissedRunPolicy_SKIP_MISSED     MissedRunPolicy = 0 // record them as missed
+	MissedRunPolicy_RUN_MISSED_ONCE MissedRunPolicy = 1 // run once on startup, however many were missed
+)
+
+// Enum value maps for MissedRunPolicy.
+var (
+	MissedRunPolicy_name = map[int32]string{
+		0: "SKIP_MISSED",
+		1: "RUN_MISSED_ONCE",
+	}
+	MissedRunPolicy_value = map[string]int32{
+		"SKIP_MISSED":     0,
+		"RUN_MISSED_ONCE": 1,
+	}
+)
+
+func (x MissedRunPolicy) Enum() *MissedRunPolicy {
+	p := new(MissedRunPolicy)
+	*p = x
+	return p
+}
+
+func (x MissedRunPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MissedRunPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_schedule_service_proto_enumTypes[0].Descriptor()
+}
+
+func (MissedRunPolicy) Type() protoreflect.EnumType {
+	return &file_schedule_service_proto_enumTypes[0]
+}
+
+func (x MissedRunPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MissedRunPolicy.Descriptor instead.
+func (MissedRunPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_schedule_service_proto_rawDescGZIP(), []int{0}
+}
+
+type RunState int32
+
+const (
+	RunState_RUN_RUNNING RunState = 0
+	RunState_RUN_DONE    RunState = 1
+	RunState_RUN_FAILED  RunState = 2
+	RunState_RUN_SKIPPED RunState = 3 // the previous run was still going
+	RunState_RUN_MISSED  RunState = 4 // the router was down
+)
+
+// Enum value maps for RunState.
+var (
+	RunState_name = map[int32]string{
+		0: "RUN_RUNNING",
+		1: "RUN_DONE",
+		2: "RUN_FAILED",
+		3: "RUN_SKIPPED",
+		4: "RUN_MISSED",
+	}
+	RunState_value = map[string]int32{
+		"RUN_RUNNING": 0,
+		"RUN_DONE":    1,
+		"RUN_FAILED":  2,
+		"RUN_SKIPPED": 3,
+		"RUN_MISSED":  4,
+	}
+)
+
+func (x RunState) Enum() *RunState {
+	p := new(RunState)
+	*p = x
+	return p
+}
+
+func (x RunState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RunState) Descriptor() protoreflect.EnumDescriptor {
+	return file_schedule_service_proto_enumTypes[1].Descriptor()
+}
+
+func (RunState) Type() protoreflect.EnumType {
+	return &file_schedule_service_proto_enumTypes[1]
+}
+
+func (x RunState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RunState.Descriptor instead.
+func (RunState) EnumDescriptor() ([]byte, []int) {
+	return file_schedule_service_proto_rawDescGZIP(), []int{1}
+}
+
+type Schedule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name         string          `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Cron         string          `protobuf:"bytes,2,opt,name=cron,proto3" json:"cron,omitempty"` // e.g. "0 2 * * *" or "@hourly", in the router's local time
+	To           string          `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`     // peer or pool
+	Command      string          `protobuf:"bytes,4,opt,name=command,proto3" json:"command,omitempty"`
+	Missed       MissedRunPolicy `protobuf:"varint,5,opt,name=missed,proto3,enum=grpcsh.MissedRunPolicy" json:"missed,omitempty"`
+	AllowOverlap bool            `protobuf:"varint,6,opt,name=allow_overlap,json=allowOverlap,proto3" json:"allow_overlap,omitempty"` // start a run even if the previous one is still going
+	NextRun      int64           `protobuf:"varint,7,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`                // unix time; set by the router
+	Running      bool            `protobuf:"varint,8,opt,name=running,proto3" json:"running,omitempty"`                               // set by the router
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schedule_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_schedule_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_schedule_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Schedule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Schedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *Schedule) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Schedule) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *Schedule) GetMissed() MissedRunPolicy {
+	if x != nil {
+		return x.Missed
+	}
+	return MissedRunPolicy_SKIP_MISSED
+}
+
+func (x *Schedule) GetAllowOverlap() bool {
+	if x != nil {
+		return x.AllowOverlap
+	}
+	return false
+}
+
+func (x *Schedule) GetNextRun() int64 {
+	if x != nil {
+		return x.NextRun
+	}
+	return 0
+}
+
+func (x *Schedule) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+type ScheduleName struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *ScheduleName) Reset() {
+	*x = ScheduleName{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schedule_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleName) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleName) ProtoMessage() {}
+
+func (x *ScheduleName) ProtoReflect() protoreflect.Message {
+	mi := &file_schedule_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleName.ProtoReflect.Descriptor instead.
+func (*ScheduleName) Descriptor() ([]byte, []int) {
+	return file_schedule_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScheduleName) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ScheduleList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schedules []*Schedule `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+}
+
+func (x *ScheduleList) Reset() {
+	*x = ScheduleList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schedule_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleList) ProtoMessage() {}
+
+func (x *ScheduleList) ProtoReflect() protoreflect.Message {
+	mi := &file_schedule_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleList.ProtoReflect.Descriptor instead.
+func (*ScheduleList) Descriptor() ([]byte, []int) {
+	return file_schedule_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScheduleList) GetSchedules() []*Schedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type ScheduleRun struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Scheduled int64    `protobuf:"varint,1,opt,name=scheduled,proto3" json:"scheduled,omitempty"` // unix time the run was due
+	Started   int64    `protobuf:"varint,2,opt,name=started,proto3" json:"started,omitempty"`
+	Finished  int64    `protobuf:"varint,3,opt,name=finished,proto3" json:"finished,omitempty"`
+	State     RunState `protobuf:"varint,4,opt,name=state,proto3,enum=grpcsh.RunState" json:"state,omitempty"`
+	Peer      string   `protobuf:"bytes,5,opt,name=peer,proto3" json:"peer,omitempty"`
+	ExitCode  int32    `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Output    []byte   `protobuf:"bytes,7,opt,name=output,proto3" json:"output,omitempty"` // the tail of stdout and stderr
+}
+
+func (x *ScheduleRun) Reset() {
+	*x = ScheduleRun{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schedule_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleRun) ProtoMessage() {}
+
+func (x *ScheduleRun) ProtoReflect() protoreflect.Message {
+	mi := &file_schedule_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleRun.ProtoReflect.Descriptor instead.
+func (*ScheduleRun) Descriptor() ([]byte, []int) {
+	return file_schedule_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScheduleRun) GetScheduled() int64 {
+	if x != nil {
+		return x.Scheduled
+	}
+	return 0
+}
+
+func (x *ScheduleRun) GetStarted() int64 {
+	if x != nil {
+		return x.Started
+	}
+	return 0
+}
+
+func (x *ScheduleRun) GetFinished() int64 {
+	if x != nil {
+		return x.Finished
+	}
+	return 0
+}
+
+func (x *ScheduleRun) GetState() RunState {
+	if x != nil {
+		return x.State
+	}
+	return RunState_RUN_RUNNING
+}
+
+func (x *ScheduleRun) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *ScheduleRun) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ScheduleRun) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+type ScheduleHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Runs []*ScheduleRun `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+}
+
+func (x *ScheduleHistory) Reset() {
+	*x = ScheduleHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_schedule_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleHistory) ProtoMessage() {}
+
+func (x *ScheduleHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_schedule_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleHistory.ProtoReflect.Descriptor instead.
+func (*ScheduleHistory) Descriptor() ([]byte, []int) {
+	return file_schedule_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScheduleHistory) GetRuns() []*ScheduleRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+var File_schedule_service_proto protoreflect.FileDescriptor
+
+var file_schedule_service_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68,
+	0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xe7, 0x01,
+	0x0a, 0x08, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x63, 0x72, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x72,
+	0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x74, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x2f, 0x0a, 0x06,
+	0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x4d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x52, 0x75, 0x6e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x12, 0x23, 0x0a,
+	0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x6f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4f, 0x76, 0x65, 0x72, 0x6c,
+	0x61, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6e, 0x65, 0x78, 0x74, 0x52, 0x75, 0x6e, 0x12, 0x18, 0x0a,
+	0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x22, 0x22, 0x0a, 0x0c, 0x53, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x3e, 0x0a, 0x0c, 0x53,
+	0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x09, 0x73,
+	0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x22, 0xd2, 0x01, 0x0a, 0x0b,
+	0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x75, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x12,
+	0x26, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x52, 0x75, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x65, 0x65, 0x72, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x65, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x65,
+	0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x22, 0x3a, 0x0a, 0x0f, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x48, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x12, 0x27, 0x0a, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x52, 0x75, 0x6e, 0x52, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x2a, 0x37, 0x0a, 0x0f,
+	0x4d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x52, 0x75, 0x6e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12,
+	0x0f, 0x0a, 0x0b, 0x53, 0x4b, 0x49, 0x50, 0x5f, 0x4d, 0x49, 0x53, 0x53, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x13, 0x0a, 0x0f, 0x52, 0x55, 0x4e, 0x5f, 0x4d, 0x49, 0x53, 0x53, 0x45, 0x44, 0x5f, 0x4f,
+	0x4e, 0x43, 0x45, 0x10, 0x01, 0x2a, 0x5a, 0x0a, 0x08, 0x52, 0x75, 0x6e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x55, 0x4e, 0x5f, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47,
+	0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x55, 0x4e, 0x5f, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x01,
+	0x12, 0x0e, 0x0a, 0x0a, 0x52, 0x55, 0x4e, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x02,
+	0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x55, 0x4e, 0x5f, 0x53, 0x4b, 0x49, 0x50, 0x50, 0x45, 0x44, 0x10,
+	0x03, 0x12, 0x0e, 0x0a, 0x0a, 0x52, 0x55, 0x4e, 0x5f, 0x4d, 0x49, 0x53, 0x53, 0x45, 0x44, 0x10,
+	0x04, 0x32, 0xe7, 0x01, 0x0a, 0x0f, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12,
+	0x10, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x1a, 0x10, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x12, 0x36, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x14, 0x2e,
+	0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x34, 0x0a, 0x04, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x14, 0x2e, 0x67, 0x72,
+	0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x4c, 0x69, 0x73,
+	0x74, 0x12, 0x38, 0x0a, 0x07, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x14, 0x2e, 0x67,
+	0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x1a, 0x17, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x63, 0x68, 0x65,
+	0x64, 0x75, 0x6c, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x42, 0x0b, 0x5a, 0x09, 0x67,
+	0x72, 0x70, 0x63, 0x73, 0x68, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_schedule_service_proto_rawDescOnce sync.Once
+	file_schedule_service_proto_rawDescData = file_schedule_service_proto_rawDesc
+)
+
+func file_schedule_service_proto_rawDescGZIP() []byte {
+	file_schedule_service_proto_rawDescOnce.Do(func() {
+		file_schedule_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_schedule_service_proto_rawDescData)
+	})
+	return file_schedule_service_proto_rawDescData
+}
+
+var file_schedule_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_schedule_service_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_schedule_service_proto_goTypes = []any{
+	(MissedRunPolicy)(0),    // 0: grpcsh.MissedRunPolicy
+	(RunState)(0),           // 1: grpcsh.RunState
+	(*Schedule)(nil),        // 2: grpcsh.Schedule
+	(*ScheduleName)(nil),    // 3: grpcsh.ScheduleName
+	(*ScheduleList)(nil),    // 4: grpcsh.ScheduleList
+	(*ScheduleRun)(nil),     // 5: grpcsh.ScheduleRun
+	(*ScheduleHistory)(nil), // 6: grpcsh.ScheduleHistory
+	(*emptypb.Empty)(nil),   // 7: google.protobuf.Empty
+}
+var file_schedule_service_proto_depIdxs = []int32{
+	0, // 0: grpcsh.Schedule.missed:type_name -> grpcsh.MissedRunPolicy
+	2, // 1: grpcsh.ScheduleList.schedules:type_name -> grpcsh.Schedule
+	1, // 2: grpcsh.ScheduleRun.state:type_name -> grpcsh.RunState
+	5, // 3: grpcsh.ScheduleHistory.runs:type_name -> grpcsh.ScheduleRun
+	2, // 4: grpcsh.ScheduleService.Create:input_type -> grpcsh.Schedule
+	3, // 5: grpcsh.ScheduleService.Delete:input_type -> grpcsh.ScheduleName
+	7, // 6: grpcsh.ScheduleService.List:input_type -> google.protobuf.Empty
+	3, // 7: grpcsh.ScheduleService.History:input_type -> grpcsh.ScheduleName
+	2, // 8: grpcsh.ScheduleService.Create:output_type -> grpcsh.Schedule
+	7, // 9: grpcsh.ScheduleService.Delete:output_type -> google.protobuf.Empty
+	4, // 10: grpcsh.ScheduleService.List:output_type -> grpcsh.ScheduleList
+	6, // 11: grpcsh.ScheduleService.History:output_type -> grpcsh.ScheduleHistory
+	8, // [8:12] is the sub-list for method output_type
+	4, // [4:8] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_schedule_service_proto_init() }
+func file_schedule_service_proto_init() {
+	if File_schedule_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_schedule_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Schedule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schedule_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ScheduleName); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schedule_service_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ScheduleList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schedule_service_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ScheduleRun); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_schedule_service_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ScheduleHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_schedule_service_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_schedule_service_proto_goTypes,
+		DependencyIndexes: file_schedule_service_proto_depIdxs,
+		EnumInfos:         file_schedule_service_proto_enumTypes,
+		MessageInfos:      file_schedule_service_proto_msgTypes,
+	}.Build()
+	File_schedule_service_proto = out.File
+	file_schedule_service_proto_rawDesc = nil
+	file_schedule_service_proto_goTypes = nil
+	file_schedule_service_proto_depIdxs = nil
+}
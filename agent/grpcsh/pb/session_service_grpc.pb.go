@@ -0,0 +1,229 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: session_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	SessionService_Run_FullMethodName   = "/grpcsh.SessionService/Run"
+	SessionService_List_FullMethodName  = "/grpcsh.SessionService/List"
+	SessionService_Close_FullMethodName = "/grpcsh.SessionService/Close"
+)
+
+// SessionServiceClient is the client API for SessionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SessionService keeps named shells on the agent, so that the working
+// directory, variables and activated environments of one command carry
+// over to the next. Any client may run commands in a session by its name,
+// one at a time; a session lasts until it is closed or its shell exits.
+type SessionServiceClient interface {
+	// Run starts the session if there is none by that name. Cancelling the
+	// call interrupts the command, not the session.
+	Run(ctx context.Context, in *SessionCommand, opts ...grpc.CallOption) (SessionService_RunClient, error)
+	List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SessionList, error)
+	Close(ctx context.Context, in *SessionName, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type sessionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSessionServiceClient(cc grpc.ClientConnInterface) SessionServiceClient {
+	return &sessionServiceClient{cc}
+}
+
+func (c *sessionServiceClient) Run(ctx context.Context, in *SessionCommand, opts ...grpc.CallOption) (SessionService_RunClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SessionService_ServiceDesc.Streams[0], SessionService_Run_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sessionServiceRunClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SessionService_RunClient interface {
+	Recv() (*SessionOutput, error)
+	grpc.ClientStream
+}
+
+type sessionServiceRunClient struct {
+	grpc.ClientStream
+}
+
+func (x *sessionServiceRunClient) Recv() (*SessionOutput, error) {
+	m := new(SessionOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sessionServiceClient) List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SessionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionList)
+	err := c.cc.Invoke(ctx, SessionService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) Close(ctx context.Context, in *SessionName, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SessionService_Close_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SessionServiceServer is the server API for SessionService service.
+// All implementations must embed UnimplementedSessionServiceServer
+// for forward compatibility
+//
+// SessionService keeps named shells on the agent, so that the working
+// directory, variables and activated environments of one command carry
+// over to the next. Any client may run commands in a session by its name,
+// one at a time; a session lasts until it is closed or its shell exits.
+type SessionServiceServer interface {
+	// Run starts the session if there is none by that name. Cancelling the
+	// call interrupts the command, not the session.
+	Run(*SessionCommand, SessionService_RunServer) error
+	List(context.Context, *emptypb.Empty) (*SessionList, error)
+	Close(context.Context, *SessionName) (*emptypb.Empty, error)
+	mustEmbedUnimplementedSessionServiceServer()
+}
+
+// UnimplementedSessionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSessionServiceServer struct {
+}
+
+func (UnimplementedSessionServiceServer) Run(*SessionCommand, SessionService_RunServer) error {
+	return status.Errorf(codes.Unimplemented, "method Run not implemented")
+}
+func (UnimplementedSessionServiceServer) List(context.Context, *emptypb.Empty) (*SessionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedSessionServiceServer) Close(context.Context, *SessionName) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Close not implemented")
+}
+func (UnimplementedSessionServiceServer) mustEmbedUnimplementedSessionServiceServer() {}
+
+// UnsafeSessionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SessionServiceServer will
+// result in compilation errors.
+type UnsafeSessionServiceServer interface {
+	mustEmbedUnimplementedSessionServiceServer()
+}
+
+func RegisterSessionServiceServer(s grpc.ServiceRegistrar, srv SessionServiceServer) {
+	s.RegisterService(&SessionService_ServiceDesc, srv)
+}
+
+func _SessionService_Run_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SessionCommand)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SessionServiceServer).Run(m, &sessionServiceRunServer{ServerStream: stream})
+}
+
+type SessionService_RunServer interface {
+	Send(*SessionOutput) error
+	grpc.ServerStream
+}
+
+type sessionServiceRunServer struct {
+	grpc.ServerStream
+}
+
+func (x *sessionServiceRunServer) Send(m *SessionOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SessionService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).List(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_Close_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionName)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).Close(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_Close_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).Close(ctx, req.(*SessionName))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SessionService_ServiceDesc is the grpc.ServiceDesc for SessionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SessionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.SessionService",
+	HandlerType: (*SessionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _SessionService_List_Handler,
+		},
+		{
+			MethodName: "Close",
+			Handler:    _SessionService_Close_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Run",
+			Handler:       _SessionService_Run_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "session_service.proto",
+}
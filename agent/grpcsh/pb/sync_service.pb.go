@@ -0,0 +1,795 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.29.3
+// source: sync_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SyncMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Msg:
+	//	*SyncMessage_Start
+	//	*SyncMessage_Entry
+	//	*SyncMessage_Request
+	//	*SyncMessage_Data
+	//	*SyncMessage_Done
+	Msg isSyncMessage_Msg `protobuf_oneof:"msg"`
+}
+
+func (x *SyncMessage) Reset() {
+	*x = SyncMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sync_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncMessage) ProtoMessage() {}
+
+func (x *SyncMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncMessage.ProtoReflect.Descriptor instead.
+func (*SyncMessage) Descriptor() ([]byte, []int) {
+	return file_sync_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *SyncMessage) GetMsg() isSyncMessage_Msg {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (x *SyncMessage) GetStart() *SyncStart {
+	if x, ok := x.GetMsg().(*SyncMessage_Start); ok {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *SyncMessage) GetEntry() *SyncEntry {
+	if x, ok := x.GetMsg().(*SyncMessage_Entry); ok {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *SyncMessage) GetRequest() *SyncRequest {
+	if x, ok := x.GetMsg().(*SyncMessage_Request); ok {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *SyncMessage) GetData() *SyncData {
+	if x, ok := x.GetMsg().(*SyncMessage_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SyncMessage) GetDone() *SyncDone {
+	if x, ok := x.GetMsg().(*SyncMessage_Done); ok {
+		return x.Done
+	}
+	return nil
+}
+
+type isSyncMessage_Msg interface {
+	isSyncMessage_Msg()
+}
+
+type SyncMessage_Start struct {
+	Start *SyncStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type SyncMessage_Entry struct {
+	Entry *SyncEntry `protobuf:"bytes,2,opt,name=entry,proto3,oneof"`
+}
+
+type SyncMessage_Request struct {
+	Request *SyncRequest `protobuf:"bytes,3,opt,name=request,proto3,oneof"`
+}
+
+type SyncMessage_Data struct {
+	Data *SyncData `protobuf:"bytes,4,opt,name=data,proto3,oneof"`
+}
+
+type SyncMessage_Done struct {
+	Done *SyncDone `protobuf:"bytes,5,opt,name=done,proto3,oneof"` // ends each phase; from the receiver, it sums up the sync
+}
+
+func (*SyncMessage_Start) isSyncMessage_Msg() {}
+
+func (*SyncMessage_Entry) isSyncMessage_Msg() {}
+
+func (*SyncMessage_Request) isSyncMessage_Msg() {}
+
+func (*SyncMessage_Data) isSyncMessage_Msg() {}
+
+func (*SyncMessage_Done) isSyncMessage_Msg() {}
+
+type SyncStart struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path   string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`      // the directory on the agent
+	Delete bool   `protobuf:"varint,2,opt,name=delete,proto3" json:"delete,omitempty"` // remove files the sender does not have
+}
+
+func (x *SyncStart) Reset() {
+	*x = SyncStart{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sync_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStart) ProtoMessage() {}
+
+func (x *SyncStart) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStart.ProtoReflect.Descriptor instead.
+func (*SyncStart) Descriptor() ([]byte, []int) {
+	return file_sync_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SyncStart) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SyncStart) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+type SyncEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path  string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`  // slash-separated, relative to the directory
+	Mode  uint32 `protobuf:"varint,2,opt,name=mode,proto3" json:"mode,omitempty"` // Go file mode, including the type bits
+	Size  int64  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Mtime int64  `protobuf:"varint,4,opt,name=mtime,proto3" json:"mtime,omitempty"` // unix nanoseconds
+	Link  string `protobuf:"bytes,5,opt,name=link,proto3" json:"link,omitempty"`    // target of a symlink
+}
+
+func (x *SyncEntry) Reset() {
+	*x = SyncEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sync_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncEntry) ProtoMessage() {}
+
+func (x *SyncEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncEntry.ProtoReflect.Descriptor instead.
+func (*SyncEntry) Descriptor() ([]byte, []int) {
+	return file_sync_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SyncEntry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SyncEntry) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *SyncEntry) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SyncEntry) GetMtime() int64 {
+	if x != nil {
+		return x.Mtime
+	}
+	return 0
+}
+
+func (x *SyncEntry) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+// SyncRequest asks for a file. Blocks are the signatures of the receiver's
+// copy, if it has one, and may be split over several requests.
+type SyncRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path      string      `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	BlockSize int32       `protobuf:"varint,2,opt,name=block_size,json=blockSize,proto3" json:"block_size,omitempty"`
+	Blocks    []*BlockSum `protobuf:"bytes,3,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	More      bool        `protobuf:"varint,4,opt,name=more,proto3" json:"more,omitempty"` // more blocks follow in another request
+}
+
+func (x *SyncRequest) Reset() {
+	*x = SyncRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sync_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRequest) ProtoMessage() {}
+
+func (x *SyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return file_sync_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SyncRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SyncRequest) GetBlockSize() int32 {
+	if x != nil {
+		return x.BlockSize
+	}
+	return 0
+}
+
+func (x *SyncRequest) GetBlocks() []*BlockSum {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *SyncRequest) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+type BlockSum struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Weak   uint32 `protobuf:"varint,1,opt,name=weak,proto3" json:"weak,omitempty"`    // rolling checksum
+	Strong []byte `protobuf:"bytes,2,opt,name=strong,proto3" json:"strong,omitempty"` // md5
+}
+
+func (x *BlockSum) Reset() {
+	*x = BlockSum{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sync_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockSum) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockSum) ProtoMessage() {}
+
+func (x *BlockSum) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockSum.ProtoReflect.Descriptor instead.
+func (*BlockSum) Descriptor() ([]byte, []int) {
+	return file_sync_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BlockSum) GetWeak() uint32 {
+	if x != nil {
+		return x.Weak
+	}
+	return 0
+}
+
+func (x *BlockSum) GetStrong() []byte {
+	if x != nil {
+		return x.Strong
+	}
+	return nil
+}
+
+// SyncData carries either literal data or a range to copy from the
+// receiver's copy; the last message of a file carries its digest.
+type SyncData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path       string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Literal    []byte `protobuf:"bytes,2,opt,name=literal,proto3" json:"literal,omitempty"`
+	CopyOffset int64  `protobuf:"varint,3,opt,name=copy_offset,json=copyOffset,proto3" json:"copy_offset,omitempty"`
+	CopyLength int64  `protobuf:"varint,4,opt,name=copy_length,json=copyLength,proto3" json:"copy_length,omitempty"`
+	End        bool   `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	Sha256     string `protobuf:"bytes,6,opt,name=sha256,proto3" json:"sha256,omitempty"`
+}
+
+func (x *SyncData) Reset() {
+	*x = SyncData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sync_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncData) ProtoMessage() {}
+
+func (x *SyncData) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncData.ProtoReflect.Descriptor instead.
+func (*SyncData) Descriptor() ([]byte, []int) {
+	return file_sync_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SyncData) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SyncData) GetLiteral() []byte {
+	if x != nil {
+		return x.Literal
+	}
+	return nil
+}
+
+func (x *SyncData) GetCopyOffset() int64 {
+	if x != nil {
+		return x.CopyOffset
+	}
+	return 0
+}
+
+func (x *SyncData) GetCopyLength() int64 {
+	if x != nil {
+		return x.CopyLength
+	}
+	return 0
+}
+
+func (x *SyncData) GetEnd() bool {
+	if x != nil {
+		return x.End
+	}
+	return false
+}
+
+func (x *SyncData) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+type SyncDone struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Files        int32 `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"` // files transferred
+	LiteralBytes int64 `protobuf:"varint,2,opt,name=literal_bytes,json=literalBytes,proto3" json:"literal_bytes,omitempty"`
+	MatchedBytes int64 `protobuf:"varint,3,opt,name=matched_bytes,json=matchedBytes,proto3" json:"matched_bytes,omitempty"`
+	Deleted      int32 `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`
+}
+
+func (x *SyncDone) Reset() {
+	*x = SyncDone{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sync_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncDone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncDone) ProtoMessage() {}
+
+func (x *SyncDone) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncDone.ProtoReflect.Descriptor instead.
+func (*SyncDone) Descriptor() ([]byte, []int) {
+	return file_sync_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SyncDone) GetFiles() int32 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *SyncDone) GetLiteralBytes() int64 {
+	if x != nil {
+		return x.LiteralBytes
+	}
+	return 0
+}
+
+func (x *SyncDone) GetMatchedBytes() int64 {
+	if x != nil {
+		return x.MatchedBytes
+	}
+	return 0
+}
+
+func (x *SyncDone) GetDeleted() int32 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+var File_sync_service_proto protoreflect.FileDescriptor
+
+var file_sync_service_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x22, 0xeb, 0x01, 0x0a,
+	0x0b, 0x53, 0x79, 0x6e, 0x63, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x29, 0x0a, 0x05,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x67, 0x72,
+	0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x53, 0x74, 0x61, 0x72, 0x74, 0x48, 0x00,
+	0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x29, 0x0a, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e,
+	0x53, 0x79, 0x6e, 0x63, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x48, 0x00, 0x52, 0x05, 0x65, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x2f, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x79, 0x6e,
+	0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x44,
+	0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x26, 0x0a, 0x04, 0x64,
+	0x6f, 0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x73, 0x68, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x44, 0x6f, 0x6e, 0x65, 0x48, 0x00, 0x52, 0x04, 0x64,
+	0x6f, 0x6e, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x22, 0x37, 0x0a, 0x09, 0x53, 0x79,
+	0x6e, 0x63, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x22, 0x71, 0x0a, 0x09, 0x53, 0x79, 0x6e, 0x63, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x6d, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x74, 0x69,
+	0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x22, 0x7e, 0x0a, 0x0b, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x28, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73,
+	0x68, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x75, 0x6d, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x04, 0x6d, 0x6f, 0x72, 0x65, 0x22, 0x36, 0x0a, 0x08, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53,
+	0x75, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x77, 0x65, 0x61, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x04, 0x77, 0x65, 0x61, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x6f, 0x6e, 0x67,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x74, 0x72, 0x6f, 0x6e, 0x67, 0x22, 0xa4,
+	0x01, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x44, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12,
+	0x18, 0x0a, 0x07, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x70,
+	0x79, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a,
+	0x63, 0x6f, 0x70, 0x79, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f,
+	0x70, 0x79, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x63, 0x6f, 0x70, 0x79, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x65,
+	0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x68, 0x61, 0x32, 0x35, 0x36, 0x22, 0x84, 0x01, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x44, 0x6f,
+	0x6e, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x69, 0x74, 0x65,
+	0x72, 0x61, 0x6c, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0c, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x23, 0x0a,
+	0x0d, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x32, 0x79, 0x0a, 0x0b,
+	0x53, 0x79, 0x6e, 0x63, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x04, 0x50,
+	0x75, 0x73, 0x68, 0x12, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x79, 0x6e,
+	0x63, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73,
+	0x68, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x28, 0x01, 0x30,
+	0x01, 0x12, 0x34, 0x0a, 0x04, 0x50, 0x75, 0x6c, 0x6c, 0x12, 0x13, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x73, 0x68, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x13,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x0b, 0x5a, 0x09, 0x67, 0x72, 0x70, 0x63, 0x73,
+	0x68, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_sync_service_proto_rawDescOnce sync.Once
+	file_sync_service_proto_rawDescData = file_sync_service_proto_rawDesc
+)
+
+func file_sync_service_proto_rawDescGZIP() []byte {
+	file_sync_service_proto_rawDescOnce.Do(func() {
+		file_sync_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_sync_service_proto_rawDescData)
+	})
+	return file_sync_service_proto_rawDescData
+}
+
+var file_sync_service_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_sync_service_proto_goTypes = []any{
+	(*SyncMessage)(nil), // 0: grpcsh.SyncMessage
+	(*SyncStart)(nil),   // 1: grpcsh.SyncStart
+	(*SyncEntry)(nil),   // 2: grpcsh.SyncEntry
+	(*SyncRequest)(nil), // 3: grpcsh.SyncRequest
+	(*BlockSum)(nil),    // 4: grpcsh.BlockSum
+	(*SyncData)(nil),    // 5: grpcsh.SyncData
+	(*SyncDone)(nil),    // 6: grpcsh.SyncDone
+}
+var file_sync_service_proto_depIdxs = []int32{
+	1, // 0: grpcsh.SyncMessage.start:type_name -> grpcsh.SyncStart
+	2, // 1: grpcsh.SyncMessage.entry:type_name -> grpcsh.SyncEntry
+	3, // 2: grpcsh.SyncMessage.request:type_name -> grpcsh.SyncRequest
+	5, // 3: grpcsh.SyncMessage.data:type_name -> grpcsh.SyncData
+	6, // 4: grpcsh.SyncMessage.done:type_name -> grpcsh.SyncDone
+	4, // 5: grpcsh.SyncRequest.blocks:type_name -> grpcsh.BlockSum
+	0, // 6: grpcsh.SyncService.Push:input_type -> grpcsh.SyncMessage
+	0, // 7: grpcsh.SyncService.Pull:input_type -> grpcsh.SyncMessage
+	0, // 8: grpcsh.SyncService.Push:output_type -> grpcsh.SyncMessage
+	0, // 9: grpcsh.SyncService.Pull:output_type -> grpcsh.SyncMessage
+	8, // [8:10] is the sub-list for method output_type
+	6, // [6:8] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_sync_service_proto_init() }
+func file_sync_service_proto_init() {
+	if File_sync_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_sync_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*SyncMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sync_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*SyncStart); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sync_service_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*SyncEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sync_service_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*SyncRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sync_service_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*BlockSum); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sync_service_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*SyncData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sync_service_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*SyncDone); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_sync_service_proto_msgTypes[0].OneofWrappers = []any{
+		(*SyncMessage_Start)(nil),
+		(*SyncMessage_Entry)(nil),
+		(*SyncMessage_Request)(nil),
+		(*SyncMessage_Data)(nil),
+		(*SyncMessage_Done)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_sync_service_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sync_service_proto_goTypes,
+		DependencyIndexes: file_sync_service_proto_depIdxs,
+		MessageInfos:      file_sync_service_proto_msgTypes,
+	}.Build()
+	File_sync_service_proto = out.File
+	file_sync_service_proto_rawDesc = nil
+	file_sync_service_proto_goTypes = nil
+	file_sync_service_proto_depIdxs = nil
+}
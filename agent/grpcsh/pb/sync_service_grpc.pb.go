@@ -0,0 +1,222 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: sync_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	SyncService_Push_FullMethodName = "/grpcsh.SyncService/Push"
+	SyncService_Pull_FullMethodName = "/grpcsh.SyncService/Pull"
+)
+
+// SyncServiceClient is the client API for SyncService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SyncService brings a directory up to date with another copy of it. The
+// client starts with SyncStart; the sending side then lists its entries,
+// the receiving side asks for the files that differ, along with block
+// signatures of its own copy, and the sender answers with deltas.
+type SyncServiceClient interface {
+	Push(ctx context.Context, opts ...grpc.CallOption) (SyncService_PushClient, error)
+	Pull(ctx context.Context, opts ...grpc.CallOption) (SyncService_PullClient, error)
+}
+
+type syncServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSyncServiceClient(cc grpc.ClientConnInterface) SyncServiceClient {
+	return &syncServiceClient{cc}
+}
+
+func (c *syncServiceClient) Push(ctx context.Context, opts ...grpc.CallOption) (SyncService_PushClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SyncService_ServiceDesc.Streams[0], SyncService_Push_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncServicePushClient{ClientStream: stream}
+	return x, nil
+}
+
+type SyncService_PushClient interface {
+	Send(*SyncMessage) error
+	Recv() (*SyncMessage, error)
+	grpc.ClientStream
+}
+
+type syncServicePushClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncServicePushClient) Send(m *SyncMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *syncServicePushClient) Recv() (*SyncMessage, error) {
+	m := new(SyncMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncServiceClient) Pull(ctx context.Context, opts ...grpc.CallOption) (SyncService_PullClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SyncService_ServiceDesc.Streams[1], SyncService_Pull_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncServicePullClient{ClientStream: stream}
+	return x, nil
+}
+
+type SyncService_PullClient interface {
+	Send(*SyncMessage) error
+	Recv() (*SyncMessage, error)
+	grpc.ClientStream
+}
+
+type syncServicePullClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncServicePullClient) Send(m *SyncMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *syncServicePullClient) Recv() (*SyncMessage, error) {
+	m := new(SyncMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SyncServiceServer is the server API for SyncService service.
+// All implementations must embed UnimplementedSyncServiceServer
+// for forward compatibility
+//
+// SyncService brings a directory up to date with another copy of it. The
+// client starts with SyncStart; the sending side then lists its entries,
+// the receiving side asks for the files that differ, along with block
+// signatures of its own copy, and the sender answers with deltas.
+type SyncServiceServer interface {
+	Push(SyncService_PushServer) error
+	Pull(SyncService_PullServer) error
+	mustEmbedUnimplementedSyncServiceServer()
+}
+
+// UnimplementedSyncServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSyncServiceServer struct {
+}
+
+func (UnimplementedSyncServiceServer) Push(SyncService_PushServer) error {
+	return status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedSyncServiceServer) Pull(SyncService_PullServer) error {
+	return status.Errorf(codes.Unimplemented, "method Pull not implemented")
+}
+func (UnimplementedSyncServiceServer) mustEmbedUnimplementedSyncServiceServer() {}
+
+// UnsafeSyncServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SyncServiceServer will
+// result in compilation errors.
+type UnsafeSyncServiceServer interface {
+	mustEmbedUnimplementedSyncServiceServer()
+}
+
+func RegisterSyncServiceServer(s grpc.ServiceRegistrar, srv SyncServiceServer) {
+	s.RegisterService(&SyncService_ServiceDesc, srv)
+}
+
+func _SyncService_Push_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SyncServiceServer).Push(&syncServicePushServer{ServerStream: stream})
+}
+
+type SyncService_PushServer interface {
+	Send(*SyncMessage) error
+	Recv() (*SyncMessage, error)
+	grpc.ServerStream
+}
+
+type syncServicePushServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncServicePushServer) Send(m *SyncMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *syncServicePushServer) Recv() (*SyncMessage, error) {
+	m := new(SyncMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SyncService_Pull_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SyncServiceServer).Pull(&syncServicePullServer{ServerStream: stream})
+}
+
+type SyncService_PullServer interface {
+	Send(*SyncMessage) error
+	Recv() (*SyncMessage, error)
+	grpc.ServerStream
+}
+
+type syncServicePullServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncServicePullServer) Send(m *SyncMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *syncServicePullServer) Recv() (*SyncMessage, error) {
+	m := new(SyncMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SyncService_ServiceDesc is the grpc.ServiceDesc for SyncService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SyncService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.SyncService",
+	HandlerType: (*SyncServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Push",
+			Handler:       _SyncService_Push_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Pull",
+			Handler:       _SyncService_Pull_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "sync_service.proto",
+}
@@ -0,0 +1,450 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.29.3
+// source: tunnel_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ForwardEvent_Kind int32
+
+const (
+	ForwardEvent_LISTENING ForwardEvent_Kind = 0 // address is where the agent listens
+	ForwardEvent_OPENED    ForwardEvent_Kind = 1 // address is the connecting client, or the method of a gRPC call
+	ForwardEvent_CLOSED    ForwardEvent_Kind = 2 // address is the target, once known
+)
+
+// Enum value maps for ForwardEvent_Kind.
+var (
+	ForwardEvent_Kind_name = map[int32]string{
+		0: "LISTENING",
+		1: "OPENED",
+		2: "CLOSED",
+	}
+	ForwardEvent_Kind_value = map[string]int32{
+		"LISTENING": 0,
+		"OPENED":    1,
+		"CLOSED":    2,
+	}
+)
+
+func (x ForwardEvent_Kind) Enum() *ForwardEvent_Kind {
+	p := new(ForwardEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x ForwardEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ForwardEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_tunnel_service_proto_enumTypes[0].Descriptor()
+}
+
+func (ForwardEvent_Kind) Type() protoreflect.EnumType {
+	return &file_tunnel_service_proto_enumTypes[0]
+}
+
+func (x ForwardEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ForwardEvent_Kind.Descriptor instead.
+func (ForwardEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_tunnel_service_proto_rawDescGZIP(), []int{2, 0}
+}
+
+type TunnelData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"` // host:port, in the first message
+	Data    []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Eof     bool   `protobuf:"varint,3,opt,name=eof,proto3" json:"eof,omitempty"`
+}
+
+func (x *TunnelData) Reset() {
+	*x = TunnelData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tunnel_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TunnelData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TunnelData) ProtoMessage() {}
+
+func (x *TunnelData) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnel_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TunnelData.ProtoReflect.Descriptor instead.
+func (*TunnelData) Descriptor() ([]byte, []int) {
+	return file_tunnel_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TunnelData) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *TunnelData) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *TunnelData) GetEof() bool {
+	if x != nil {
+		return x.Eof
+	}
+	return false
+}
+
+type ForwardRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Listen  string `protobuf:"bytes,1,opt,name=listen,proto3" json:"listen,omitempty"`    // host:port to listen on
+	Peer    string `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`        // the peer that dials; defaults to the caller
+	Target  string `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`    // host:port the peer dials
+	Reverse bool   `protobuf:"varint,4,opt,name=reverse,proto3" json:"reverse,omitempty"` // listen on the peer, and dial the target from this agent
+	Socks   bool   `protobuf:"varint,5,opt,name=socks,proto3" json:"socks,omitempty"`     // take the target of each connection from its SOCKS5 request instead
+	Grpc    bool   `protobuf:"varint,6,opt,name=grpc,proto3" json:"grpc,omitempty"`       // serve gRPC, relaying each call to the gRPC server at the target
+}
+
+func (x *ForwardRequest) Reset() {
+	*x = ForwardRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tunnel_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardRequest) ProtoMessage() {}
+
+func (x *ForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnel_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardRequest.ProtoReflect.Descriptor instead.
+func (*ForwardRequest) Descriptor() ([]byte, []int) {
+	return file_tunnel_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ForwardRequest) GetListen() string {
+	if x != nil {
+		return x.Listen
+	}
+	return ""
+}
+
+func (x *ForwardRequest) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *ForwardRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ForwardRequest) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+func (x *ForwardRequest) GetSocks() bool {
+	if x != nil {
+		return x.Socks
+	}
+	return false
+}
+
+func (x *ForwardRequest) GetGrpc() bool {
+	if x != nil {
+		return x.Grpc
+	}
+	return false
+}
+
+type ForwardEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind       ForwardEvent_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=grpcsh.ForwardEvent_Kind" json:"kind,omitempty"`
+	Connection int64             `protobuf:"varint,2,opt,name=connection,proto3" json:"connection,omitempty"` // numbers the connections, from 1
+	Address    string            `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Sent       int64             `protobuf:"varint,4,opt,name=sent,proto3" json:"sent,omitempty"`         // bytes sent to the target
+	Received   int64             `protobuf:"varint,5,opt,name=received,proto3" json:"received,omitempty"` // bytes received from it
+	Error      string            `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`        // why the connection failed
+}
+
+func (x *ForwardEvent) Reset() {
+	*x = ForwardEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tunnel_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForwardEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardEvent) ProtoMessage() {}
+
+func (x *ForwardEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnel_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardEvent.ProtoReflect.Descriptor instead.
+func (*ForwardEvent) Descriptor() ([]byte, []int) {
+	return file_tunnel_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ForwardEvent) GetKind() ForwardEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return ForwardEvent_LISTENING
+}
+
+func (x *ForwardEvent) GetConnection() int64 {
+	if x != nil {
+		return x.Connection
+	}
+	return 0
+}
+
+func (x *ForwardEvent) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ForwardEvent) GetSent() int64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+func (x *ForwardEvent) GetReceived() int64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *ForwardEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_tunnel_service_proto protoreflect.FileDescriptor
+
+var file_tunnel_service_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x22, 0x4c,
+	0x0a, 0x0a, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x44, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f,
+	0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x65, 0x6f, 0x66, 0x22, 0x98, 0x01, 0x0a,
+	0x0e, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x65, 0x65, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x65, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x6f,
+	0x63, 0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x67, 0x72, 0x70, 0x63, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x04, 0x67, 0x72, 0x70, 0x63, 0x22, 0xec, 0x01, 0x0a, 0x0c, 0x46, 0x6f, 0x72, 0x77,
+	0x61, 0x72, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e,
+	0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x4b, 0x69, 0x6e,
+	0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x63, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x73, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x2d, 0x0a, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12,
+	0x0d, 0x0a, 0x09, 0x4c, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0a,
+	0x0a, 0x06, 0x4f, 0x50, 0x45, 0x4e, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x4c,
+	0x4f, 0x53, 0x45, 0x44, 0x10, 0x02, 0x32, 0x7e, 0x0a, 0x0d, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x04, 0x44, 0x69, 0x61, 0x6c, 0x12,
+	0x12, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x44,
+	0x61, 0x74, 0x61, 0x1a, 0x12, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x54, 0x75, 0x6e,
+	0x6e, 0x65, 0x6c, 0x44, 0x61, 0x74, 0x61, 0x28, 0x01, 0x30, 0x01, 0x12, 0x39, 0x0a, 0x07, 0x46,
+	0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x12, 0x16, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e,
+	0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
+	0x2e, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68, 0x2e, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x0b, 0x5a, 0x09, 0x67, 0x72, 0x70, 0x63, 0x73, 0x68,
+	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_tunnel_service_proto_rawDescOnce sync.Once
+	file_tunnel_service_proto_rawDescData = file_tunnel_service_proto_rawDesc
+)
+
+func file_tunnel_service_proto_rawDescGZIP() []byte {
+	file_tunnel_service_proto_rawDescOnce.Do(func() {
+		file_tunnel_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_tunnel_service_proto_rawDescData)
+	})
+	return file_tunnel_service_proto_rawDescData
+}
+
+var file_tunnel_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_tunnel_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_tunnel_service_proto_goTypes = []any{
+	(ForwardEvent_Kind)(0), // 0: grpcsh.ForwardEvent.Kind
+	(*TunnelData)(nil),     // 1: grpcsh.TunnelData
+	(*ForwardRequest)(nil), // 2: grpcsh.ForwardRequest
+	(*ForwardEvent)(nil),   // 3: grpcsh.ForwardEvent
+}
+var file_tunnel_service_proto_depIdxs = []int32{
+	0, // 0: grpcsh.ForwardEvent.kind:type_name -> grpcsh.ForwardEvent.Kind
+	1, // 1: grpcsh.TunnelService.Dial:input_type -> grpcsh.TunnelData
+	2, // 2: grpcsh.TunnelService.Forward:input_type -> grpcsh.ForwardRequest
+	1, // 3: grpcsh.TunnelService.Dial:output_type -> grpcsh.TunnelData
+	3, // 4: grpcsh.TunnelService.Forward:output_type -> grpcsh.ForwardEvent
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_tunnel_service_proto_init() }
+func file_tunnel_service_proto_init() {
+	if File_tunnel_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_tunnel_service_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*TunnelData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tunnel_service_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ForwardRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tunnel_service_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ForwardEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tunnel_service_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tunnel_service_proto_goTypes,
+		DependencyIndexes: file_tunnel_service_proto_depIdxs,
+		EnumInfos:         file_tunnel_service_proto_enumTypes,
+		MessageInfos:      file_tunnel_service_proto_msgTypes,
+	}.Build()
+	File_tunnel_service_proto = out.File
+	file_tunnel_service_proto_rawDesc = nil
+	file_tunnel_service_proto_goTypes = nil
+	file_tunnel_service_proto_depIdxs = nil
+}
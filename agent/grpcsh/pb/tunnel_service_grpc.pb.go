@@ -0,0 +1,229 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v5.29.3
+// source: tunnel_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	TunnelService_Dial_FullMethodName    = "/grpcsh.TunnelService/Dial"
+	TunnelService_Forward_FullMethodName = "/grpcsh.TunnelService/Forward"
+)
+
+// TunnelServiceClient is the client API for TunnelService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TunnelService carries TCP connections between peers. Dial, called with
+// the peer metadata, connects from that peer; Forward listens on a port
+// of this agent and dials a peer for each connection it accepts, or the
+// other way round, or relays the gRPC calls made to the port. Agents only
+// dial the addresses they allow for peers.
+type TunnelServiceClient interface {
+	// Dial connects to the address in the first message and relays the
+	// connection's bytes over the stream. The agent answers with an empty
+	// message once connected. Either side sends eof when its end of the
+	// connection stops sending; the stream ends when both have.
+	Dial(ctx context.Context, opts ...grpc.CallOption) (TunnelService_DialClient, error)
+	// Forward listens until the client goes away, reporting connections.
+	Forward(ctx context.Context, in *ForwardRequest, opts ...grpc.CallOption) (TunnelService_ForwardClient, error)
+}
+
+type tunnelServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTunnelServiceClient(cc grpc.ClientConnInterface) TunnelServiceClient {
+	return &tunnelServiceClient{cc}
+}
+
+func (c *tunnelServiceClient) Dial(ctx context.Context, opts ...grpc.CallOption) (TunnelService_DialClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TunnelService_ServiceDesc.Streams[0], TunnelService_Dial_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tunnelServiceDialClient{ClientStream: stream}
+	return x, nil
+}
+
+type TunnelService_DialClient interface {
+	Send(*TunnelData) error
+	Recv() (*TunnelData, error)
+	grpc.ClientStream
+}
+
+type tunnelServiceDialClient struct {
+	grpc.ClientStream
+}
+
+func (x *tunnelServiceDialClient) Send(m *TunnelData) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *tunnelServiceDialClient) Recv() (*TunnelData, error) {
+	m := new(TunnelData)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tunnelServiceClient) Forward(ctx context.Context, in *ForwardRequest, opts ...grpc.CallOption) (TunnelService_ForwardClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TunnelService_ServiceDesc.Streams[1], TunnelService_Forward_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tunnelServiceForwardClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TunnelService_ForwardClient interface {
+	Recv() (*ForwardEvent, error)
+	grpc.ClientStream
+}
+
+type tunnelServiceForwardClient struct {
+	grpc.ClientStream
+}
+
+func (x *tunnelServiceForwardClient) Recv() (*ForwardEvent, error) {
+	m := new(ForwardEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TunnelServiceServer is the server API for TunnelService service.
+// All implementations must embed UnimplementedTunnelServiceServer
+// for forward compatibility
+//
+// TunnelService carries TCP connections between peers. Dial, called with
+// the peer metadata, connects from that peer; Forward listens on a port
+// of this agent and dials a peer for each connection it accepts, or the
+// other way round, or relays the gRPC calls made to the port. Agents only
+// dial the addresses they allow for peers.
+type TunnelServiceServer interface {
+	// Dial connects to the address in the first message and relays the
+	// connection's bytes over the stream. The agent answers with an empty
+	// message once connected. Either side sends eof when its end of the
+	// connection stops sending; the stream ends when both have.
+	Dial(TunnelService_DialServer) error
+	// Forward listens until the client goes away, reporting connections.
+	Forward(*ForwardRequest, TunnelService_ForwardServer) error
+	mustEmbedUnimplementedTunnelServiceServer()
+}
+
+// UnimplementedTunnelServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedTunnelServiceServer struct {
+}
+
+func (UnimplementedTunnelServiceServer) Dial(TunnelService_DialServer) error {
+	return status.Errorf(codes.Unimplemented, "method Dial not implemented")
+}
+func (UnimplementedTunnelServiceServer) Forward(*ForwardRequest, TunnelService_ForwardServer) error {
+	return status.Errorf(codes.Unimplemented, "method Forward not implemented")
+}
+func (UnimplementedTunnelServiceServer) mustEmbedUnimplementedTunnelServiceServer() {}
+
+// UnsafeTunnelServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TunnelServiceServer will
+// result in compilation errors.
+type UnsafeTunnelServiceServer interface {
+	mustEmbedUnimplementedTunnelServiceServer()
+}
+
+func RegisterTunnelServiceServer(s grpc.ServiceRegistrar, srv TunnelServiceServer) {
+	s.RegisterService(&TunnelService_ServiceDesc, srv)
+}
+
+func _TunnelService_Dial_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TunnelServiceServer).Dial(&tunnelServiceDialServer{ServerStream: stream})
+}
+
+type TunnelService_DialServer interface {
+	Send(*TunnelData) error
+	Recv() (*TunnelData, error)
+	grpc.ServerStream
+}
+
+type tunnelServiceDialServer struct {
+	grpc.ServerStream
+}
+
+func (x *tunnelServiceDialServer) Send(m *TunnelData) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *tunnelServiceDialServer) Recv() (*TunnelData, error) {
+	m := new(TunnelData)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _TunnelService_Forward_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ForwardRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TunnelServiceServer).Forward(m, &tunnelServiceForwardServer{ServerStream: stream})
+}
+
+type TunnelService_ForwardServer interface {
+	Send(*ForwardEvent) error
+	grpc.ServerStream
+}
+
+type tunnelServiceForwardServer struct {
+	grpc.ServerStream
+}
+
+func (x *tunnelServiceForwardServer) Send(m *ForwardEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// TunnelService_ServiceDesc is the grpc.ServiceDesc for TunnelService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TunnelService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcsh.TunnelService",
+	HandlerType: (*TunnelServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Dial",
+			Handler:       _TunnelService_Dial_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Forward",
+			Handler:       _TunnelService_Forward_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tunnel_service.proto",
+}
@@ -4,12 +4,25 @@ import (
 	"flag"
 	router "grpcsh/router"
 	"log"
+	"os"
+	"strings"
 )
 
 func main() {
 
 	// argument parsing
 	routerUrl := flag.String("r", "localhost:50051", "Router URL")
+	poolConfig := flag.String("p", "", "Agent pool configuration (JSON)")
+	scheduleFile := flag.String("schedules", "", "File to keep schedules and their history in (JSON)")
+	httpAddr := flag.String("http", "", "Address to serve HTTP on, e.g. :8080, proxying /peers/<id>/port/<port>/ to peers (default: off)")
+	httpTokenFile := flag.String("http-token-file", "", "File holding the token HTTP clients must present; terminals and agents connecting over WebSockets need one (default: none required)")
+	httpCert := flag.String("http-tls-cert", "", "TLS certificate to serve HTTPS with, e.g. for agents connecting over wss:// (default: plain HTTP)")
+	httpKey := flag.String("http-tls-key", "", "TLS key of -http-tls-cert")
+	httpTerminalAssets := flag.String("http-terminal-assets", "", "Directory holding xterm.js, xterm.css and addon-fit.js for the /terminal/ page (default: no page)")
+	httpAllow := flag.String("http-allow", "", "Comma-separated peer:port patterns HTTP clients may reach, e.g. 'agent_*:8888' (default: any)")
+	sshAddr := flag.String("ssh", "", "Address to serve SSH on, e.g. :2222, where the username selects the peer (default: off)")
+	sshHostKey := flag.String("ssh-host-key", "grpcsh_router_host_key", "Private key of the SSH server; generated if missing")
+	sshAuthorizedKeys := flag.String("ssh-authorized-keys", os.ExpandEnv("$HOME/.ssh/authorized_keys"), "Public keys that may log in over SSH")
 	flag.Parse()
 
 	// validation
@@ -17,7 +30,43 @@ func main() {
 		log.Fatalf("Router URL must be provided using -r")
 	}
 
+	opts := router.Options{}
+	if *poolConfig != "" {
+		provisioner, err := router.LoadProvisioner(*poolConfig)
+		if err != nil {
+			log.Fatalf("Failed to load pool configuration: %v", err)
+		}
+		opts.Provisioner = provisioner
+	}
+	schedules, err := router.LoadSchedules(*scheduleFile)
+	if err != nil {
+		log.Fatalf("Failed to load schedules: %v", err)
+	}
+	opts.Schedules = schedules
+	if *httpAddr != "" {
+		opts.HTTP = &router.HTTPOptions{Addr: *httpAddr}
+		if *httpTokenFile != "" {
+			token, err := os.ReadFile(*httpTokenFile)
+			if err != nil {
+				log.Fatalf("Failed to read HTTP token: %v", err)
+			}
+			opts.HTTP.Token = strings.TrimSpace(string(token))
+		}
+		if *httpAllow != "" {
+			opts.HTTP.Allow = strings.Split(*httpAllow, ",")
+		}
+		if (*httpCert == "") != (*httpKey == "") {
+			log.Fatalf("-http-tls-cert and -http-tls-key must be given together")
+		}
+		opts.HTTP.CertFile, opts.HTTP.KeyFile = *httpCert, *httpKey
+		opts.HTTP.TerminalAssets = *httpTerminalAssets
+	}
+
+	if *sshAddr != "" {
+		opts.SSH = &router.SSHOptions{Addr: *sshAddr, HostKey: *sshHostKey, AuthorizedKeys: *sshAuthorizedKeys}
+	}
+
 	// logic
 	log.Println("Router URL:", *routerUrl)
-	router.Start(*routerUrl)
+	router.Start(*routerUrl, opts)
 }
@@ -0,0 +1,398 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	maxArrayTasks = 100000
+	// bytes of each output stream kept per task
+	taskOutputLimit = 256 * 1024
+	// bytes of output kept per array; beyond it, the output of the tasks
+	// that finished first is discarded
+	arrayOutputLimit = 64 * 1024 * 1024
+)
+
+var rangeSpec = regexp.MustCompile(`^(\d+)(?:-(\d+)(?::(\d+))?)?$`)
+
+// ArrayService runs a command template over every combination of a set of
+// parameters, spreading the tasks over peers and pools while capping how
+// many run at once. Arrays and the output of their tasks are kept in memory
+// for as long as the router runs, up to arrayOutputLimit of output each.
+type ArrayService struct {
+	pb.UnimplementedArrayServiceServer
+	router *RouterService
+	arrays map[string]*jobArray
+	mu     sync.RWMutex
+}
+
+type jobArray struct {
+	id          string
+	peers       []string
+	concurrency int
+	tasks       []*arrayTask
+	load        map[string]int // running tasks per peer
+	cancel      chan struct{}
+	cancelled   bool
+	finished    []*arrayTask // tasks whose output is kept, in the order they finished
+	kept        int          // bytes of output kept of finished tasks
+	mu          sync.Mutex
+}
+
+type arrayTask struct {
+	index  int
+	params map[string]string
+	script string
+	state  pb.TaskState
+	peer   string
+	code   int
+	stdout tailBuffer
+	stderr tailBuffer
+}
+
+// tailBuffer keeps the last bytes written to it, up to limit or else
+// taskOutputLimit.
+type tailBuffer struct {
+	data      []byte
+	limit     int
+	truncated bool
+	mu        sync.Mutex
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	limit := b.limit
+	if limit <= 0 {
+		limit = taskOutputLimit
+	}
+	b.data = append(b.data, p...)
+	if len(b.data) > limit {
+		b.data = append([]byte(nil), b.data[len(b.data)-limit:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// discard drops what the buffer keeps, and returns how many bytes that was.
+func (b *tailBuffer) discard() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(b.data)
+	b.data = nil
+	b.truncated = b.truncated || n > 0
+	return n
+}
+
+func (b *tailBuffer) size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.data)
+}
+
+func (b *tailBuffer) bytes() ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.data...), b.truncated
+}
+
+// parseRange expands "start-end" or "start-end:step"; a single number is a
+// range of one.
+func parseRange(spec string) ([]string, error) {
+	m := rangeSpec.FindStringSubmatch(spec)
+	if m == nil {
+		return nil, fmt.Errorf("invalid range: %q", spec)
+	}
+	start, _ := strconv.Atoi(m[1])
+	end, step := start, 1
+	if m[2] != "" {
+		end, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		step, _ = strconv.Atoi(m[3])
+	}
+	if end < start || step < 1 {
+		return nil, fmt.Errorf("invalid range: %q", spec)
+	}
+	if (end-start)/step >= maxArrayTasks {
+		return nil, fmt.Errorf("range too large: %q", spec)
+	}
+	var values []string
+	for i := start; i <= end; i += step {
+		values = append(values, strconv.Itoa(i))
+	}
+	return values, nil
+}
+
+// expandParams returns every combination of the parameter values, varying
+// the last parameter fastest.
+func expandParams(params []*pb.ArrayParam) ([]map[string]string, error) {
+	combos := []map[string]string{{}}
+	seen := map[string]bool{}
+	for _, p := range params {
+		if !envName.MatchString(p.Name) {
+			return nil, fmt.Errorf("invalid parameter name: %q", p.Name)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("duplicate parameter: %s", p.Name)
+		}
+		seen[p.Name] = true
+		values := append([]string(nil), p.Values...)
+		for _, spec := range p.Ranges {
+			r, err := parseRange(spec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, r...)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("parameter %s has no values", p.Name)
+		}
+		if len(combos)*len(values) > maxArrayTasks {
+			return nil, fmt.Errorf("more than %d tasks", maxArrayTasks)
+		}
+		next := make([]map[string]string, 0, len(combos)*len(values))
+		for _, combo := range combos {
+			for _, v := range values {
+				c := make(map[string]string, len(combo)+1)
+				for k, cv := range combo {
+					c[k] = cv
+				}
+				c[p.Name] = v
+				next = append(next, c)
+			}
+		}
+		combos = next
+	}
+	return combos, nil
+}
+
+// taskScript exports the parameters of a task and substitutes them into the
+// command template.
+func taskScript(id string, index int, command string, params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "export ARRAY_ID=%s ARRAY_TASK_ID=%d", id, index)
+	var pairs []string
+	for _, k := range names {
+		fmt.Fprintf(&b, " %s=%s", k, shellQuote(params[k]))
+		pairs = append(pairs, "{"+k+"}", shellQuote(params[k]))
+	}
+	b.WriteString("\n")
+	b.WriteString(strings.NewReplacer(pairs...).Replace(command))
+	return b.String()
+}
+
+func (a *ArrayService) Submit(ctx context.Context, req *pb.ArrayRequest) (*pb.ArrayStatus, error) {
+	if req.Command == "" {
+		return nil, fmt.Errorf("command must not be empty")
+	}
+	if len(req.Peers) == 0 {
+		return nil, fmt.Errorf("at least one peer is required")
+	}
+	combos, err := expandParams(req.Params)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := int(req.Concurrency)
+	if concurrency <= 0 {
+		concurrency = len(req.Peers)
+	}
+
+	a.mu.Lock()
+	id := fmt.Sprintf("array%d", len(a.arrays)+1)
+	arr := &jobArray{
+		id:          id,
+		peers:       req.Peers,
+		concurrency: concurrency,
+		load:        make(map[string]int),
+		cancel:      make(chan struct{}),
+	}
+	// running tasks may keep up to half of the array's output
+	limit := taskOutputLimit
+	if running := min(concurrency, len(combos)); arrayOutputLimit/(4*running) < limit {
+		limit = arrayOutputLimit / (4 * running)
+	}
+	for i, params := range combos {
+		t := &arrayTask{index: i, params: params, script: taskScript(id, i, req.Command, params)}
+		t.stdout.limit, t.stderr.limit = limit, limit
+		arr.tasks = append(arr.tasks, t)
+	}
+	a.arrays[id] = arr
+	a.mu.Unlock()
+
+	log.Printf("[Router] submitted %s: %d tasks on %s, %d at a time\n", id, len(arr.tasks), strings.Join(arr.peers, ","), concurrency)
+	go arr.run(a.router)
+	return arr.status(false), nil
+}
+
+func (a *ArrayService) Status(ctx context.Context, req *pb.ArrayId) (*pb.ArrayStatus, error) {
+	arr, err := a.get(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return arr.status(true), nil
+}
+
+func (a *ArrayService) Output(ctx context.Context, req *pb.TaskId) (*pb.TaskOutput, error) {
+	arr, err := a.get(req.Array)
+	if err != nil {
+		return nil, err
+	}
+	if req.Index < 0 || int(req.Index) >= len(arr.tasks) {
+		return nil, fmt.Errorf("%s has no task %d", arr.id, req.Index)
+	}
+	t := arr.tasks[req.Index]
+	arr.mu.Lock()
+	status := t.status()
+	arr.mu.Unlock()
+	stdout, outTruncated := t.stdout.bytes()
+	stderr, errTruncated := t.stderr.bytes()
+	return &pb.TaskOutput{Status: status, Stdout: stdout, Stderr: stderr, Truncated: outTruncated || errTruncated}, nil
+}
+
+// Cancel drops the pending tasks of an array and terminates the running ones.
+func (a *ArrayService) Cancel(ctx context.Context, req *pb.ArrayId) (*pb.ArrayStatus, error) {
+	arr, err := a.get(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	arr.mu.Lock()
+	if !arr.cancelled {
+		log.Printf("[Router] cancelling %s\n", arr.id)
+		arr.cancelled = true
+		close(arr.cancel)
+	}
+	arr.mu.Unlock()
+	return arr.status(false), nil
+}
+
+func (a *ArrayService) get(id string) (*jobArray, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	arr, exists := a.arrays[id]
+	if !exists {
+		return nil, fmt.Errorf("unknown array: %s", id)
+	}
+	return arr, nil
+}
+
+func (arr *jobArray) run(s *RouterService) {
+	slots := make(chan struct{}, arr.concurrency)
+	var wg sync.WaitGroup
+	for _, t := range arr.tasks {
+		select {
+		case slots <- struct{}{}:
+		case <-arr.cancel:
+		}
+		arr.mu.Lock()
+		if arr.cancelled {
+			t.state = pb.TaskState_CANCELLED
+			arr.mu.Unlock()
+			continue
+		}
+		t.peer = arr.pick()
+		t.state = pb.TaskState_RUNNING
+		arr.load[t.peer]++
+		arr.mu.Unlock()
+
+		wg.Add(1)
+		go func(t *arrayTask, to string) {
+			defer wg.Done()
+			code := arr.runTask(s, t, to)
+			arr.mu.Lock()
+			arr.load[to]--
+			t.code = code
+			switch {
+			case code == 0:
+				t.state = pb.TaskState_DONE
+			case arr.cancelled:
+				t.state = pb.TaskState_CANCELLED
+			default:
+				t.state = pb.TaskState_FAILED
+			}
+			arr.keep(t)
+			arr.mu.Unlock()
+			<-slots
+		}(t, t.peer)
+	}
+	wg.Wait()
+	status := arr.status(false)
+	log.Printf("[Router] %s finished: done=%d, failed=%d, cancelled=%d\n", arr.id, status.Done, status.Failed, status.Cancelled)
+}
+
+func (arr *jobArray) runTask(s *RouterService, t *arrayTask, to string) int {
+	e, err := s.exec(to, t.script)
+	if err != nil {
+		fmt.Fprintf(&t.stderr, "%s\n", err)
+		return 255
+	}
+	defer e.close()
+	e.send(pb.Flag_EOF_STDIN, nil)
+	return e.wait(&t.stdout, &t.stderr, arr.cancel)
+}
+
+// keep accounts for the output of a finished task, discarding that of the
+// tasks that finished first while the array keeps more than
+// arrayOutputLimit; arr.mu must be held.
+func (arr *jobArray) keep(t *arrayTask) {
+	arr.finished = append(arr.finished, t)
+	arr.kept += t.stdout.size() + t.stderr.size()
+	for arr.kept > arrayOutputLimit/2 && len(arr.finished) > 0 {
+		old := arr.finished[0]
+		arr.finished = arr.finished[1:]
+		arr.kept -= old.stdout.discard() + old.stderr.discard()
+	}
+}
+
+// pick returns the peer running the fewest tasks of the array.
+func (arr *jobArray) pick() string {
+	best := arr.peers[0]
+	for _, p := range arr.peers[1:] {
+		if arr.load[p] < arr.load[best] {
+			best = p
+		}
+	}
+	return best
+}
+
+func (t *arrayTask) status() *pb.TaskStatus {
+	return &pb.TaskStatus{Index: int32(t.index), State: t.state, Peer: t.peer, ExitCode: int32(t.code), Params: t.params}
+}
+
+// status counts the tasks in each state, listing them if asked to.
+func (arr *jobArray) status(tasks bool) *pb.ArrayStatus {
+	arr.mu.Lock()
+	defer arr.mu.Unlock()
+	status := &pb.ArrayStatus{Id: arr.id}
+	for _, t := range arr.tasks {
+		switch t.state {
+		case pb.TaskState_PENDING:
+			status.Pending++
+		case pb.TaskState_RUNNING:
+			status.Running++
+		case pb.TaskState_DONE:
+			status.Done++
+		case pb.TaskState_FAILED:
+			status.Failed++
+		case pb.TaskState_CANCELLED:
+			status.Cancelled++
+		}
+		if tasks {
+			status.Tasks = append(status.Tasks, t.status())
+		}
+	}
+	return status
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	pb "grpcsh/pb"
+	"reflect"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string // nil for an error
+	}{
+		{"3", []string{"3"}},
+		{"1-4", []string{"1", "2", "3", "4"}},
+		{"0-10:5", []string{"0", "5", "10"}},
+		{"1-6:4", []string{"1", "5"}},
+		{"4-1", nil},
+		{"1-4:0", nil},
+		{"-1", nil},
+		{"a-b", nil},
+		{"1-4:", nil},
+		{"0-100000", nil},
+	}
+	for _, test := range tests {
+		got, err := parseRange(test.spec)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("parseRange(%q) = %v, want an error", test.spec, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseRange(%q) = %v, %v; want %v", test.spec, got, err, test.want)
+		}
+	}
+}
+
+func TestExpandParams(t *testing.T) {
+	got, err := expandParams([]*pb.ArrayParam{
+		{Name: "A", Values: []string{"x", "y"}},
+		{Name: "N", Values: []string{"0"}, Ranges: []string{"1-2"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"A": "x", "N": "0"}, {"A": "x", "N": "1"}, {"A": "x", "N": "2"},
+		{"A": "y", "N": "0"}, {"A": "y", "N": "1"}, {"A": "y", "N": "2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandParams = %v, want %v", got, want)
+	}
+
+	bad := [][]*pb.ArrayParam{
+		{{Name: "1A", Values: []string{"x"}}},
+		{{Name: "A", Values: []string{"x"}}, {Name: "A", Values: []string{"y"}}},
+		{{Name: "A"}},
+		{{Name: "A", Ranges: []string{"2-1"}}},
+		{{Name: "A", Ranges: []string{"1-1000"}}, {Name: "B", Ranges: []string{"1-1000"}}},
+	}
+	for _, params := range bad {
+		if _, err := expandParams(params); err == nil {
+			t.Errorf("expandParams(%v) succeeded, want an error", params)
+		}
+	}
+}
+
+func TestTaskScript(t *testing.T) {
+	got := taskScript("a1", 3, "run {IN} > {OUT}.log", map[string]string{"OUT": "r 1", "IN": "it's"})
+	want := "export ARRAY_ID=a1 ARRAY_TASK_ID=3 IN='it'\\''s' OUT='r 1'\nrun 'it'\\''s' > 'r 1'.log"
+	if got != want {
+		t.Errorf("taskScript = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+package router
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr   string
+		ok     bool
+		minute uint64
+		dow    uint64
+	}{
+		{"0 2 * * *", true, 1, 0x7f | 1<<7},
+		{"@hourly", true, 1, 0x7f | 1<<7},
+		{"*/15 * * * *", true, 1 | 1<<15 | 1<<30 | 1<<45, 0x7f | 1<<7},
+		{"5,10-12 * * * mon-fri", true, 1<<5 | 1<<10 | 1<<11 | 1<<12, 0x3e},
+		{"0 0 * * 7", true, 1, 1 | 1<<7},
+		{"0 0 * * SUN", true, 1, 1},
+		{"0 0 * *", false, 0, 0},
+		{"60 * * * *", false, 0, 0},
+		{"* * 0 * *", false, 0, 0},
+		{"* * * 13 *", false, 0, 0},
+		{"*/0 * * * *", false, 0, 0},
+		{"5-1 * * * *", false, 0, 0},
+		{"x * * * *", false, 0, 0},
+	}
+	for _, test := range tests {
+		c, err := parseCron(test.expr)
+		if (err == nil) != test.ok {
+			t.Errorf("parseCron(%q): error %v, want ok=%v", test.expr, err, test.ok)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if c.minute != test.minute || c.dow != test.dow {
+			t.Errorf("parseCron(%q): minute %b, dow %b; want %b, %b", test.expr, c.minute, c.dow, test.minute, test.dow)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		expr string
+		from string
+		want string // "" for no match
+	}{
+		{"0 2 * * *", "2024-03-10 01:59", "2024-03-10 02:00"},
+		{"0 2 * * *", "2024-03-10 02:00", "2024-03-11 02:00"},
+		{"*/15 * * * *", "2024-03-10 10:07", "2024-03-10 10:15"},
+		{"@monthly", "2024-12-15 00:00", "2025-01-01 00:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"0 0 30 2 *", "2024-03-01 00:00", ""},
+		// 2024-03-10 is a Sunday
+		{"30 9 * * mon-fri", "2024-03-10 12:00", "2024-03-11 09:30"},
+		{"0 0 * * 7", "2024-03-10 00:00", "2024-03-17 00:00"},
+		// both day fields restricted: either one matches
+		{"0 0 15 * fri", "2024-03-10 00:00", "2024-03-15 00:00"},
+		{"0 0 13 * mon", "2024-03-10 00:00", "2024-03-11 00:00"},
+		// a day field with a step is unrestricted, so both must match
+		{"0 0 */2 * mon", "2024-03-12 00:00", "2024-03-25 00:00"},
+		{"0 0 13 * */2", "2024-03-10 00:00", "2024-04-13 00:00"},
+	}
+	for _, test := range tests {
+		c, err := parseCron(test.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %s", test.expr, err)
+		}
+		got := c.next(at(test.from))
+		var want time.Time
+		if test.want != "" {
+			want = at(test.want)
+		}
+		if !got.Equal(want) {
+			t.Errorf("%q after %s: got %s, want %s", test.expr, test.from, got, want)
+		}
+	}
+}
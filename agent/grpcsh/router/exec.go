@@ -0,0 +1,317 @@
+package router
+
+import (
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RouterId is the peer ID under which the router itself opens channels,
+// e.g. to run commands on agents on behalf of a client.
+const RouterId = "@router"
+
+// bytes of each direction of a channel a peer may send ahead of the WINDOW
+// frames the receiver sends back once it consumed them; must match the
+// agents' window
+const channelWindow = 8 * 1024 * 1024
+
+// an execution whose peer runs this many windows ahead of its consumer is
+// ended, rather than buffered without bound
+const channelOverrun = 4
+
+// execution is a command the router runs on a peer. Frames sent back by
+// the peer arrive on frames, up to and including EXIT. They are queued
+// until the consumer takes them, so that a slow consumer holds up neither
+// the peer's other channels nor the router; the peer's window bounds the
+// queue.
+type execution struct {
+	s       *RouterService
+	channel string
+	to      string // the peer or pool the command was sent to
+	peer    string // the peer running it, once known
+	frames  chan *pb.PeerMessage
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+
+	queue    []*pb.PeerMessage
+	queued   int64            // data bytes in queue
+	inflight *pb.PeerMessage  // taken from queue, not yet consumed
+	overrun  bool             // the peer ignored its window; its frames are dropped
+	credits  map[string]int64 // by direction, data bytes that may still be sent
+	owed     map[string]int64 // by sender, data bytes consumed and not yet credited
+	closed   bool
+	cond     *sync.Cond
+}
+
+// direction returns the direction of a data frame, or "" for other frames.
+func direction(flag pb.Flag) string {
+	switch flag {
+	case pb.Flag_MSG_STDIN:
+		return "in"
+	case pb.Flag_MSG_STDOUT, pb.Flag_MSG_STDERR:
+		return "out"
+	}
+	return ""
+}
+
+func windowFrame(channel string, to string, dir string, n int64) *pb.PeerMessage {
+	return &pb.PeerMessage{Channel: channel, From: RouterId, To: to, Flag: pb.Flag_WINDOW, Data: []byte(fmt.Sprintf("%s %d", dir, n))}
+}
+
+func parseWindow(data []byte) (string, int64, error) {
+	dir, count, _ := strings.Cut(string(data), " ")
+	n, err := strconv.ParseInt(count, 10, 64)
+	if err != nil || (dir != "in" && dir != "out") {
+		return "", 0, fmt.Errorf("bad window: %q", data)
+	}
+	return dir, n, nil
+}
+
+// exec sends a command to a peer or pool over a new channel.
+func (s *RouterService) exec(to string, script string) (*execution, error) {
+	return s.open(to, pb.Flag_COMMAND, []byte(script))
+}
+
+// open opens a new channel to a peer or pool with a COMMAND or CALL frame.
+func (s *RouterService) open(to string, flag pb.Flag, data []byte) (*execution, error) {
+	e := &execution{
+		s:       s,
+		channel: s.channels.create(),
+		to:      to,
+		frames:  make(chan *pb.PeerMessage),
+		done:    make(chan struct{}),
+		credits: map[string]int64{"in": channelWindow, "out": channelWindow},
+		owed:    make(map[string]int64),
+	}
+	e.cond = sync.NewCond(&e.mu)
+	s.mu.Lock()
+	s.executions[e.channel] = e
+	s.mu.Unlock()
+	go e.pump()
+	if err := e.send(flag, data); err != nil {
+		e.close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// send writes a frame to the peer running the command, waiting for credit
+// to send data.
+func (e *execution) send(flag pb.Flag, data []byte) error {
+	if dir := direction(flag); dir != "" {
+		e.mu.Lock()
+		for e.credits[dir] <= 0 && !e.closed {
+			e.cond.Wait()
+		}
+		closed := e.closed
+		e.credits[dir] -= int64(len(data))
+		e.mu.Unlock()
+		if closed {
+			return fmt.Errorf("channel %s is closed", e.channel)
+		}
+	}
+	msg := &pb.PeerMessage{Channel: e.channel, From: RouterId, To: e.to, Flag: flag, Data: data}
+	if err := e.s.route(msg); err != nil {
+		return err
+	}
+	// pools resolve to a peer on the first frame that is not queued
+	e.mu.Lock()
+	if e.peer == "" && msg.To != e.to {
+		e.peer = msg.To
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// wait copies the output of the command until it exits and returns its
+// exit code. Closing cancel sends the command SIGTERM.
+func (e *execution) wait(stdout, stderr io.Writer, cancel <-chan struct{}) int {
+	for {
+		select {
+		case msg := <-e.frames:
+			switch msg.Flag {
+			case pb.Flag_MSG_STDOUT:
+				stdout.Write(msg.Data)
+			case pb.Flag_MSG_STDERR:
+				stderr.Write(msg.Data)
+			case pb.Flag_EXIT:
+				code, err := strconv.Atoi(string(msg.Data))
+				if err != nil {
+					return 255
+				}
+				return code
+			}
+		case <-cancel:
+			e.send(pb.Flag_SIGNAL, []byte("TERM"))
+			cancel = nil
+		}
+	}
+}
+
+// pump hands queued frames to the consumer, crediting their sender as
+// the consumer takes them.
+func (e *execution) pump() {
+	for {
+		e.mu.Lock()
+		for len(e.queue) == 0 && !e.closed {
+			e.cond.Wait()
+		}
+		if e.closed {
+			e.mu.Unlock()
+			return
+		}
+		msg := e.queue[0]
+		e.queue = e.queue[1:]
+		e.inflight = msg
+		e.mu.Unlock()
+		select {
+		case e.frames <- msg:
+		case <-e.done:
+			return
+		}
+		if grant := e.consumed(msg); grant != nil {
+			e.s.route(grant)
+		}
+	}
+}
+
+// close stops delivery; frames that still arrive are dropped. What was
+// received but never consumed is credited to its sender.
+func (e *execution) close() {
+	e.once.Do(func() {
+		e.s.mu.Lock()
+		delete(e.s.executions, e.channel)
+		e.s.mu.Unlock()
+		e.mu.Lock()
+		unconsumed := e.queue
+		if e.inflight != nil {
+			unconsumed = append(unconsumed, e.inflight)
+		}
+		for _, msg := range unconsumed {
+			if direction(msg.Flag) == "out" {
+				e.owed[msg.From] += int64(len(msg.Data))
+			}
+		}
+		owed := e.owed
+		e.closed = true
+		e.queue = nil
+		e.inflight = nil
+		e.owed = nil
+		e.cond.Broadcast()
+		e.mu.Unlock()
+		close(e.done)
+		for from, n := range owed {
+			e.s.route(windowFrame(e.channel, from, "out", n))
+		}
+	})
+}
+
+// deliver queues a frame addressed to the router for its execution; it
+// never waits for the execution's consumer.
+func (s *RouterService) deliver(msg *pb.PeerMessage) {
+	s.mu.RLock()
+	e, exists := s.executions[msg.Channel]
+	s.mu.RUnlock()
+	if exists && msg.Flag == pb.Flag_WINDOW {
+		e.credit(msg)
+		return
+	}
+	if !exists || !e.push(msg) {
+		log.Printf("[Router] dropping %s for closed channel %s\n", msg.Flag, msg.Channel)
+		// dropped data is credited at once, so its sender is not held up
+		if dir := direction(msg.Flag); dir != "" {
+			s.route(windowFrame(msg.Channel, msg.From, dir, int64(len(msg.Data))))
+		}
+	}
+}
+
+// push queues a frame for the consumer, and reports false if the execution
+// is closed. A peer that runs too far ahead of the consumer is killed, and
+// the execution ended.
+func (e *execution) push(msg *pb.PeerMessage) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.peer == "" {
+		e.peer = msg.From
+	}
+	if e.closed {
+		return false
+	}
+	if e.overrun {
+		return true
+	}
+	if direction(msg.Flag) == "out" {
+		e.queued += int64(len(msg.Data))
+		if e.queued > channelOverrun*channelWindow {
+			log.Printf("[Router] ending channel %s, whose peer %s overran its window\n", e.channel, msg.From)
+			e.overrun = true
+			e.queue = []*pb.PeerMessage{
+				{Channel: e.channel, From: msg.From, To: RouterId, Flag: pb.Flag_MSG_STDERR, Data: []byte(fmt.Sprintf("peer %s overran its window\n", msg.From))},
+				{Channel: e.channel, From: msg.From, To: RouterId, Flag: pb.Flag_EXIT, Data: []byte("255")},
+			}
+			e.cond.Broadcast()
+			go e.s.route(&pb.PeerMessage{Channel: e.channel, From: RouterId, To: msg.From, Flag: pb.Flag_SIGNAL, Data: []byte("KILL")})
+			return true
+		}
+	}
+	e.queue = append(e.queue, msg)
+	e.cond.Broadcast()
+	return true
+}
+
+// consumed accounts for a frame the consumer took, and returns the WINDOW
+// frame crediting its sender once a quarter of a window is owed.
+func (e *execution) consumed(msg *pb.PeerMessage) *pb.PeerMessage {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.inflight = nil
+	if direction(msg.Flag) != "out" || e.closed {
+		return nil
+	}
+	n := int64(len(msg.Data))
+	e.queued -= n
+	e.owed[msg.From] += n
+	n = e.owed[msg.From]
+	if n < channelWindow/4 {
+		return nil
+	}
+	delete(e.owed, msg.From)
+	return windowFrame(e.channel, msg.From, "out", n)
+}
+
+// credit adds the credit of a WINDOW frame for the data sent on the channel.
+func (e *execution) credit(msg *pb.PeerMessage) {
+	dir, n, err := parseWindow(msg.Data)
+	if err != nil {
+		log.Printf("[Router] dropping window from %s: %s\n", msg.From, err)
+		return
+	}
+	e.mu.Lock()
+	e.credits[dir] += n
+	e.cond.Broadcast()
+	e.mu.Unlock()
+}
+
+// lost ends the executions of a peer that disconnected with exit code 255.
+func (s *RouterService) lost(peerId string) {
+	s.mu.RLock()
+	var lost []*execution
+	for _, e := range s.executions {
+		e.mu.Lock()
+		if e.peer == peerId || (e.peer == "" && e.to == peerId) {
+			lost = append(lost, e)
+		}
+		e.mu.Unlock()
+	}
+	s.mu.RUnlock()
+	for _, e := range lost {
+		msg := &pb.PeerMessage{Channel: e.channel, From: peerId, To: RouterId, Flag: pb.Flag_MSG_STDERR, Data: []byte(fmt.Sprintf("peer %s disconnected\n", peerId))}
+		s.deliver(msg)
+		s.deliver(&pb.PeerMessage{Channel: e.channel, From: peerId, To: RouterId, Flag: pb.Flag_EXIT, Data: []byte("255")})
+	}
+}
@@ -0,0 +1,250 @@
+package router
+
+import (
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how long ranks get to exit after SIGTERM before they are killed
+const gangKillGrace = 10 * time.Second
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// GangService starts one process per peer as a single job: ranks are held
+// at a barrier until all of them have started, and the whole gang is torn
+// down as soon as one rank fails.
+type GangService struct {
+	pb.UnimplementedGangServiceServer
+	router *RouterService
+}
+
+type gangRank struct {
+	exec    *execution
+	peer    string
+	started bool
+	exited  bool
+}
+
+type gangFrame struct {
+	rank int
+	msg  *pb.PeerMessage
+}
+
+// rankScript wraps the command of a rank. The rank blocks on its first line
+// of stdin, which the router sends once every rank has started and which
+// carries the rendezvous host; closing stdin instead aborts the rank.
+func rankScript(req *pb.GangRequest, rank int, port int32) (string, error) {
+	var b strings.Builder
+	b.WriteString("IFS= read -r MASTER_ADDR || exit 125\n")
+	fmt.Fprintf(&b, "export MASTER_ADDR MASTER_PORT=%d RANK=%d LOCAL_RANK=0 WORLD_SIZE=%d", port, rank, len(req.Peers))
+	keys := make([]string, 0, len(req.Env))
+	for k := range req.Env {
+		if !envName.MatchString(k) {
+			return "", fmt.Errorf("invalid environment variable name: %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%s", k, shellQuote(req.Env[k]))
+	}
+	b.WriteString("\n")
+	b.WriteString(req.Command)
+	return b.String(), nil
+}
+
+func (g *GangService) Launch(req *pb.GangRequest, stream pb.GangService_LaunchServer) error {
+	if len(req.Peers) == 0 {
+		return fmt.Errorf("at least one peer is required")
+	}
+	if req.Command == "" {
+		return fmt.Errorf("command must not be empty")
+	}
+	port := req.Port
+	if port == 0 {
+		port = 29500
+	}
+	startTimeout := time.Duration(req.StartTimeout) * time.Second
+	if startTimeout <= 0 {
+		startTimeout = 60 * time.Second
+	}
+	log.Printf("[Router] launching gang of %d: %s\n", len(req.Peers), req.Command)
+
+	ranks := make([]*gangRank, len(req.Peers))
+	frames := make(chan gangFrame)
+	quit := make(chan struct{})
+	defer close(quit)
+
+	gone := false
+	emit := func(rank int, flag pb.Flag, data []byte) {
+		if gone {
+			return
+		}
+		peer := ""
+		if rank >= 0 {
+			peer = ranks[rank].peer
+		}
+		if err := stream.Send(&pb.GangEvent{Rank: int32(rank), Peer: peer, Flag: flag, Data: data}); err != nil {
+			log.Printf("[Router] failed to send gang event: %s\n", err)
+			gone = true
+		}
+	}
+
+	pending := len(req.Peers)
+	failed := false
+	released := false
+	var killTimer <-chan time.Time
+	var abandonTimer <-chan time.Time
+
+	// teardown aborts ranks still held at the barrier and terminates the rest
+	teardown := func(reason string) {
+		failed = true
+		log.Printf("[Router] tearing down gang: %s\n", reason)
+		emit(-1, pb.Flag_MSG_STDERR, []byte("gang: "+reason+"\n"))
+		for _, r := range ranks {
+			if r.exited || r.exec == nil {
+				continue
+			}
+			if released {
+				r.exec.send(pb.Flag_SIGNAL, []byte("TERM"))
+			} else {
+				r.exec.send(pb.Flag_EOF_STDIN, nil)
+			}
+		}
+		killTimer = time.After(gangKillGrace)
+	}
+
+	scripts := make([]string, len(req.Peers))
+	for i := range req.Peers {
+		script, err := rankScript(req, i, port)
+		if err != nil {
+			return err
+		}
+		scripts[i] = script
+		ranks[i] = &gangRank{peer: req.Peers[i]}
+	}
+
+	for i, to := range req.Peers {
+		if failed {
+			// an earlier rank could not be started
+			ranks[i].exited = true
+			pending--
+			emit(i, pb.Flag_EXIT, []byte("125"))
+			continue
+		}
+		e, err := g.router.exec(to, scripts[i])
+		if err != nil {
+			ranks[i].exited = true
+			pending--
+			emit(i, pb.Flag_MSG_STDERR, []byte(err.Error()+"\n"))
+			emit(i, pb.Flag_EXIT, []byte("255"))
+			if !failed {
+				teardown(fmt.Sprintf("rank %d could not be started", i))
+			}
+			continue
+		}
+		ranks[i].exec = e
+		defer e.close()
+		go func(rank int) {
+			for {
+				select {
+				case msg := <-e.frames:
+					select {
+					case frames <- gangFrame{rank, msg}:
+					case <-quit:
+						return
+					}
+					if msg.Flag == pb.Flag_EXIT {
+						return
+					}
+				case <-quit:
+					return
+				}
+			}
+		}(i)
+	}
+
+	release := func() {
+		released = true
+		addr := req.Rendezvous
+		if addr == "" {
+			if addr = g.router.host(ranks[0].peer); addr == "" {
+				addr = ranks[0].peer
+			}
+		}
+		log.Printf("[Router] releasing gang with MASTER_ADDR=%s\n", addr)
+		for _, r := range ranks {
+			r.exec.send(pb.Flag_MSG_STDIN, []byte(addr+"\n"))
+			r.exec.send(pb.Flag_EOF_STDIN, nil)
+		}
+	}
+
+	startTimer := time.NewTimer(startTimeout)
+	defer startTimer.Stop()
+	clientDone := stream.Context().Done()
+	for pending > 0 {
+		select {
+		case f := <-frames:
+			r := ranks[f.rank]
+			switch f.msg.Flag {
+			case pb.Flag_STARTED:
+				r.started = true
+				r.peer = f.msg.From
+				emit(f.rank, f.msg.Flag, nil)
+				all := true
+				for _, other := range ranks {
+					all = all && other.started
+				}
+				if all && !failed && !released {
+					release()
+				}
+			case pb.Flag_MSG_STDOUT, pb.Flag_MSG_STDERR:
+				emit(f.rank, f.msg.Flag, f.msg.Data)
+			case pb.Flag_EXIT:
+				r.exited = true
+				pending--
+				emit(f.rank, f.msg.Flag, f.msg.Data)
+				if code, _ := strconv.Atoi(string(f.msg.Data)); code != 0 && !failed {
+					teardown(fmt.Sprintf("rank %d exited with code %d", f.rank, code))
+				}
+			}
+		case <-startTimer.C:
+			if !released && !failed {
+				teardown(fmt.Sprintf("not all ranks started within %s", startTimeout))
+			}
+		case <-killTimer:
+			for _, r := range ranks {
+				if !r.exited {
+					r.exec.send(pb.Flag_SIGNAL, []byte("KILL"))
+				}
+			}
+			killTimer = nil
+			abandonTimer = time.After(gangKillGrace)
+		case <-abandonTimer:
+			// the agents of these ranks never reported their exit
+			for i, r := range ranks {
+				if !r.exited {
+					r.exited = true
+					pending--
+					emit(i, pb.Flag_MSG_STDERR, []byte(fmt.Sprintf("gang: rank %d did not exit after SIGKILL\n", i)))
+					emit(i, pb.Flag_EXIT, []byte("255"))
+				}
+			}
+		case <-clientDone:
+			// keep going without a client, so the ranks are still torn down
+			clientDone = nil
+			gone = true
+			if !failed {
+				teardown("client disconnected")
+			}
+		}
+	}
+	log.Printf("[Router] gang finished (failed=%t)\n", failed)
+	return nil
+}
@@ -0,0 +1,208 @@
+package router
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/websocket"
+)
+
+// tokenCookie keeps the token of a browser that presented it in the URL.
+const tokenCookie = "grpcsh_token"
+
+// HTTPOptions configures the router's HTTP front end.
+type HTTPOptions struct {
+	Addr     string   // host:port to listen on
+	Token    string   // required of clients as a bearer token or token parameter; "" lets anyone in
+	Allow    []string // peer:port patterns, as for path.Match, that may be reached; none allows any
+	CertFile string   // TLS certificate; with KeyFile, HTTPS is served instead of HTTP
+	KeyFile  string
+
+	TerminalAssets string // directory holding the xterm.js files of the /terminal/ page; none serves no page
+}
+
+// httpFrontend serves HTTP for the router. Requests under
+// /peers/<id>/port/<port>/ are proxied to that port on the peer's host,
+// including WebSocket upgrades, over tunnels the peer dials. Browser
+// terminals are served under /terminal/, and the REST API of the agent
+// SDK at the root. Agents that cannot reach the gRPC port connect over a
+// WebSocket at /connect instead. Terminals and /connect need a token, as
+// they hand out shells and a place among the peers.
+type httpFrontend struct {
+	router *RouterService
+	opts   HTTPOptions
+	mux    *http.ServeMux
+}
+
+func newHTTPFrontend(router *RouterService, opts HTTPOptions, agents *agentListener) *httpFrontend {
+	h := &httpFrontend{router: router, opts: opts, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/peers/", h.proxy)
+	if opts.Token != "" {
+		h.mux.Handle("GET /connect", websocket.Server{Handler: agents.serve})
+		newTerminals(router, h.mux, opts.TerminalAssets)
+	} else {
+		log.Printf("[Router] no HTTP token: terminals and agents connecting over WebSockets are off\n")
+	}
+	newRESTGateway(router, h.mux)
+	return h
+}
+
+func (h *httpFrontend) serve() {
+	var err error
+	if h.opts.CertFile != "" {
+		log.Printf("[Router] serving HTTPS on %s\n", h.opts.Addr)
+		err = http.ListenAndServeTLS(h.opts.Addr, h.opts.CertFile, h.opts.KeyFile, h)
+	} else {
+		log.Printf("[Router] serving HTTP on %s\n", h.opts.Addr)
+		err = http.ListenAndServe(h.opts.Addr, h)
+	}
+	if err != nil {
+		log.Printf("[Router] HTTP front end stopped: %s\n", err)
+	}
+}
+
+func (h *httpFrontend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// authorize checks the token of a request. A token given in the URL is
+// kept in a cookie, so that pages can load what they link to.
+func (h *httpFrontend) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if h.opts.Token == "" {
+		return true
+	}
+	valid := func(token string) bool {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(h.opts.Token)) == 1
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && valid(token) {
+		r.Header.Del("Authorization")
+		return true
+	}
+	if cookie, err := r.Cookie(tokenCookie); err == nil && valid(cookie.Value) {
+		return true
+	}
+	if query := r.URL.Query(); valid(query.Get("token")) {
+		http.SetCookie(w, &http.Cookie{Name: tokenCookie, Value: h.opts.Token, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		query.Del("token")
+		r.URL.RawQuery = query.Encode()
+		if r.Method == http.MethodGet {
+			http.Redirect(w, r, r.URL.RequestURI(), http.StatusFound)
+			return false
+		}
+		return true
+	}
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+	return false
+}
+
+// allowed reports whether a port of a peer may be reached.
+func (h *httpFrontend) allowed(peer string, port string) bool {
+	if len(h.opts.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range h.opts.Allow {
+		if ok, _ := path.Match(pattern, peer+":"+port); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// proxy relays /peers/<id>/port/<port>/<rest> to /<rest> on localhost:<port>
+// of the peer. Redirects and cookies of the service are kept under the prefix.
+func (h *httpFrontend) proxy(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.EscapedPath(), "/peers/"), "/", 4)
+	if len(parts) < 3 || parts[1] != "port" {
+		http.NotFound(w, r)
+		return
+	}
+	peer, err := url.PathUnescape(parts[0])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	port := parts[2]
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		http.NotFound(w, r)
+		return
+	}
+	prefix := "/peers/" + parts[0] + "/port/" + port
+	if len(parts) == 3 {
+		// relative links of the service resolve under the prefix only with the slash
+		target := prefix + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+	if !h.allowed(peer, port) {
+		http.Error(w, fmt.Sprintf("port %s of %s may not be reached", port, peer), http.StatusForbidden)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Scheme = "http"
+			pr.Out.URL.Host = net.JoinHostPort("localhost", port)
+			pr.Out.URL.RawPath = "/" + parts[3]
+			pr.Out.URL.Path, _ = url.PathUnescape(pr.Out.URL.RawPath)
+			// the Host header stays that of the router, as services check
+			// the origin of WebSocket requests against it
+			pr.SetXForwarded()
+			pr.Out.Header.Set("X-Forwarded-Prefix", prefix)
+			removeCookie(pr.Out, tokenCookie)
+		},
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network string, address string) (net.Conn, error) {
+				conn, err := h.router.dial(ctx, peer, address)
+				if err != nil {
+					return nil, err
+				}
+				return conn, nil
+			},
+			// each connection is a call to one peer
+			DisableKeepAlives: true,
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if location := resp.Header.Get("Location"); strings.HasPrefix(location, "/") && !strings.HasPrefix(location, prefix+"/") {
+				resp.Header.Set("Location", prefix+location)
+			}
+			if cookies := resp.Header.Values("Set-Cookie"); len(cookies) > 0 {
+				resp.Header.Del("Set-Cookie")
+				for _, cookie := range cookies {
+					resp.Header.Add("Set-Cookie", strings.Replace(cookie, "Path=/", "Path="+prefix+"/", 1))
+				}
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("[Router] proxying %s to %s:%s failed: %s\n", r.URL.Path, peer, port, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// removeCookie drops a cookie from a request.
+func removeCookie(r *http.Request, name string) {
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != name {
+			r.AddCookie(cookie)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Launcher starts agents that connect back to the router under a given peer ID.
+type Launcher interface {
+	Launch(peerId string, routerUrl string) (Instance, error)
+}
+
+// Instance is an agent started by a Launcher.
+type Instance interface {
+	// Stop terminates the agent.
+	Stop() error
+	// Done is closed once the agent process has exited.
+	Done() <-chan struct{}
+}
+
+// LocalLauncher runs agents as child processes of the router.
+type LocalLauncher struct {
+	Binary    string   // path to the agent binary
+	SocketDir string   // directory for the agents' unix sockets
+	Args      []string // extra agent arguments
+	LogDir    string   // if set, agent output goes to <LogDir>/<peerId>.log
+}
+
+// SSHLauncher runs agents on another host through the ssh command.
+type SSHLauncher struct {
+	Host      string   // user@hostname
+	SSHArgs   []string // extra ssh arguments, e.g. ["-i", "key.pem"]
+	Binary    string   // path to the agent binary on the host
+	SocketDir string   // directory for the agents' unix sockets on the host
+	Args      []string // extra agent arguments
+	LogDir    string   // if set, ssh output goes to <LogDir>/<peerId>.log
+}
+
+type processInstance struct {
+	cmd  *exec.Cmd
+	stop func() error
+	done chan struct{}
+}
+
+func (i *processInstance) Stop() error {
+	if i.stop != nil {
+		if err := i.stop(); err != nil {
+			log.Printf("[Router] failed to stop agent remotely: %s\n", err)
+		}
+	}
+	return i.cmd.Process.Kill()
+}
+
+func (i *processInstance) Done() <-chan struct{} {
+	return i.done
+}
+
+func agentArgs(peerId string, routerUrl string, socketDir string, extra []string) []string {
+	args := []string{"-r", routerUrl, "-i", peerId, "-s", filepath.Join(socketDir, peerId+".sock")}
+	return append(args, extra...)
+}
+
+func logOutput(logDir string, peerId string) (io.WriteCloser, error) {
+	if logDir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(filepath.Join(logDir, peerId+".log"))
+}
+
+func startInstance(cmd *exec.Cmd, output io.WriteCloser) (*processInstance, error) {
+	if output != nil {
+		if cmd.Stdout == nil {
+			cmd.Stdout = output
+		}
+		cmd.Stderr = output
+	}
+	if err := cmd.Start(); err != nil {
+		if output != nil {
+			output.Close()
+		}
+		return nil, err
+	}
+	i := &processInstance{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		if output != nil {
+			output.Close()
+		}
+		close(i.done)
+	}()
+	return i, nil
+}
+
+func (l *LocalLauncher) Launch(peerId string, routerUrl string) (Instance, error) {
+	output, err := logOutput(l.LogDir, peerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open agent log: %w", err)
+	}
+	cmd := exec.Command(l.Binary, agentArgs(peerId, routerUrl, l.SocketDir, l.Args)...)
+	return startInstance(cmd, output)
+}
+
+func (l *SSHLauncher) Launch(peerId string, routerUrl string) (Instance, error) {
+	output, err := logOutput(l.LogDir, peerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open agent log: %w", err)
+	}
+	// print the remote shell's PID before exec'ing the agent, so that
+	// the agent can be killed even if the ssh connection lingers
+	remote := []string{"echo $$; exec", shellQuote(l.Binary)}
+	for _, arg := range agentArgs(peerId, routerUrl, l.SocketDir, l.Args) {
+		remote = append(remote, shellQuote(arg))
+	}
+	args := append(append([]string{}, l.SSHArgs...), l.Host, strings.Join(remote, " "))
+	cmd := exec.Command("ssh", args...)
+	stdout, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = w
+	i, err := startInstance(cmd, output)
+	w.Close()
+	if err != nil {
+		stdout.Close()
+		return nil, err
+	}
+	pid := make(chan string, 1)
+	go func() {
+		defer stdout.Close()
+		r := bufio.NewReader(stdout)
+		line, _ := r.ReadString('\n')
+		pid <- strings.TrimSpace(line)
+		if output != nil {
+			io.Copy(output, r)
+		} else {
+			io.Copy(io.Discard, r)
+		}
+	}()
+	i.stop = func() error {
+		var remotePid string
+		select {
+		case remotePid = <-pid:
+		case <-time.After(5 * time.Second):
+		}
+		if remotePid == "" {
+			return fmt.Errorf("unknown remote pid for %s", peerId)
+		}
+		args := append(append([]string{}, l.SSHArgs...), l.Host, "kill "+remotePid)
+		return exec.Command("ssh", args...).Run()
+	}
+	return i, nil
+}
+
+// shellQuote quotes s for use as a single word in a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
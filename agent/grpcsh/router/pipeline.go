@@ -0,0 +1,198 @@
+package router
+
+import (
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// PipelineService runs pipelines whose stages are on different peers. The
+// agents send the stdout of each stage straight to the stdin of the next;
+// the router only relays the output of the last stage, the stderr and exit
+// code of every stage, and the client's stdin to the first.
+type PipelineService struct {
+	pb.UnimplementedPipelineServiceServer
+	router *RouterService
+}
+
+type pipelineStage struct {
+	exec   *execution
+	peer   string
+	eof    bool // done writing to the next stage
+	exited bool
+}
+
+type stageFrame struct {
+	stage int
+	msg   *pb.PeerMessage
+}
+
+func (p *PipelineService) Run(stream pb.PipelineService_RunServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to receive pipeline: %w", err)
+	}
+	req := first.Request
+	if req == nil || len(req.Stages) == 0 {
+		return fmt.Errorf("at least one stage is required")
+	}
+	for i, st := range req.Stages {
+		if st.Peer == "" {
+			return fmt.Errorf("stage %d has no peer", i)
+		}
+		if st.Command == "" {
+			return fmt.Errorf("stage %d has no command", i)
+		}
+	}
+	log.Printf("[Router] running pipeline of %d stages\n", len(req.Stages))
+
+	stages := make([]*pipelineStage, len(req.Stages))
+	for i, st := range req.Stages {
+		stages[i] = &pipelineStage{peer: st.Peer}
+	}
+	frames := make(chan stageFrame)
+	quit := make(chan struct{})
+	defer close(quit)
+
+	gone := false
+	emit := func(stage int, flag pb.Flag, data []byte) {
+		if gone {
+			return
+		}
+		if err := stream.Send(&pb.PipelineEvent{Stage: int32(stage), Peer: stages[stage].peer, Flag: flag, Data: data}); err != nil {
+			log.Printf("[Router] failed to send pipeline event: %s\n", err)
+			gone = true
+		}
+	}
+	signal := func(name string) {
+		for _, st := range stages {
+			if !st.exited {
+				st.exec.send(pb.Flag_SIGNAL, []byte(name))
+			}
+		}
+	}
+
+	// stages are opened from the last, so that each one is there before the
+	// stage feeding it starts; if one cannot be, those already opened are
+	// terminated and those before it never start
+	pending := 0
+	for i := len(req.Stages) - 1; i >= 0; i-- {
+		var e *execution
+		var err error
+		if i == len(req.Stages)-1 {
+			e, err = p.router.exec(req.Stages[i].Peer, req.Stages[i].Command)
+		} else {
+			next := stages[i+1].exec
+			data, _ := proto.Marshal(&pb.Pipe{Command: req.Stages[i].Command, Peer: next.to, Channel: next.channel})
+			e, err = p.router.open(req.Stages[i].Peer, pb.Flag_PIPE, data)
+		}
+		if err != nil {
+			emit(i, pb.Flag_MSG_STDERR, []byte(err.Error()+"\n"))
+			emit(i, pb.Flag_EXIT, []byte("255"))
+			for j := i; j >= 0; j-- {
+				stages[j].exited = true
+				if j < i {
+					emit(j, pb.Flag_EXIT, []byte("125"))
+				}
+			}
+			signal("TERM")
+			break
+		}
+		stages[i].exec = e
+		pending++
+		defer e.close()
+		go func(stage int) {
+			for {
+				select {
+				case msg := <-e.frames:
+					select {
+					case frames <- stageFrame{stage, msg}:
+					case <-quit:
+						return
+					}
+					if msg.Flag == pb.Flag_EXIT {
+						return
+					}
+				case <-quit:
+					return
+				}
+			}
+		}(i)
+	}
+
+	// the client's input is read on its own, as the stages may finish
+	// without reading it
+	inputs := make(chan *pb.PipelineInput)
+	go func() {
+		defer close(inputs)
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case inputs <- in:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	eof := false
+	clientDone := stream.Context().Done()
+	for pending > 0 {
+		select {
+		case f := <-frames:
+			st := stages[f.stage]
+			switch f.msg.Flag {
+			case pb.Flag_STARTED:
+				st.peer = f.msg.From
+				emit(f.stage, f.msg.Flag, nil)
+			case pb.Flag_MSG_STDOUT, pb.Flag_MSG_STDERR:
+				emit(f.stage, f.msg.Flag, f.msg.Data)
+			case pb.Flag_EOF_STDOUT:
+				st.eof = true
+			case pb.Flag_EXIT:
+				st.exited = true
+				pending--
+				emit(f.stage, f.msg.Flag, f.msg.Data)
+				// the agent of the stage sends the next one EOF itself, unless
+				// it was lost; a stage that stops reading ends the one still
+				// writing to it, as a closed pipe would
+				if next := f.stage + 1; next < len(stages) && !stages[next].exited {
+					stages[next].exec.send(pb.Flag_EOF_STDIN, nil)
+				}
+				if prev := f.stage - 1; prev >= 0 && !stages[prev].exited && !stages[prev].eof {
+					stages[prev].exec.send(pb.Flag_SIGNAL, []byte("PIPE"))
+				}
+			}
+		case in, ok := <-inputs:
+			if !ok {
+				inputs = nil
+				if !eof && !stages[0].exited {
+					stages[0].exec.send(pb.Flag_EOF_STDIN, nil)
+				}
+				eof = true
+				continue
+			}
+			switch in.Flag {
+			case pb.Flag_MSG_STDIN, pb.Flag_EOF_STDIN:
+				if !eof && !stages[0].exited {
+					stages[0].exec.send(in.Flag, in.Data)
+				}
+				eof = eof || in.Flag == pb.Flag_EOF_STDIN
+			case pb.Flag_SIGNAL:
+				signal(string(in.Data))
+			}
+		case <-clientDone:
+			// keep going without a client, so the stages are still ended
+			clientDone = nil
+			gone = true
+			signal("TERM")
+		}
+	}
+	log.Printf("[Router] pipeline finished\n")
+	return nil
+}
@@ -0,0 +1,386 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PoolPrefix marks a recipient as a pool of provisioned agents rather than
+// a single peer, e.g. "pool:scratch".
+const PoolPrefix = "pool:"
+
+// how long a launched agent may take to connect before it is given up on
+const registerTimeout = 2 * time.Minute
+
+// ProvisionConfig is the JSON configuration of the provisioner.
+type ProvisionConfig struct {
+	RouterUrl string       `json:"routerUrl"` // address launched agents use to reach the router
+	Pools     []PoolConfig `json:"pools"`
+}
+
+type PoolConfig struct {
+	Name        string         `json:"name"`
+	Min         int            `json:"min"`         // agents kept running even when idle
+	Max         int            `json:"max"`         // upper bound on agents in the pool
+	Slots       int            `json:"slots"`       // concurrent channels per agent (default 1)
+	IdleTimeout string         `json:"idleTimeout"` // e.g. "5m"; idle agents above Min are retired
+	Launcher    LauncherConfig `json:"launcher"`
+}
+
+type LauncherConfig struct {
+	Type      string   `json:"type"` // "local" or "ssh"
+	Binary    string   `json:"binary"`
+	SocketDir string   `json:"socketDir"`
+	Args      []string `json:"args"`
+	LogDir    string   `json:"logDir"`
+	Host      string   `json:"host"`    // [ssh] user@hostname
+	SSHArgs   []string `json:"sshArgs"` // [ssh] extra ssh arguments
+}
+
+// Provisioner launches agents into pools on demand, assigns channels
+// addressed to a pool to its agents, and retires agents that stay idle.
+type Provisioner struct {
+	routerUrl string
+	pools     map[string]*pool
+	owners    map[string]*pool // peerId -> pool
+	bindings  map[string]*binding
+	send      func(to string, msg *pb.PeerMessage) error
+	mu        sync.Mutex
+
+	// work that blocks, done by unlock once mu is released
+	stops   map[string]Instance // agents to stop, by peer ID
+	flushes []*binding          // bindings whose held frames are to be sent
+}
+
+type pool struct {
+	name      string
+	min       int
+	max       int
+	slots     int
+	idle      time.Duration
+	launcher  Launcher
+	instances map[string]*instance
+	queue     []string // channels waiting for a free slot
+	count     int      // used to number new agents
+}
+
+type instance struct {
+	peerId     string
+	proc       Instance
+	registered bool
+	busy       int
+	launchedAt time.Time
+	lastActive time.Time
+}
+
+// binding is a channel addressed to a pool, and the agent serving it.
+type binding struct {
+	pool     *pool
+	peerId   string            // empty while the channel is queued
+	pending  []*pb.PeerMessage // frames held until the channel is assigned
+	flushing bool              // assigned, with held frames still being sent
+	released bool              // the agent reported an exit
+	eof      bool              // the sender closed its input
+}
+
+func LoadProvisioner(path string) (*Provisioner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var config ProvisionConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return NewProvisioner(config)
+}
+
+func NewProvisioner(config ProvisionConfig) (*Provisioner, error) {
+	if config.RouterUrl == "" {
+		return nil, fmt.Errorf("routerUrl must be set")
+	}
+	p := &Provisioner{
+		routerUrl: config.RouterUrl,
+		pools:     make(map[string]*pool),
+		owners:    make(map[string]*pool),
+		bindings:  make(map[string]*binding),
+		stops:     make(map[string]Instance),
+	}
+	for _, c := range config.Pools {
+		if c.Name == "" || p.pools[c.Name] != nil {
+			return nil, fmt.Errorf("pool names must be unique and non-empty")
+		}
+		if c.Max < 1 || c.Min > c.Max {
+			return nil, fmt.Errorf("pool %s: need 0 <= min <= max and max >= 1", c.Name)
+		}
+		pl := &pool{name: c.Name, min: c.Min, max: c.Max, slots: c.Slots, instances: make(map[string]*instance)}
+		if pl.slots < 1 {
+			pl.slots = 1
+		}
+		if c.IdleTimeout != "" {
+			idle, err := time.ParseDuration(c.IdleTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("pool %s: bad idleTimeout: %w", c.Name, err)
+			}
+			pl.idle = idle
+		}
+		switch c.Launcher.Type {
+		case "local":
+			pl.launcher = &LocalLauncher{Binary: c.Launcher.Binary, SocketDir: c.Launcher.SocketDir, Args: c.Launcher.Args, LogDir: c.Launcher.LogDir}
+		case "ssh":
+			if c.Launcher.Host == "" {
+				return nil, fmt.Errorf("pool %s: ssh launcher needs a host", c.Name)
+			}
+			pl.launcher = &SSHLauncher{Host: c.Launcher.Host, SSHArgs: c.Launcher.SSHArgs, Binary: c.Launcher.Binary, SocketDir: c.Launcher.SocketDir, Args: c.Launcher.Args, LogDir: c.Launcher.LogDir}
+		default:
+			return nil, fmt.Errorf("pool %s: unknown launcher type %q", c.Name, c.Launcher.Type)
+		}
+		if c.Launcher.Binary == "" {
+			return nil, fmt.Errorf("pool %s: launcher needs a binary", c.Name)
+		}
+		p.pools[c.Name] = pl
+	}
+	return p, nil
+}
+
+// start keeps the pools at their minimum size and retires idle agents.
+func (p *Provisioner) start(send func(to string, msg *pb.PeerMessage) error) {
+	p.send = send
+	go func() {
+		for {
+			p.mu.Lock()
+			for _, pl := range p.pools {
+				p.retire(pl)
+				p.scale(pl)
+			}
+			p.unlock()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+}
+
+// unlock releases mu, then stops the agents and sends the held frames that
+// were queued while it was held, so that routing never waits for them.
+func (p *Provisioner) unlock() {
+	stops, flushes := p.stops, p.flushes
+	p.stops, p.flushes = make(map[string]Instance), nil
+	p.mu.Unlock()
+	for peerId, proc := range stops {
+		if err := proc.Stop(); err != nil {
+			log.Printf("[Router] failed to stop %s: %s\n", peerId, err)
+		}
+	}
+	for _, b := range flushes {
+		p.flush(b)
+	}
+}
+
+// flush sends the frames held for a channel until none are left. Frames
+// arriving meanwhile are held behind them, so they are not reordered.
+func (p *Provisioner) flush(b *binding) {
+	for {
+		p.mu.Lock()
+		held := b.pending
+		b.pending = nil
+		if len(held) == 0 {
+			b.flushing = false
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+		for _, msg := range held {
+			msg.To = b.peerId
+			if err := p.send(b.peerId, msg); err != nil {
+				log.Printf("[Router] failed to send message: %s\n", err)
+			}
+		}
+	}
+}
+
+// route resolves the recipient of a frame. Frames addressed to a pool are
+// bound to one of its agents; an empty result means the frame was queued.
+func (p *Provisioner) route(msg *pb.PeerMessage) (string, error) {
+	p.mu.Lock()
+	to, err := p.bind(msg)
+	p.unlock()
+	return to, err
+}
+
+func (p *Provisioner) bind(msg *pb.PeerMessage) (string, error) {
+	// an agent reporting an exit frees its slot
+	if msg.Flag == pb.Flag_EXIT {
+		if b, exists := p.bindings[msg.Channel]; exists && b.peerId == msg.From && !b.released {
+			b.released = true
+			p.release(msg.Channel, b)
+		}
+	}
+
+	if !strings.HasPrefix(msg.To, PoolPrefix) {
+		return msg.To, nil
+	}
+	b, exists := p.bindings[msg.Channel]
+	if exists && msg.Flag == pb.Flag_EOF_STDIN {
+		// the binding is kept until the sender is done with the channel
+		b.eof = true
+		if b.released {
+			delete(p.bindings, msg.Channel)
+		}
+	}
+	if !exists {
+		pl := p.pools[strings.TrimPrefix(msg.To, PoolPrefix)]
+		if pl == nil {
+			return "", fmt.Errorf("no such pool: %s", strings.TrimPrefix(msg.To, PoolPrefix))
+		}
+		if msg.Flag == pb.Flag_CALL {
+			// a call, e.g. to a port, means a particular peer, not any worker
+			return "", fmt.Errorf("calls cannot be made to pool %s", pl.name)
+		}
+		if msg.Flag != pb.Flag_COMMAND && msg.Flag != pb.Flag_TERMINAL && msg.Flag != pb.Flag_PIPE {
+			log.Printf("[Router] dropping %s for unassigned channel %s\n", msg.Flag, msg.Channel)
+			return "", nil
+		}
+		b = &binding{pool: pl}
+		p.bindings[msg.Channel] = b
+		if peerId := p.assign(pl); peerId != "" {
+			b.peerId = peerId
+		} else {
+			pl.queue = append(pl.queue, msg.Channel)
+			log.Printf("[Router] queued channel %s for pool %s (depth=%d)\n", msg.Channel, pl.name, len(pl.queue))
+			p.scale(pl)
+		}
+	}
+	if b.peerId == "" || b.flushing {
+		b.pending = append(b.pending, msg)
+		return "", nil
+	}
+	msg.To = b.peerId
+	return b.peerId, nil
+}
+
+// assign takes a free slot on the least busy registered agent of a pool.
+func (p *Provisioner) assign(pl *pool) string {
+	var best *instance
+	for _, inst := range pl.instances {
+		if inst.registered && inst.busy < pl.slots && (best == nil || inst.busy < best.busy) {
+			best = inst
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	best.busy++
+	best.lastActive = time.Now()
+	return best.peerId
+}
+
+func (p *Provisioner) release(channel string, b *binding) {
+	if b.eof {
+		delete(p.bindings, channel)
+	}
+	if inst := b.pool.instances[b.peerId]; inst != nil {
+		inst.busy--
+		inst.lastActive = time.Now()
+	}
+	p.dispatch(b.pool)
+}
+
+// dispatch hands queued channels to free slots; their held frames are sent
+// once mu is released.
+func (p *Provisioner) dispatch(pl *pool) {
+	for len(pl.queue) > 0 {
+		peerId := p.assign(pl)
+		if peerId == "" {
+			return
+		}
+		channel := pl.queue[0]
+		pl.queue = pl.queue[1:]
+		b := p.bindings[channel]
+		b.peerId, b.flushing = peerId, true
+		log.Printf("[Router] assigned queued channel %s to %s\n", channel, peerId)
+		p.flushes = append(p.flushes, b)
+	}
+}
+
+// scale launches agents until the pool can serve its queue, within bounds.
+func (p *Provisioner) scale(pl *pool) {
+	free := 0
+	for _, inst := range pl.instances {
+		free += pl.slots - inst.busy
+	}
+	for len(pl.instances) < pl.max && (len(pl.instances) < pl.min || free < len(pl.queue)) {
+		pl.count++
+		peerId := fmt.Sprintf("%s-%d", pl.name, pl.count)
+		proc, err := pl.launcher.Launch(peerId, p.routerUrl)
+		if err != nil {
+			log.Printf("[Router] failed to launch %s: %s\n", peerId, err)
+			return
+		}
+		log.Printf("[Router] launched %s for pool %s\n", peerId, pl.name)
+		now := time.Now()
+		pl.instances[peerId] = &instance{peerId: peerId, proc: proc, launchedAt: now, lastActive: now}
+		p.owners[peerId] = pl
+		free += pl.slots
+		go func() {
+			<-proc.Done()
+			p.mu.Lock()
+			p.remove(pl, peerId)
+			p.mu.Unlock()
+		}()
+	}
+}
+
+// retire stops agents that idled past the timeout or never registered.
+func (p *Provisioner) retire(pl *pool) {
+	now := time.Now()
+	for peerId, inst := range pl.instances {
+		stale := !inst.registered && now.Sub(inst.launchedAt) > registerTimeout
+		idle := inst.registered && inst.busy == 0 && pl.idle > 0 && now.Sub(inst.lastActive) > pl.idle && len(pl.instances) > pl.min
+		if stale || idle {
+			log.Printf("[Router] retiring %s (registered=%t)\n", peerId, inst.registered)
+			p.stops[peerId] = inst.proc
+			p.remove(pl, peerId)
+		}
+	}
+}
+
+func (p *Provisioner) remove(pl *pool, peerId string) {
+	if _, exists := pl.instances[peerId]; !exists {
+		return
+	}
+	delete(pl.instances, peerId)
+	delete(p.owners, peerId)
+	log.Printf("[Router] removed %s from pool %s\n", peerId, pl.name)
+}
+
+// register marks a launched agent as connected and gives it queued work.
+func (p *Provisioner) register(peerId string) {
+	p.mu.Lock()
+	defer p.unlock()
+	pl := p.owners[peerId]
+	if pl == nil {
+		return
+	}
+	inst := pl.instances[peerId]
+	inst.registered = true
+	inst.lastActive = time.Now()
+	log.Printf("[Router] %s registered with pool %s\n", peerId, pl.name)
+	p.dispatch(pl)
+}
+
+// unregister forgets an agent of a pool once its connection drops.
+func (p *Provisioner) unregister(peerId string) {
+	p.mu.Lock()
+	defer p.unlock()
+	if pl := p.owners[peerId]; pl != nil {
+		if inst := pl.instances[peerId]; inst != nil && inst.registered {
+			p.stops[peerId] = inst.proc
+			p.remove(pl, peerId)
+		}
+	}
+}
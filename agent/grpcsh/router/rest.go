@@ -0,0 +1,159 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	pb "grpcsh/pb"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how long results of finished commands are kept for clients to fetch
+const restResultTTL = 10 * time.Minute
+
+// commandRequest is the body of POST /executecommandrequest.
+type commandRequest struct {
+	AgentId    string   `json:"agentId"`
+	WorkingDir string   `json:"workingDir"`
+	Arguments  []string `json:"arguments"`
+}
+
+// commandAccepted answers POST /executecommandrequest.
+type commandAccepted struct {
+	ExecutionId *string `json:"executionId"`
+	Error       *string `json:"error"`
+}
+
+// commandResponse answers GET /executecommandresponse/{id}; only
+// available is set until the command has exited.
+type commandResponse struct {
+	ExecutionId    string `json:"executionId"`
+	Available      bool   `json:"available"`
+	ResponseString string `json:"responseString"`
+	ErrorString    string `json:"errorString"`
+	ExitCode       int    `json:"exitCode"`
+}
+
+// restCommand is a command run for a REST client, kept until its result
+// expires.
+type restCommand struct {
+	stdout   string
+	stderr   string
+	code     int
+	finished time.Time // zero while running
+}
+
+// restGateway runs commands for clients of the REST API of the agent
+// SDK, which submit commands and poll for their results, on the same
+// path as gRPC clients.
+type restGateway struct {
+	router   *RouterService
+	commands map[string]*restCommand
+	mu       sync.Mutex
+}
+
+func newRESTGateway(router *RouterService, mux *http.ServeMux) *restGateway {
+	g := &restGateway{router: router, commands: make(map[string]*restCommand)}
+	mux.HandleFunc("POST /executecommandrequest", g.submit)
+	mux.HandleFunc("GET /executecommandresponse/{id}", g.result)
+	mux.HandleFunc("GET /{agentId}", g.agent)
+	return g
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// submit starts a command and answers with the ID to poll for its result.
+func (g *restGateway) submit(w http.ResponseWriter, r *http.Request) {
+	fail := func(code int, msg string) {
+		writeJSON(w, code, commandAccepted{Error: &msg})
+	}
+	var req commandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	if req.AgentId == "" || len(req.Arguments) == 0 {
+		fail(http.StatusBadRequest, "agentId and arguments are required")
+		return
+	}
+	quoted := make([]string, len(req.Arguments))
+	for i, arg := range req.Arguments {
+		quoted[i] = shellQuote(arg)
+	}
+	script := "exec " + strings.Join(quoted, " ")
+	if req.WorkingDir != "" {
+		script = "cd " + shellQuote(req.WorkingDir) + " && " + script
+	}
+	e, err := g.router.exec(req.AgentId, script)
+	if err != nil {
+		fail(http.StatusBadGateway, err.Error())
+		return
+	}
+	// the command gets no input
+	e.send(pb.Flag_EOF_STDIN, nil)
+	c := &restCommand{}
+	g.mu.Lock()
+	g.expire()
+	g.commands[e.channel] = c
+	g.mu.Unlock()
+	log.Printf("[Router] REST command %s on %s: %q\n", e.channel, req.AgentId, req.Arguments)
+	go func() {
+		defer e.close()
+		var stdout, stderr bytes.Buffer
+		code := e.wait(&stdout, &stderr, nil)
+		g.mu.Lock()
+		c.stdout, c.stderr, c.code, c.finished = stdout.String(), stderr.String(), code, time.Now()
+		g.mu.Unlock()
+	}()
+	id := e.channel
+	writeJSON(w, http.StatusOK, commandAccepted{ExecutionId: &id})
+}
+
+// expire forgets results that were not fetched in time. g.mu is held.
+func (g *restGateway) expire() {
+	for id, c := range g.commands {
+		if !c.finished.IsZero() && time.Since(c.finished) > restResultTTL {
+			delete(g.commands, id)
+		}
+	}
+}
+
+// result reports whether a command has exited and, once it has, its output.
+func (g *restGateway) result(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, exists := g.commands[id]
+	if !exists {
+		writeJSON(w, http.StatusNotFound, commandResponse{ExecutionId: id})
+		return
+	}
+	resp := commandResponse{ExecutionId: id}
+	if !c.finished.IsZero() {
+		resp.Available = true
+		resp.ResponseString = c.stdout
+		resp.ErrorString = c.stderr
+		resp.ExitCode = c.code
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// agent reports whether an agent is connected, with 202 if it is.
+func (g *restGateway) agent(w http.ResponseWriter, r *http.Request) {
+	agentId := r.PathValue("agentId")
+	g.router.mu.RLock()
+	_, up := g.router.peers[agentId]
+	g.router.mu.RUnlock()
+	code := http.StatusAccepted
+	if !up {
+		code = http.StatusNotFound
+	}
+	writeJSON(w, code, map[string]any{"agentId": agentId, "agentUp": up})
+}
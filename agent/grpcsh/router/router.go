@@ -10,13 +10,32 @@ import (
 	"sync"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type RouterService struct {
 	pb.UnimplementedRouterServiceServer
-	peers map[string]pb.RouterService_ConnectServer
-	mu    sync.RWMutex
+	peers       map[string]*peerConn
+	executions  map[string]*execution
+	channels    *ChannelService
+	provisioner *Provisioner
+	mu          sync.RWMutex
+}
+
+// peerConn serializes sends to a peer, whose frames may come from several senders.
+type peerConn struct {
+	stream pb.RouterService_ConnectServer
+	host   string // hostname reported by the agent
+	mu     sync.Mutex
+}
+
+func (p *peerConn) Send(msg *pb.PeerMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stream.Send(msg)
 }
 
 func (s *RouterService) Connect(stream pb.RouterService_ConnectServer) error {
@@ -31,14 +50,21 @@ func (s *RouterService) Connect(stream pb.RouterService_ConnectServer) error {
 
 	// Assign peer ID
 	s.mu.Lock() // Write lock when modifying the map and counter
-	s.peers[peerId] = stream
+	s.peers[peerId] = &peerConn{stream: stream, host: string(peer.Data)}
 	s.mu.Unlock()
 
 	log.Printf("[Router] saved peerId: %s\n", peerId)
+	if s.provisioner != nil {
+		s.provisioner.register(peerId)
+	}
 	defer func() {
 		s.mu.Lock() // Write lock when removing from map
 		delete(s.peers, peerId)
 		s.mu.Unlock()
+		if s.provisioner != nil {
+			s.provisioner.unregister(peerId)
+		}
+		s.lost(peerId)
 		log.Printf("[Router] disconnected peerId: %s\n", peerId)
 	}()
 
@@ -47,23 +73,73 @@ func (s *RouterService) Connect(stream pb.RouterService_ConnectServer) error {
 		if err != nil {
 			return fmt.Errorf("failed to receive message: %w", err)
 		}
-		from := msg.From
-		to := msg.To
-		if to != "" {
-			s.mu.RLock()
-			if peer, exists := s.peers[msg.To]; exists {
-				log.Printf("[Router] %s -> %s: channel=%s, flag=%s, hash=%x, length=%d\n", from, to, msg.Channel, msg.Flag, md5.Sum(msg.Data), len(msg.Data))
-				if err := peer.Send(msg); err != nil {
-					log.Printf("[Router] failed to send message: %s\n", err)
-				}
-			}
-			s.mu.RUnlock()
-		} else {
-			log.Printf("[Router] %s -> [no recipient]: %s\n", from, msg)
+		if msg.To == "" {
+			log.Printf("[Router] %s -> [no recipient]: %s\n", msg.From, msg)
+			continue
+		}
+		if err := s.route(msg); err != nil {
+			log.Printf("[Router] failed to send message: %s\n", err)
+			s.reject(msg, err)
 		}
 	}
 }
 
+// route delivers a frame to its recipient, which may be a peer, a pool
+// of peers, or the router itself.
+func (s *RouterService) route(msg *pb.PeerMessage) error {
+	from := msg.From
+	to := msg.To
+	if s.provisioner != nil {
+		// frames for a pool may be bound to an agent, or held until one is free
+		var err error
+		if to, err = s.provisioner.route(msg); err != nil || to == "" {
+			return err
+		}
+	}
+	log.Printf("[Router] %s -> %s: channel=%s, flag=%s, hash=%x, length=%d\n", from, to, msg.Channel, msg.Flag, md5.Sum(msg.Data), len(msg.Data))
+	if to == RouterId {
+		s.deliver(msg)
+		return nil
+	}
+	return s.send(to, msg)
+}
+
+func (s *RouterService) send(to string, msg *pb.PeerMessage) error {
+	s.mu.RLock()
+	peer, exists := s.peers[to]
+	s.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("unknown peer: %s", to)
+	}
+	return peer.Send(msg)
+}
+
+// reject ends a command or call whose opening frame could not be
+// delivered, so that the sender does not wait for a peer that is not there.
+func (s *RouterService) reject(msg *pb.PeerMessage, err error) {
+	var exit []byte
+	switch msg.Flag {
+	case pb.Flag_COMMAND, pb.Flag_TERMINAL, pb.Flag_PIPE:
+		exit = []byte("255")
+		s.route(&pb.PeerMessage{Channel: msg.Channel, From: msg.To, To: msg.From, Flag: pb.Flag_MSG_STDERR, Data: []byte(err.Error() + "\n")})
+	case pb.Flag_CALL:
+		exit, _ = proto.Marshal(status.New(codes.Unavailable, err.Error()).Proto())
+	default:
+		return
+	}
+	s.route(&pb.PeerMessage{Channel: msg.Channel, From: msg.To, To: msg.From, Flag: pb.Flag_EXIT, Data: exit})
+}
+
+// host returns the hostname a peer reported when it connected.
+func (s *RouterService) host(peerId string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if peer, exists := s.peers[peerId]; exists {
+		return peer.host
+	}
+	return ""
+}
+
 type ChannelService struct {
 	pb.UnimplementedChannelServiceServer
 	channels map[string]string
@@ -71,12 +147,16 @@ type ChannelService struct {
 }
 
 func (c *ChannelService) CreateChannel(ctx context.Context, req *emptypb.Empty) (*pb.Channel, error) {
+	return &pb.Channel{Id: c.create()}, nil
+}
+
+func (c *ChannelService) create() string {
 	c.mu.Lock()
 	channelId := fmt.Sprintf("ch%d", len(c.channels)+1)
 	c.channels[channelId] = channelId
 	c.mu.Unlock()
 	log.Printf("[Router] created channel: %s\n", channelId)
-	return &pb.Channel{Id: channelId}, nil
+	return channelId
 }
 
 func (c *ChannelService) DeleteChannel(ctx context.Context, req *pb.Channel) (*emptypb.Empty, error) {
@@ -87,14 +167,54 @@ func (c *ChannelService) DeleteChannel(ctx context.Context, req *pb.Channel) (*e
 	return &emptypb.Empty{}, nil
 }
 
-func Start(routerUrl string) {
+// Options holds the optional settings of a router.
+type Options struct {
+	Provisioner *Provisioner     // launches agents for pools; nil disables pools
+	Schedules   *ScheduleService // cron schedules; nil keeps them in memory
+	HTTP        *HTTPOptions     // the HTTP front end; nil disables it
+	SSH         *SSHOptions      // the SSH front end; nil disables it
+}
+
+func Start(routerUrl string, opts Options) {
 	server := grpc.NewServer()
-	pb.RegisterRouterServiceServer(server, &RouterService{
-		peers: make(map[string]pb.RouterService_ConnectServer),
-	})
-	pb.RegisterChannelServiceServer(server, &ChannelService{
+	channelSvc := &ChannelService{
 		channels: make(map[string]string),
+	}
+	routerSvc := &RouterService{
+		peers:       make(map[string]*peerConn),
+		executions:  make(map[string]*execution),
+		channels:    channelSvc,
+		provisioner: opts.Provisioner,
+	}
+	if routerSvc.provisioner != nil {
+		routerSvc.provisioner.start(routerSvc.send)
+	}
+	scheduleSvc := opts.Schedules
+	if scheduleSvc == nil {
+		scheduleSvc, _ = LoadSchedules("")
+	}
+	scheduleSvc.start(routerSvc)
+	pb.RegisterRouterServiceServer(server, routerSvc)
+	pb.RegisterChannelServiceServer(server, channelSvc)
+	pb.RegisterGangServiceServer(server, &GangService{router: routerSvc})
+	pb.RegisterPipelineServiceServer(server, &PipelineService{router: routerSvc})
+	pb.RegisterArrayServiceServer(server, &ArrayService{
+		router: routerSvc,
+		arrays: make(map[string]*jobArray),
 	})
+	pb.RegisterScheduleServiceServer(server, scheduleSvc)
+	if opts.HTTP != nil {
+		agents := newAgentListener()
+		go server.Serve(agents)
+		go newHTTPFrontend(routerSvc, *opts.HTTP, agents).serve()
+	}
+	if opts.SSH != nil {
+		sshFrontend, err := newSSHFrontend(routerSvc, *opts.SSH)
+		if err != nil {
+			log.Fatalf("[Router] failed to start the SSH front end: %s\n", err)
+		}
+		go sshFrontend.serve()
+	}
 
 	lis, _ := net.Listen("tcp", routerUrl)
 	log.Printf("[Router] started on: %s\n", routerUrl)
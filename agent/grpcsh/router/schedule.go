@@ -0,0 +1,336 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	// runs kept per schedule
+	scheduleHistory = 20
+	// bytes of output kept per run
+	scheduleOutputLimit = 16 * 1024
+	// how late a run may start before it counts as missed
+	missedGrace = time.Minute
+)
+
+// ScheduleService runs commands on cron schedules through the same path as
+// any other execution. Schedules and their run history are kept in a JSON
+// file, so runs that fell due while the router was down can be detected
+// and, depending on the schedule, made up for on startup.
+type ScheduleService struct {
+	pb.UnimplementedScheduleServiceServer
+	router    *RouterService
+	path      string // "" keeps schedules in memory only
+	schedules map[string]*schedule
+	mu        sync.Mutex
+}
+
+type schedule struct {
+	Name         string             `json:"name"`
+	Cron         string             `json:"cron"`
+	To           string             `json:"to"`
+	Command      string             `json:"command"`
+	Missed       pb.MissedRunPolicy `json:"missed"`
+	AllowOverlap bool               `json:"allowOverlap"`
+	Checked      time.Time          `json:"checked"` // runs due up to here have been handled
+	Runs         []*scheduleRun     `json:"runs"`
+
+	spec    *cronSpec
+	next    time.Time
+	running int
+}
+
+type scheduleRun struct {
+	Scheduled time.Time   `json:"scheduled"`
+	Started   time.Time   `json:"started"`
+	Finished  time.Time   `json:"finished"`
+	State     pb.RunState `json:"state"`
+	Peer      string      `json:"peer"`
+	ExitCode  int         `json:"exitCode"`
+	Output    []byte      `json:"output"`
+}
+
+// LoadSchedules reads the schedules kept in a file, which is created once
+// the first schedule is added. An empty path keeps schedules in memory.
+func LoadSchedules(path string) (*ScheduleService, error) {
+	s := &ScheduleService{path: path, schedules: make(map[string]*schedule)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*schedule
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, sc := range list {
+		if sc.spec, err = parseCron(sc.Cron); err != nil {
+			return nil, fmt.Errorf("schedule %s: %w", sc.Name, err)
+		}
+		// runs that were going when the router stopped never reported back
+		for _, run := range sc.Runs {
+			if run.State == pb.RunState_RUN_RUNNING {
+				run.State = pb.RunState_RUN_FAILED
+				run.ExitCode = 255
+				run.Output = append(run.Output, "\nrouter stopped during the run\n"...)
+			}
+		}
+		sc.next = sc.spec.next(sc.Checked)
+		s.schedules[sc.Name] = sc
+	}
+	log.Printf("[Router] loaded %d schedules from %s\n", len(list), path)
+	return s, nil
+}
+
+// start triggers due schedules until the router exits.
+func (s *ScheduleService) start(router *RouterService) {
+	s.router = router
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.tick(now)
+		}
+	}()
+}
+
+func (s *ScheduleService) tick(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := false
+	for _, sc := range s.schedules {
+		if sc.next.IsZero() || now.Before(sc.next) {
+			continue
+		}
+		// runs due within the grace period still start; older ones were missed
+		var first, lastMissed, due time.Time
+		missed := 0
+		for t := sc.next; !t.IsZero() && !t.After(now); t = sc.spec.next(t) {
+			if now.Sub(t) <= missedGrace {
+				due = t
+				continue
+			}
+			if missed == 0 {
+				first = t
+			}
+			missed++
+			lastMissed = t
+		}
+		if missed > 0 {
+			log.Printf("[Router] schedule %s missed %d runs since %s\n", sc.Name, missed, first.Format(time.RFC3339))
+			sc.record(&scheduleRun{
+				Scheduled: first,
+				State:     pb.RunState_RUN_MISSED,
+				Output:    []byte(fmt.Sprintf("%d runs missed between %s and %s\n", missed, first.Format(time.RFC3339), lastMissed.Format(time.RFC3339))),
+			})
+			if due.IsZero() && sc.Missed == pb.MissedRunPolicy_RUN_MISSED_ONCE {
+				due = lastMissed
+			}
+		}
+		if !due.IsZero() {
+			s.trigger(sc, due, now)
+		}
+		sc.Checked = now
+		sc.next = sc.spec.next(now)
+		changed = true
+	}
+	if changed {
+		s.save()
+	}
+}
+
+// trigger starts a run of a schedule, unless the previous one is still going.
+func (s *ScheduleService) trigger(sc *schedule, due time.Time, now time.Time) {
+	if sc.running > 0 && !sc.AllowOverlap {
+		log.Printf("[Router] skipping schedule %s: previous run still going\n", sc.Name)
+		sc.record(&scheduleRun{Scheduled: due, Started: now, Finished: now, State: pb.RunState_RUN_SKIPPED})
+		return
+	}
+	log.Printf("[Router] running schedule %s on %s\n", sc.Name, sc.To)
+	run := &scheduleRun{Scheduled: due, Started: now, State: pb.RunState_RUN_RUNNING}
+	sc.record(run)
+	sc.running++
+	go func() {
+		output := &tailBuffer{limit: scheduleOutputLimit}
+		code, peer := 255, ""
+		e, err := s.router.exec(sc.To, sc.Command)
+		if err != nil {
+			fmt.Fprintf(output, "%s\n", err)
+		} else {
+			e.send(pb.Flag_EOF_STDIN, nil)
+			code = e.wait(output, output, nil)
+			e.mu.Lock()
+			peer = e.peer
+			e.mu.Unlock()
+			e.close()
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		sc.running--
+		run.Finished = time.Now()
+		run.Peer = peer
+		run.ExitCode = code
+		run.Output, _ = output.bytes()
+		run.State = pb.RunState_RUN_DONE
+		if code != 0 {
+			run.State = pb.RunState_RUN_FAILED
+		}
+		log.Printf("[Router] schedule %s finished with code %d\n", sc.Name, code)
+		s.save()
+	}()
+}
+
+func (sc *schedule) record(run *scheduleRun) {
+	sc.Runs = append(sc.Runs, run)
+	if len(sc.Runs) > scheduleHistory {
+		sc.Runs = sc.Runs[len(sc.Runs)-scheduleHistory:]
+	}
+}
+
+// save writes the schedules to their file; the caller holds s.mu.
+func (s *ScheduleService) save() {
+	if s.path == "" {
+		return
+	}
+	list := make([]*schedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		list = append(list, sc)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err == nil {
+		tmp := s.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0600); err == nil {
+			err = os.Rename(tmp, s.path)
+		}
+	}
+	if err != nil {
+		log.Printf("[Router] failed to save schedules: %s\n", err)
+	}
+}
+
+func (s *ScheduleService) Create(ctx context.Context, req *pb.Schedule) (*pb.Schedule, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("name must not be empty")
+	}
+	if req.To == "" {
+		return nil, fmt.Errorf("recipient must not be empty")
+	}
+	if req.Command == "" {
+		return nil, fmt.Errorf("command must not be empty")
+	}
+	spec, err := parseCron(req.Cron)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	next := spec.next(now)
+	if next.IsZero() {
+		return nil, fmt.Errorf("cron expression never matches: %q", req.Cron)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.schedules[req.Name]; exists {
+		return nil, fmt.Errorf("schedule already exists: %s", req.Name)
+	}
+	sc := &schedule{
+		Name:         req.Name,
+		Cron:         req.Cron,
+		To:           req.To,
+		Command:      req.Command,
+		Missed:       req.Missed,
+		AllowOverlap: req.AllowOverlap,
+		Checked:      now,
+		spec:         spec,
+		next:         next,
+	}
+	s.schedules[sc.Name] = sc
+	s.save()
+	log.Printf("[Router] created schedule %s (%s) on %s, next run at %s\n", sc.Name, sc.Cron, sc.To, next.Format(time.RFC3339))
+	return sc.proto(), nil
+}
+
+// Delete removes a schedule; a run that is going is left to finish.
+func (s *ScheduleService) Delete(ctx context.Context, req *pb.ScheduleName) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.schedules[req.Name]; !exists {
+		return nil, fmt.Errorf("unknown schedule: %s", req.Name)
+	}
+	delete(s.schedules, req.Name)
+	s.save()
+	log.Printf("[Router] deleted schedule %s\n", req.Name)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ScheduleService) List(ctx context.Context, req *emptypb.Empty) (*pb.ScheduleList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := &pb.ScheduleList{}
+	for _, sc := range s.schedules {
+		list.Schedules = append(list.Schedules, sc.proto())
+	}
+	sort.Slice(list.Schedules, func(i, j int) bool { return list.Schedules[i].Name < list.Schedules[j].Name })
+	return list, nil
+}
+
+func (s *ScheduleService) History(ctx context.Context, req *pb.ScheduleName) (*pb.ScheduleHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, exists := s.schedules[req.Name]
+	if !exists {
+		return nil, fmt.Errorf("unknown schedule: %s", req.Name)
+	}
+	history := &pb.ScheduleHistory{}
+	for _, run := range sc.Runs {
+		history.Runs = append(history.Runs, &pb.ScheduleRun{
+			Scheduled: unixTime(run.Scheduled),
+			Started:   unixTime(run.Started),
+			Finished:  unixTime(run.Finished),
+			State:     run.State,
+			Peer:      run.Peer,
+			ExitCode:  int32(run.ExitCode),
+			Output:    run.Output,
+		})
+	}
+	return history, nil
+}
+
+func (sc *schedule) proto() *pb.Schedule {
+	return &pb.Schedule{
+		Name:         sc.Name,
+		Cron:         sc.Cron,
+		To:           sc.To,
+		Command:      sc.Command,
+		Missed:       sc.Missed,
+		AllowOverlap: sc.AllowOverlap,
+		NextRun:      unixTime(sc.next),
+		Running:      sc.running > 0,
+	}
+}
+
+// unixTime converts a time to unix seconds, keeping 0 for the zero time.
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
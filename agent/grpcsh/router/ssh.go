@@ -0,0 +1,285 @@
+package router
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHOptions configures the router's SSH front end.
+type SSHOptions struct {
+	Addr           string // host:port to listen on
+	HostKey        string // private key file of the router; generated if missing
+	AuthorizedKeys string // public keys that may log in, in authorized_keys format
+}
+
+// sftpScript runs the SFTP server of the peer's host, wherever it is installed.
+const sftpScript = `for p in /usr/lib/openssh/sftp-server /usr/libexec/openssh/sftp-server /usr/lib/ssh/sftp-server /usr/libexec/sftp-server /usr/lib/sftp-server; do
+  [ -x "$p" ] && exec "$p"
+done
+echo "sftp-server not found" >&2
+exit 127`
+
+// sshFrontend serves SSH for the router. The username selects the peer,
+// either as a whole or up to an '@', as in "compute-1@alice"; exec, shell
+// and subsystem requests run as commands on it, so ssh, scp and sftp work
+// against agents without sshd on their hosts.
+type sshFrontend struct {
+	router *RouterService
+	opts   SSHOptions
+	config *ssh.ServerConfig
+}
+
+func newSSHFrontend(router *RouterService, opts SSHOptions) (*sshFrontend, error) {
+	authorized, err := loadAuthorizedKeys(opts.AuthorizedKeys)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := loadHostKey(opts.HostKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorized[string(key.Marshal())] {
+				return &ssh.Permissions{Extensions: map[string]string{"fingerprint": ssh.FingerprintSHA256(key)}}, nil
+			}
+			return nil, fmt.Errorf("unknown key %s for %s", ssh.FingerprintSHA256(key), conn.User())
+		},
+	}
+	config.AddHostKey(signer)
+	return &sshFrontend{router: router, opts: opts, config: config}, nil
+}
+
+// loadAuthorizedKeys reads the keys of an authorized_keys file; options
+// given with them are ignored.
+func loadAuthorizedKeys(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorized keys: %w", err)
+	}
+	keys := make(map[string]bool)
+	for len(bytes.TrimSpace(data)) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse authorized keys %s: %w", path, err)
+		}
+		keys[string(key.Marshal())] = true
+		data = rest
+	}
+	return keys, nil
+}
+
+// loadHostKey reads the router's host key, generating and saving an
+// ed25519 key the first time.
+func loadHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		block, err := ssh.MarshalPrivateKey(key, "grpcsh router")
+		if err != nil {
+			return nil, err
+		}
+		data = pem.EncodeToMemory(block)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to save host key: %w", err)
+		}
+		log.Printf("[Router] generated SSH host key %s\n", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read host key: %w", err)
+	}
+	return ssh.ParsePrivateKey(data)
+}
+
+func (f *sshFrontend) serve() {
+	lis, err := net.Listen("tcp", f.opts.Addr)
+	if err != nil {
+		log.Printf("[Router] SSH front end stopped: %s\n", err)
+		return
+	}
+	log.Printf("[Router] serving SSH on %s\n", f.opts.Addr)
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			log.Printf("[Router] SSH front end stopped: %s\n", err)
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *sshFrontend) handle(conn net.Conn) {
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, f.config)
+	if err != nil {
+		log.Printf("[Router] SSH handshake with %s failed: %s\n", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	defer sconn.Close()
+	peer, _, _ := strings.Cut(sconn.User(), "@")
+	log.Printf("[Router] SSH login to %s from %s with %s\n", peer, sconn.RemoteAddr(), sconn.Permissions.Extensions["fingerprint"])
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+		ch, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		s := &sshSession{router: f.router, peer: peer, ch: ch}
+		go s.serve(requests)
+	}
+	log.Printf("[Router] SSH session to %s from %s ended\n", peer, sconn.RemoteAddr())
+}
+
+// sshSession runs one command on a peer for a session channel.
+type sshSession struct {
+	router *RouterService
+	peer   string
+	ch     ssh.Channel
+	env    strings.Builder // exports to run before the command
+	e      *execution
+	exited chan struct{}
+	mu     sync.Mutex
+}
+
+func (s *sshSession) serve(requests <-chan *ssh.Request) {
+	for req := range requests {
+		ok := false
+		switch req.Type {
+		case "env":
+			var kv struct{ Name, Value string }
+			if ssh.Unmarshal(req.Payload, &kv) == nil && envName.MatchString(kv.Name) {
+				fmt.Fprintf(&s.env, "export %s=%s\n", kv.Name, shellQuote(kv.Value))
+				ok = true
+			}
+		case "exec":
+			var cmd struct{ Command string }
+			if ssh.Unmarshal(req.Payload, &cmd) == nil {
+				ok = s.start(cmd.Command)
+			}
+		case "shell":
+			ok = s.start(`exec "${SHELL:-/bin/sh}" -i`)
+		case "subsystem":
+			var sub struct{ Name string }
+			if ssh.Unmarshal(req.Payload, &sub) == nil && sub.Name == "sftp" {
+				ok = s.start(sftpScript)
+			}
+		case "signal":
+			var sig struct{ Signal string }
+			if ssh.Unmarshal(req.Payload, &sig) == nil && s.running() {
+				s.e.send(pb.Flag_SIGNAL, []byte(sig.Signal))
+				ok = true
+			}
+		}
+		// pty-req is refused, so that clients keep echoing and editing
+		// lines themselves; agents run commands without a terminal
+		if req.WantReply {
+			req.Reply(ok, nil)
+		}
+	}
+	// the client closed the channel before the command exited
+	if s.running() {
+		s.e.send(pb.Flag_SIGNAL, []byte("TERM"))
+	}
+}
+
+// running reports whether a command was started and has not exited.
+func (s *sshSession) running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.e == nil {
+		return false
+	}
+	select {
+	case <-s.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// start runs a command on the peer, relaying the channel to its stdin
+// and its output back. A session runs one command only.
+func (s *sshSession) start(command string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.e != nil {
+		return false
+	}
+	log.Printf("[Router] SSH command on %s: %q\n", s.peer, command)
+	e, err := s.router.exec(s.peer, s.env.String()+command)
+	if err != nil {
+		fmt.Fprintf(s.ch.Stderr(), "%s\n", err)
+		s.exit(255)
+		return true
+	}
+	s.e, s.exited = e, make(chan struct{})
+	go s.stdin()
+	go s.output()
+	return true
+}
+
+func (s *sshSession) stdin() {
+	buf := make([]byte, tunnelChunk)
+	for {
+		n, err := s.ch.Read(buf)
+		if n > 0 {
+			if s.e.send(pb.Flag_MSG_STDIN, append([]byte(nil), buf[:n]...)) != nil {
+				return
+			}
+		}
+		if err == io.EOF {
+			s.e.send(pb.Flag_EOF_STDIN, nil)
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *sshSession) output() {
+	defer s.e.close()
+	for msg := range s.e.frames {
+		switch msg.Flag {
+		case pb.Flag_MSG_STDOUT:
+			s.ch.Write(msg.Data)
+		case pb.Flag_MSG_STDERR:
+			s.ch.Stderr().Write(msg.Data)
+		case pb.Flag_EXIT:
+			code, err := strconv.Atoi(string(msg.Data))
+			if err != nil {
+				code = 255
+			}
+			close(s.exited)
+			s.exit(code)
+			return
+		}
+	}
+}
+
+// exit reports the exit code of the command and closes the channel.
+func (s *sshSession) exit(code int) {
+	s.ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(code)}))
+	s.ch.CloseWrite()
+	s.ch.Close()
+}
@@ -0,0 +1,169 @@
+package router
+
+import (
+	_ "embed"
+	"fmt"
+	pb "grpcsh/pb"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// terminalScript starts the user's login shell in a terminal.
+const terminalScript = `exec "${SHELL:-/bin/bash}" -l`
+
+// how long a terminal's shell is given to exit after the browser went
+// away, first after SIGHUP, then after SIGKILL
+const terminalKillGrace = 10 * time.Second
+
+//go:embed terminal.html
+var terminalPage []byte
+
+// terminalMessage is a message of a terminal WebSocket, sent as text.
+// Browsers send "input" with data, "resize" with cols and rows, and
+// "signal"; the router sends "exit" with the code, and the output of the
+// terminal as binary messages.
+type terminalMessage struct {
+	Type   string `json:"type"`
+	Data   string `json:"data,omitempty"`
+	Cols   uint16 `json:"cols,omitempty"`
+	Rows   uint16 `json:"rows,omitempty"`
+	Signal string `json:"signal,omitempty"`
+	Code   int    `json:"code"`
+	Error  string `json:"error,omitempty"`
+}
+
+// terminalAssets are the files of xterm.js the terminal page loads from
+// the router, which serves them from a directory it is given rather than
+// have browsers run scripts from elsewhere.
+var terminalAssets = []string{"xterm.js", "xterm.css", "addon-fit.js"}
+
+// terminals bridges browser terminals to login shells run under a
+// pseudo-terminal on agents, at /terminal/ws?peer=<id>&cols=<n>&rows=<n>.
+// /terminal/ serves a page to try it with, given the assets it needs.
+type terminals struct {
+	router *RouterService
+}
+
+func newTerminals(router *RouterService, mux *http.ServeMux, assets string) *terminals {
+	t := &terminals{router: router}
+	mux.Handle("GET /terminal/ws", websocket.Server{Handshake: sameOrigin, Handler: t.serve})
+	if assets == "" {
+		return t
+	}
+	mux.HandleFunc("GET /terminal/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(terminalPage)
+	})
+	for _, name := range terminalAssets {
+		file := filepath.Join(assets, name)
+		mux.HandleFunc("GET /terminal/assets/"+name, func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, file)
+		})
+	}
+	return t
+}
+
+// sameOrigin refuses WebSockets opened by pages of other sites, which
+// browsers would send the token cookie with.
+func sameOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != r.Host {
+		return fmt.Errorf("cross-origin WebSocket from %s", origin)
+	}
+	config.Origin = u
+	return nil
+}
+
+func (t *terminals) serve(ws *websocket.Conn) {
+	defer ws.Close()
+	query := ws.Request().URL.Query()
+	peer := query.Get("peer")
+	cols, _ := strconv.ParseUint(query.Get("cols"), 10, 16)
+	rows, _ := strconv.ParseUint(query.Get("rows"), 10, 16)
+	if cols == 0 || rows == 0 {
+		cols, rows = 80, 24
+	}
+	if peer == "" || strings.HasPrefix(peer, PoolPrefix) {
+		websocket.JSON.Send(ws, terminalMessage{Type: "exit", Code: 255, Error: "a peer is required"})
+		return
+	}
+	e, err := t.router.open(peer, pb.Flag_TERMINAL, []byte(terminalScript))
+	if err != nil {
+		websocket.JSON.Send(ws, terminalMessage{Type: "exit", Code: 255, Error: err.Error()})
+		return
+	}
+	defer e.close()
+	e.send(pb.Flag_RESIZE, []byte(fmt.Sprintf("%d %d", cols, rows)))
+	log.Printf("[Router] terminal on %s for %s\n", peer, ws.Request().RemoteAddr)
+
+	done := make(chan struct{})
+	hangup := make(chan struct{})
+	go func() {
+		for {
+			var msg terminalMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				// the browser went away; hang up the shell unless it is gone
+				select {
+				case <-done:
+				default:
+					e.send(pb.Flag_SIGNAL, []byte("HUP"))
+					close(hangup)
+				}
+				return
+			}
+			switch msg.Type {
+			case "input":
+				e.send(pb.Flag_MSG_STDIN, []byte(msg.Data))
+			case "resize":
+				if msg.Cols > 0 && msg.Rows > 0 {
+					e.send(pb.Flag_RESIZE, []byte(fmt.Sprintf("%d %d", msg.Cols, msg.Rows)))
+				}
+			case "signal":
+				e.send(pb.Flag_SIGNAL, []byte(msg.Signal))
+			}
+		}
+	}()
+	// a shell that ignores SIGHUP is killed, and given up on if its agent
+	// never reports the exit
+	var killTimer, abandonTimer <-chan time.Time
+	for {
+		select {
+		case msg := <-e.frames:
+			switch msg.Flag {
+			case pb.Flag_MSG_STDOUT, pb.Flag_MSG_STDERR:
+				websocket.Message.Send(ws, msg.Data)
+			case pb.Flag_EXIT:
+				close(done)
+				code, err := strconv.Atoi(string(msg.Data))
+				if err != nil {
+					code = 255
+				}
+				websocket.JSON.Send(ws, terminalMessage{Type: "exit", Code: code})
+				log.Printf("[Router] terminal on %s for %s exited with %d\n", peer, ws.Request().RemoteAddr, code)
+				return
+			}
+		case <-hangup:
+			hangup = nil
+			killTimer = time.After(terminalKillGrace)
+		case <-killTimer:
+			log.Printf("[Router] killing terminal on %s for %s, which ignored SIGHUP\n", peer, ws.Request().RemoteAddr)
+			e.send(pb.Flag_SIGNAL, []byte("KILL"))
+			killTimer = nil
+			abandonTimer = time.After(terminalKillGrace)
+		case <-abandonTimer:
+			log.Printf("[Router] giving up on terminal on %s for %s, which did not exit after SIGKILL\n", peer, ws.Request().RemoteAddr)
+			return
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	pb "grpcsh/pb"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// bytes written per frame
+const tunnelChunk = 32 * 1024
+
+// tunnelConn is a TCP connection made by a peer, carried over a call to
+// its TunnelService.Dial. The call is relayed as between agents: requests
+// go as MSG_STDIN frames, responses come back as MSG_STDOUT frames, and
+// EXIT carries the call's status.
+type tunnelConn struct {
+	e       *execution
+	peer    string
+	address string
+	buf     []byte
+	err     error // returned once buf is drained
+	once    sync.Once
+}
+
+// dial connects to an address from a peer.
+func (s *RouterService) dial(ctx context.Context, peer string, address string) (*tunnelConn, error) {
+	e, err := s.open(peer, pb.Flag_CALL, []byte(pb.TunnelService_Dial_FullMethodName))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	c := &tunnelConn{e: e, peer: peer, address: address}
+	if err := c.send(&pb.TunnelData{Address: address}); err != nil {
+		c.Close()
+		return nil, err
+	}
+	// the peer answers with an empty message once connected
+	connected := make(chan error, 1)
+	go func() {
+		_, err := c.recv()
+		connected <- err
+	}()
+	select {
+	case err = <-connected:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *tunnelConn) send(data *pb.TunnelData) error {
+	msg, err := proto.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.e.send(pb.Flag_MSG_STDIN, msg)
+}
+
+// recv returns the next message of the call, or the status it ended with.
+func (c *tunnelConn) recv() (*pb.TunnelData, error) {
+	var stderr string
+	for {
+		select {
+		case msg := <-c.e.frames:
+			switch msg.Flag {
+			case pb.Flag_MSG_STDOUT:
+				data := &pb.TunnelData{}
+				if err := proto.Unmarshal(msg.Data, data); err != nil {
+					return nil, err
+				}
+				return data, nil
+			case pb.Flag_MSG_STDERR:
+				stderr = string(msg.Data)
+			case pb.Flag_EXIT:
+				s := &spb.Status{}
+				if err := proto.Unmarshal(msg.Data, s); err != nil || stderr != "" {
+					// ended by the router, e.g. as the peer disconnected
+					return nil, status.Errorf(codes.Unavailable, "%s", stderr)
+				}
+				if err := status.FromProto(s).Err(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+		case <-c.e.done:
+			return nil, net.ErrClosed
+		}
+	}
+}
+
+func (c *tunnelConn) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		data, err := c.recv()
+		if err != nil {
+			c.err = err
+			continue
+		}
+		c.buf = data.Data
+		if data.Eof {
+			c.err = io.EOF
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (c *tunnelConn) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n := min(len(p)-written, tunnelChunk)
+		if err := c.send(&pb.TunnelData{Data: p[written : written+n]}); err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// CloseWrite tells the peer that nothing more will be written.
+func (c *tunnelConn) CloseWrite() error {
+	return c.send(&pb.TunnelData{Eof: true})
+}
+
+// Close ends the call; the peer closes its connection.
+func (c *tunnelConn) Close() error {
+	c.once.Do(func() {
+		c.e.send(pb.Flag_SIGNAL, []byte("TERM"))
+		c.e.close()
+	})
+	return nil
+}
+
+type tunnelAddr string
+
+func (a tunnelAddr) Network() string { return "grpcsh" }
+func (a tunnelAddr) String() string  { return string(a) }
+
+func (c *tunnelConn) LocalAddr() net.Addr { return tunnelAddr(RouterId) }
+func (c *tunnelConn) RemoteAddr() net.Addr {
+	return tunnelAddr(fmt.Sprintf("%s:%s", c.peer, c.address))
+}
+
+// deadlines are not supported; connections end with their call
+func (c *tunnelConn) SetDeadline(t time.Time) error      { return nil }
+func (c *tunnelConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *tunnelConn) SetWriteDeadline(t time.Time) error { return nil }
@@ -0,0 +1,61 @@
+package router
+
+import (
+	"net"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// agentListener hands connections agents make over WebSockets to the gRPC
+// server, which serves them like those made to its port.
+type agentListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newAgentListener() *agentListener {
+	return &agentListener{conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+func (l *agentListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *agentListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *agentListener) Addr() net.Addr { return tunnelAddr("websocket") }
+
+// serve is the WebSocket handler of /connect. The connection stays open
+// until the gRPC server is done with it.
+func (l *agentListener) serve(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	conn := &agentConn{Conn: ws, done: make(chan struct{})}
+	select {
+	case l.conns <- conn:
+		<-conn.done
+	case <-l.closed:
+	}
+}
+
+// agentConn is the connection of an agent over a WebSocket.
+type agentConn struct {
+	*websocket.Conn
+	done chan struct{}
+	once sync.Once
+}
+
+func (c *agentConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { close(c.done) })
+	return err
+}